@@ -0,0 +1,57 @@
+package breaker
+
+import "sync/atomic"
+
+// Do、DoWithFallback 等未指定 Acceptable 的方法使用的判定方式, 为nil时使用 AcceptNil
+// 每次请求都会读取, 很少修改, 使用原子操作避免加锁
+var processAcceptable atomic.Pointer[Acceptable]
+
+// SetDefaultAcceptable 修改进程内所有熔断器的默认判定方式, 影响 Do、DoWithFallback、DoCtx、DoWithFallbackCtx
+// 应在启动时、创建和使用熔断器之前设置, 运行中修改只对之后的请求生效, 已经在执行的请求仍按原来的方式判定
+// 例如 SetDefaultAcceptable(AcceptAny(AcceptNil, isNotFound)) 表示 NotFound 不计为失败
+func SetDefaultAcceptable(fn Acceptable) {
+	if fn == nil {
+		panic("acceptable must not be nil")
+	}
+
+	processAcceptable.Store(&fn)
+}
+
+// DefaultAcceptable 返回当前的默认判定方式, 便于 breakertest 等自定义的 Breaker 实现与真实熔断器保持一致
+func DefaultAcceptable() Acceptable {
+	if fn := processAcceptable.Load(); fn != nil {
+		return *fn
+	}
+
+	return defaultAcceptable
+}
+
+// AcceptAny 组合多个判定函数, 任意一个判定为可接受时即可接受, 没有判定函数时不接受
+// 例如 AcceptAny(AcceptNil, isBizError) 表示成功或者业务错误都不计为失败
+func AcceptAny(fns ...Acceptable) Acceptable {
+	return func(err error) bool {
+		for _, fn := range fns {
+			if fn(err) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AcceptAll 组合多个判定函数, 所有的判定都为可接受时才接受, 没有判定函数时总是接受
+func AcceptAll(fns ...Acceptable) Acceptable {
+	return func(err error) bool {
+		for _, fn := range fns {
+			if !fn(err) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AcceptNil 未调用 SetDefaultAcceptable 时的默认判定方式, 只有err为nil时才接受, 便于与 AcceptAny、AcceptAll 组合
+func AcceptNil(err error) bool {
+	return defaultAcceptable(err)
+}
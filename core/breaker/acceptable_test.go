@@ -0,0 +1,96 @@
+package breaker
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var errBiz = errors.New("biz error")
+
+func isBizError(err error) bool {
+	return errors.Is(err, errBiz)
+}
+
+func TestAcceptAny(t *testing.T) {
+	acceptable := AcceptAny(AcceptNil, isBizError)
+	assert.True(t, acceptable(nil))
+	assert.True(t, acceptable(errBiz))
+	assert.True(t, acceptable(errors.Join(errors.New("wrapped"), errBiz)))
+	assert.False(t, acceptable(errors.New("down")))
+
+	assert.False(t, AcceptAny()(nil))
+}
+
+func TestAcceptAll(t *testing.T) {
+	notTimeout := func(err error) bool {
+		return err == nil || err.Error() != "timeout"
+	}
+	acceptable := AcceptAll(AcceptAny(AcceptNil, isBizError), notTimeout)
+	assert.True(t, acceptable(nil))
+	assert.True(t, acceptable(errBiz))
+	assert.False(t, acceptable(errors.New("timeout")))
+	assert.False(t, acceptable(errors.New("down")))
+
+	assert.False(t, AcceptAll(AcceptNil, isBizError)(errBiz))
+	assert.True(t, AcceptAll()(errors.New("down")))
+}
+
+func TestBreakerAcceptAny(t *testing.T) {
+	b := NewBreaker()
+	acceptable := AcceptAny(AcceptNil, isBizError)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, errBiz, b.DoWithAcceptable(func() error {
+			return errBiz
+		}, acceptable))
+	}
+
+	// 业务错误不计为失败
+	st := b.Stats()
+	assert.Equal(t, int64(100), st.Accepts)
+	assert.Equal(t, int64(100), st.Total)
+}
+
+func TestSetDefaultAcceptable(t *testing.T) {
+	SetDefaultAcceptable(AcceptAny(AcceptNil, isBizError))
+	defer SetDefaultAcceptable(AcceptNil)
+
+	b := NewBreaker()
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, errBiz, b.Do(func() error {
+			return errBiz
+		}))
+		assert.Equal(t, errBiz, b.DoWithFallback(func() error {
+			return errBiz
+		}, func(err error) error {
+			return err
+		}))
+	}
+	st := b.Stats()
+	assert.Equal(t, int64(20), st.Accepts)
+	assert.Equal(t, int64(20), st.Total)
+
+	// 显式指定的 Acceptable 和 AcceptNil 不受影响
+	_ = b.DoWithAcceptable(func() error {
+		return errBiz
+	}, AcceptNil)
+	st = b.Stats()
+	assert.Equal(t, int64(20), st.Accepts)
+	assert.Equal(t, int64(21), st.Total)
+	assert.False(t, AcceptNil(errBiz))
+
+	// 恢复之后业务错误重新计为失败
+	SetDefaultAcceptable(AcceptNil)
+	_ = b.Do(func() error {
+		return errBiz
+	})
+	st = b.Stats()
+	assert.Equal(t, int64(20), st.Accepts)
+	assert.Equal(t, int64(22), st.Total)
+}
+
+func TestSetDefaultAcceptableNil(t *testing.T) {
+	assert.Panics(t, func() {
+		SetDefaultAcceptable(nil)
+	})
+}
@@ -0,0 +1,26 @@
+package breaker
+
+// AllowDefer 与 Breaker.Allow 相同, 但返回一个根据执行结果上报的回调, 便于配合defer使用, 避免忘记调用 Accept/Reject
+// done(nil) 记为成功, 否则记为失败并以 err.Error() 作为失败原因, done只能调用一次, 例如:
+//
+//	done, err := breaker.AllowDefer(b)
+//	if err != nil {
+//		return err
+//	}
+//	defer func() {
+//		done(retErr)
+//	}()
+func AllowDefer(b Breaker) (done func(err error), err error) {
+	promise, err := b.Allow()
+	if err != nil {
+		return nil, err
+	}
+
+	return func(err error) {
+		if err == nil {
+			promise.Accept()
+		} else {
+			promise.Reject(err.Error())
+		}
+	}, nil
+}
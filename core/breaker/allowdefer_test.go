@@ -0,0 +1,57 @@
+package breaker
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestAllowDefer(t *testing.T) {
+	b := NewBreaker()
+
+	done, err := AllowDefer(b)
+	assert.Nil(t, err)
+	done(nil)
+	assert.Equal(t, int64(1), b.Stats().Accepts)
+	assert.Equal(t, int64(1), b.Stats().Total)
+	assert.Empty(t, b.LastErrors())
+
+	done, err = AllowDefer(b)
+	assert.Nil(t, err)
+	done(errors.New("bad response"))
+	assert.Equal(t, int64(1), b.Stats().Accepts)
+	assert.Equal(t, int64(2), b.Stats().Total)
+	assert.Equal(t, "bad response", b.LastErrors()[0].Reason)
+}
+
+func TestAllowDeferRejected(t *testing.T) {
+	b := NewBreaker()
+	b.ForceOpen()
+	done, err := AllowDefer(b)
+	assert.Nil(t, done)
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+}
+
+func TestAllowDeferWithDefer(t *testing.T) {
+	b := NewBreaker()
+	errDown := errors.New("down")
+	call := func(fail bool) (retErr error) {
+		done, err := AllowDefer(b)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			done(retErr)
+		}()
+
+		if fail {
+			return errDown
+		}
+		return nil
+	}
+
+	assert.Nil(t, call(false))
+	assert.Equal(t, errDown, call(true))
+	assert.Equal(t, int64(1), b.Stats().Accepts)
+	assert.Equal(t, int64(2), b.Stats().Total)
+}
@@ -0,0 +1,27 @@
+package breaker
+
+import "testing"
+
+func BenchmarkBreakerDo(b *testing.B) {
+	br := NewBreaker()
+	req := func() error {
+		return nil
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = br.Do(req)
+	}
+}
+
+func BenchmarkBreakerAllow(b *testing.B) {
+	br := NewBreaker()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p, err := br.Allow()
+		if err == nil {
+			p.Accept()
+		}
+	}
+}
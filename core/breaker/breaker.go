@@ -1,12 +1,12 @@
 package breaker
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"go-zero-/core/mathx"
-	"go-zero-/core/proc"
-	"go-zero-/core/stat"
 	"go-zero-/core/stringx"
+	"go-zero-/core/timex"
 	"strings"
 	"sync"
 	"time"
@@ -15,9 +15,33 @@ import (
 const (
 	numHistoryReasons = 5
 	timeFormat        = "15:04:05"
+	// 错误记录跨天时带上日期, 避免跨零点或者多日压测时时间有歧义
+	dateTimeFormat = "2006-01-02 15:04:05"
 )
 
-var ErrServiceUnavailable = errors.New("circuit breaker is open")
+const (
+	// ForcedNone 未强制, 使用正常的熔断算法
+	ForcedNone ForceMode = iota
+	// ForcedOpen 强制熔断
+	ForcedOpen
+	// ForcedClosed 强制放行
+	ForcedClosed
+)
+
+const (
+	retryBaseBackoff = time.Millisecond * 100
+	retryMaxBackoff  = time.Second * 5
+	retryDeviation   = 0.2
+)
+
+var (
+	// ErrServiceUnavailable 熔断器拒绝请求, 实际返回的是 *BreakerError, 需要使用 errors.Is 判断
+	ErrServiceUnavailable = errors.New("circuit breaker is open")
+
+	retryJitter = mathx.NewUnstable(retryDeviation)
+	// 生成未命名熔断器的名字, 使用独立的随机源, 不受 stringx.Seed 影响
+	breakerNames = stringx.NewRand(time.Now().UnixNano())
+)
 
 type (
 	// Acceptable 自定义判定执行结果
@@ -57,24 +81,118 @@ type (
 
 		// 熔断方法 支持自定义判定执行结果   支持自定义快速失败
 		DoWithFallbackAcceptable(req func() error, fallback Fallback, acceptable Acceptable) error
+
+		// 熔断方法 支持将执行结果分为成功、失败和忽略, 被忽略的结果不影响熔断器
+		DoWithClassifier(req func() error, classifier Classifier) error
+
+		// 熔断方法 支持失败重试, 每次真正执行的请求都会单独计入统计
+		// 熔断器拒绝时立即停止重试, 任意一次成功即返回成功
+		// backoff 为第attempt次重试之前的等待时间, 为nil时使用带抖动的指数退避
+		DoWithRetries(req func() error, attempts int, backoff func(attempt int) time.Duration) error
+
+		// 熔断方法 支持失败重试, 最多执行maxAttempts次, 重试之间从backoff开始指数退避, backoff不大于0时不等待
+		// 熔断器拒绝时立即停止重试, 哪些错误需要重试由 WithRetryableErrors 决定
+		DoWithRetry(req func() error, maxAttempts int, backoff time.Duration) error
+
+		// 返回通过熔断器调用fn的函数, 每次调用等同于 Do(fn), 适用于总是需要经过熔断器调用的函数
+		Wrap(fn func() error) func() error
+
+		// 同 Wrap, 每次调用等同于 DoWithFallback(fn, fallback)
+		WrapWithFallback(fn func() error, fallback Fallback) func() error
+
+		// 以下为支持context的版本, ctx已经结束时直接返回ctx.Err(), 不经过熔断器也不记录统计
+		// 避免调用方自己超时取消的请求污染熔断器的统计数据
+		AllowCtx(ctx context.Context) (Promise, error)
+		DoCtx(ctx context.Context, req func() error) error
+		DoWithAcceptableCtx(ctx context.Context, req func() error, acceptable Acceptable) error
+		DoWithFallbackCtx(ctx context.Context, req func() error, fallback Fallback) error
+		DoWithFallbackAcceptableCtx(ctx context.Context, req func() error, fallback Fallback,
+			acceptable Acceptable) error
+
+		// 强制熔断, 拒绝所有请求, 用于主动切流
+		ForceOpen()
+
+		// 强制放行, 不拒绝任何请求, 用于已知会有大量错误的迁移期间
+		ForceClose()
+
+		// 取消强制模式, 恢复正常的熔断算法
+		ClearForce()
+
+		// 熔断器当前的统计数据
+		Stats() Stats
+
+		// 最近的失败原因, 按时间从新到旧排列, 返回的是拷贝
+		// 被熔断拒绝的请求没有真正执行, 不会产生失败原因
+		LastErrors() []ErrorRecord
+
+		// 最近window时间内处于熔断中(丢弃比例大于0或者强制熔断)的时长, 用于计算SLO
+		// 熔断状态只在请求到来时更新, 没有请求时保持上一次的状态
+		OpenDuration(window time.Duration) time.Duration
 	}
 
+	// ForceMode 强制模式, 强制模式下跳过熔断算法, 但统计数据照常记录
+	ForceMode int32
+
+	// Stats 熔断器统计数据
+	Stats struct {
+		Name string
+		// 滑动窗口内成功的请求数
+		Accepts int64
+		// 滑动窗口内的总请求数
+		Total int64
+		// 当前的强制模式
+		Forced ForceMode
+		// 熔断器配置
+		Window     time.Duration
+		Buckets    int
+		K          float64
+		Protection int64
+	}
+
+	// BreakerStats 与 Stats 是同一个类型
+	BreakerStats = Stats
+
 	throttle interface {
 		// 熔断
 		allow() (Promise, error)
 		// 熔断方法, DoXXX最终都是执行该方法
-		doReq(req func() error, fallback Fallback, acceptable Acceptable) error
+		doReq(req func() error, fallback Fallback, classifier resultClassifier) error
+		force(mode ForceMode)
+		stats() Stats
+		lastErrors() []ErrorRecord
+		openDuration(window time.Duration) time.Duration
+		// 清空滑动窗口, 冷却状态以及失败原因, 强制模式保持不变
+		reset()
 	}
 
 	internalThrottle interface {
 		allow() (internalPromise, error)
-		doReq(req func() error, fallback Fallback, acceptable Acceptable) error
+		// 请求失败时通过recorder记录失败原因
+		doReq(req func() error, fallback Fallback, classifier resultClassifier, recorder reasonRecorder) error
+		force(mode ForceMode)
+		stats() Stats
+		openDuration(window time.Duration) time.Duration
+		reset()
 	}
 
 	// circuitBreaker 熔断器接口
 	circuitBreaker struct {
 		name string
 		throttle
+		// 透传给googleBreaker的配置
+		googleOpts []googleOption
+		clock      timex.Clock
+		// 定期上报的间隔, 0表示不上报
+		reportInterval time.Duration
+		summary        *summarizer
+		// 熔断拒绝请求时的上报频率和格式
+		openReportInterval  time.Duration
+		openReportFormatter OpenReportFormatter
+		// 请求耗时的观察者
+		latencyFn       func(d time.Duration, err error)
+		observeRejected bool
+		// DoWithRetry 判断错误是否需要重试
+		retryable func(err error) bool
 	}
 	Option func(breaker *circuitBreaker)
 
@@ -82,15 +200,189 @@ type (
 )
 
 func NewBreaker(opts ...Option) Breaker {
-	var b circuitBreaker
+	b := newCircuitBreaker(opts...)
+	if len(b.name) == 0 {
+		b.name = breakerNames.Rand()
+	}
+	errWin := new(errorWindow)
+	gb := b.newGoogleBreaker(b.name, errWin)
+	b.throttle = newLoggedThrottle(b, gb, errWin)
+	b.startSummary(gb)
+	return b
+}
+
+// 使用该熔断器的配置创建googleBreaker
+func (cb *circuitBreaker) newGoogleBreaker(name string, errWin *errorWindow) *googleBreaker {
+	gb := newGoogleBreaker(name, cb.clock, errWin, cb.googleOpts...)
+	gb.observer = newLatencyObserver(cb.latencyFn, cb.observeRejected)
+	return gb
+}
+
+// 应用配置, throttle由调用方创建
+func newCircuitBreaker(opts ...Option) *circuitBreaker {
+	b := &circuitBreaker{
+		clock:              timex.RealClock{},
+		openReportInterval: defaultOpenReportInterval,
+		retryable:          defaultRetryable,
+	}
 	for _, opt := range opts {
-		opt(&b)
+		opt(b)
 	}
-	if len(b.name) == 0 {
-		b.name = stringx.Rand()
+	return b
+}
+
+// WithSlowCallThreshold 设置慢调用阈值, 耗时超过d的请求即使返回成功也记为一次失败
+// 只影响熔断器的统计, 调用方拿到的仍然是请求的原始结果
+func WithSlowCallThreshold(d time.Duration) Option {
+	return func(b *circuitBreaker) {
+		b.googleOpts = append(b.googleOpts, func(s *googleSettings) {
+			s.slowCallThreshold = d
+		})
+	}
+}
+
+// WithName 设置熔断器名字, 不设置时随机生成
+func WithName(name string) Option {
+	return func(b *circuitBreaker) {
+		b.name = name
+	}
+}
+
+// WithRetryableErrors 设置 DoWithRetry 哪些错误需要重试, 其余错误直接返回
+// 默认除了熔断器拒绝和ctx结束之外的错误都重试
+func WithRetryableErrors(classifier func(err error) bool) Option {
+	if classifier == nil {
+		panic("retryable classifier must not be nil")
+	}
+	return func(b *circuitBreaker) {
+		b.retryable = classifier
+	}
+}
+
+// WithClock 设置熔断器使用的时钟, 测试时可替换为 timex.MockClock
+func WithClock(clock timex.Clock) Option {
+	return func(b *circuitBreaker) {
+		b.clock = clock
+	}
+}
+
+// WithWindow 设置滑动窗口时长, 默认10s
+func WithWindow(d time.Duration) Option {
+	if d <= 0 {
+		panic("window must be greater than 0")
+	}
+	return func(b *circuitBreaker) {
+		b.googleOpts = append(b.googleOpts, func(s *googleSettings) {
+			s.window = d
+		})
+	}
+}
+
+// WithBuckets 设置滑动窗口桶的数量, 默认40
+func WithBuckets(n int) Option {
+	if n < 1 {
+		panic("buckets must be greater than 0")
+	}
+	return func(b *circuitBreaker) {
+		b.googleOpts = append(b.googleOpts, func(s *googleSettings) {
+			s.buckets = n
+		})
+	}
+}
+
+// WithK 设置敏感度, 越小越容易熔断, 默认1.5
+func WithK(k float64) Option {
+	if k <= 0 {
+		panic("k must be greater than 0")
+	}
+	return func(b *circuitBreaker) {
+		b.googleOpts = append(b.googleOpts, func(s *googleSettings) {
+			s.k = k
+		})
+	}
+}
+
+// WithProtection 设置保护请求数, 窗口内请求数低于该值时不会熔断, 默认5
+func WithProtection(n int64) Option {
+	if n < 0 {
+		panic("protection must not be negative")
+	}
+	return func(b *circuitBreaker) {
+		b.googleOpts = append(b.googleOpts, func(s *googleSettings) {
+			s.protection = n
+		})
+	}
+}
+
+// WithMarkFailureOnReject 设置被熔断拒绝的请求是否记为一次失败, 默认为true
+// 被拒绝的请求并没有真正执行, 记为失败会使触发熔断的失败被重复统计, 设置为false则拒绝不影响滑动窗口
+func WithMarkFailureOnReject(mark bool) Option {
+	return func(b *circuitBreaker) {
+		b.googleOpts = append(b.googleOpts, func(s *googleSettings) {
+			s.markFailureOnReject = mark
+		})
+	}
+}
+
+// WithPanicAsError 设置req panic时由熔断器recover, 记为一次失败并返回错误, 而不是继续panic
+// 默认不开启, req panic时同样会记为失败, 但panic会继续向上抛出
+func WithPanicAsError() Option {
+	return func(b *circuitBreaker) {
+		b.googleOpts = append(b.googleOpts, func(s *googleSettings) {
+			s.panicAsError = true
+		})
+	}
+}
+
+// WithCooldown 设置熔断后的冷却时长
+// 丢弃比例第一次超过冷却阈值(默认0.5, 见WithCooldownRatio)后, d时间内拒绝所有请求, 之后恢复正常的概率放行
+// 适用于已知恢复至少需要一段时间的下游, 例如数据库主从切换, 过早探测只会浪费资源
+func WithCooldown(d time.Duration) Option {
+	return func(b *circuitBreaker) {
+		b.googleOpts = append(b.googleOpts, func(s *googleSettings) {
+			s.cooldown = d
+		})
+	}
+}
+
+// WithCooldownRatio 设置开始冷却的丢弃比例阈值, 取值范围 (0, 1]
+func WithCooldownRatio(r float64) Option {
+	if r <= 0 || r > 1 {
+		panic("cooldown ratio must be in (0, 1]")
+	}
+	return func(b *circuitBreaker) {
+		b.googleOpts = append(b.googleOpts, func(s *googleSettings) {
+			s.cooldownRatio = r
+		})
+	}
+}
+
+// WithMaxOpenDuration 设置连续拒绝请求超过d时放行一个探测请求, 不管当前的丢弃比例是多少
+// 流量很小时请求几乎都被拒绝, 拒绝又记为失败, 下游恢复之后熔断器也很难恢复, 每隔d探测一次保证能够恢复
+// 强制熔断以及冷却期内不会放行探测请求, 默认为0即不开启
+func WithMaxOpenDuration(d time.Duration) Option {
+	if d < 0 {
+		panic("max open duration must not be negative")
+	}
+	return func(b *circuitBreaker) {
+		b.googleOpts = append(b.googleOpts, func(s *googleSettings) {
+			s.maxOpenDuration = d
+		})
+	}
+}
+
+// WithMaxDropRatio 设置最大丢弃比例, 取值范围 (0, 1], 默认为1即不限制
+// 下游完全不可用时丢弃比例会趋近于1, 几乎没有请求能去探测下游, 导致恢复很慢
+// 推荐设置为0.9左右, 保证始终有少量真实请求能够通过
+func WithMaxDropRatio(r float64) Option {
+	if r <= 0 || r > 1 {
+		panic("max drop ratio must be in (0, 1]")
+	}
+	return func(b *circuitBreaker) {
+		b.googleOpts = append(b.googleOpts, func(s *googleSettings) {
+			s.maxDropRatio = r
+		})
 	}
-	b.throttle = newLoggedThrottle(b.name, newGoogleBreaker())
-	return &b
 }
 
 func (cb *circuitBreaker) Name() string {
@@ -101,7 +393,7 @@ func (cb *circuitBreaker) Allow() (Promise, error) {
 }
 
 func (cb *circuitBreaker) Do(req func() error) error {
-	return cb.throttle.doReq(req, nil, defaultAcceptable)
+	return cb.throttle.doReq(req, nil, DefaultAcceptable())
 }
 
 func (cb *circuitBreaker) DoWithAcceptable(req func() error, acceptable Acceptable) error {
@@ -109,7 +401,7 @@ func (cb *circuitBreaker) DoWithAcceptable(req func() error, acceptable Acceptab
 }
 
 func (cb *circuitBreaker) DoWithFallback(req func() error, fallback Fallback) error {
-	return cb.throttle.doReq(req, fallback, defaultAcceptable)
+	return cb.throttle.doReq(req, fallback, DefaultAcceptable())
 }
 
 func (cb *circuitBreaker) DoWithFallbackAcceptable(req func() error, fallback Fallback,
@@ -117,80 +409,275 @@ func (cb *circuitBreaker) DoWithFallbackAcceptable(req func() error, fallback Fa
 	return cb.throttle.doReq(req, fallback, acceptable)
 }
 
-func defaultAcceptable(err error) bool {
+func (cb *circuitBreaker) DoWithClassifier(req func() error, classifier Classifier) error {
+	return cb.throttle.doReq(req, nil, classifier)
+}
+
+func (cb *circuitBreaker) DoWithRetries(req func() error, attempts int,
+	backoff func(attempt int) time.Duration) error {
+	if backoff == nil {
+		backoff = defaultRetryBackoff
+	}
+
+	// 与 DoWithRetry 共用重试逻辑, 所有的错误都重试
+	return DoWithRetry(cb, context.Background(), req, attempts, WithRetryBackoff(BackoffFunc(backoff)),
+		WithRetryable(func(err error) bool {
+			return true
+		}))
+}
+
+func (cb *circuitBreaker) DoWithRetry(req func() error, maxAttempts int, backoff time.Duration) error {
+	return DoWithRetry(cb, context.Background(), req, maxAttempts, WithRetryBackoff(retryBackoff(backoff)),
+		WithRetryable(cb.retryable))
+}
+
+func (cb *circuitBreaker) AllowCtx(ctx context.Context) (Promise, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return cb.Allow()
+}
+
+func (cb *circuitBreaker) DoCtx(ctx context.Context, req func() error) error {
+	return cb.DoWithFallbackAcceptableCtx(ctx, req, nil, DefaultAcceptable())
+}
+
+func (cb *circuitBreaker) DoWithAcceptableCtx(ctx context.Context, req func() error, acceptable Acceptable) error {
+	return cb.DoWithFallbackAcceptableCtx(ctx, req, nil, acceptable)
+}
+
+func (cb *circuitBreaker) DoWithFallbackCtx(ctx context.Context, req func() error, fallback Fallback) error {
+	return cb.DoWithFallbackAcceptableCtx(ctx, req, fallback, DefaultAcceptable())
+}
+
+func (cb *circuitBreaker) DoWithFallbackAcceptableCtx(ctx context.Context, req func() error, fallback Fallback,
+	acceptable Acceptable) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return cb.throttle.doReq(req, fallback, acceptable)
+}
+
+func (cb *circuitBreaker) ForceOpen() {
+	cb.throttle.force(ForcedOpen)
+}
+
+func (cb *circuitBreaker) ForceClose() {
+	cb.throttle.force(ForcedClosed)
+}
+
+func (cb *circuitBreaker) ClearForce() {
+	cb.throttle.force(ForcedNone)
+}
+
+func (cb *circuitBreaker) Stats() Stats {
+	st := cb.throttle.stats()
+	st.Name = cb.name
+	return st
+}
+
+func (cb *circuitBreaker) LastErrors() []ErrorRecord {
+	return cb.throttle.lastErrors()
+}
+
+func (cb *circuitBreaker) OpenDuration(window time.Duration) time.Duration {
+	return cb.throttle.openDuration(window)
+}
+
+func (m ForceMode) String() string {
+	switch m {
+	case ForcedOpen:
+		return "forced-open"
+	case ForcedClosed:
+		return "forced-closed"
+	default:
+		return "none"
+	}
+}
+
+// 带抖动的指数退避, 100ms, 200ms, 400ms ... 最大不超过5s
+func defaultRetryBackoff(attempt int) time.Duration {
+	return retryJitter.AroundDuration(exponential(retryBaseBackoff, retryMaxBackoff, attempt))
+}
+
+// 从base开始的指数退避, 不带抖动, 最大不超过5s, base本身大于5s时不再增长
+func retryBackoff(base time.Duration) Backoff {
+	if base <= 0 {
+		return BackoffFunc(func(int) time.Duration {
+			return 0
+		})
+	}
+
+	max := retryMaxBackoff
+	if base > max {
+		max = base
+	}
+	return BackoffFunc(func(attempt int) time.Duration {
+		return exponential(base, max, attempt)
+	})
+}
+
+// 声明为 Acceptable 类型, 可以直接作为 resultClassifier 传递
+var defaultAcceptable Acceptable = func(err error) bool {
 	return err == nil
 }
 
 type loggedThrottle struct {
 	name string
 	internalThrottle
-	errWin *errorWindow
+	errWin   *errorWindow
+	reporter *dropReporter
 }
 
-func newLoggedThrottle(name string, t internalThrottle) loggedThrottle {
+func newLoggedThrottle(cb *circuitBreaker, t internalThrottle, errWin *errorWindow) loggedThrottle {
 	return loggedThrottle{
-		name:             name,
+		name:             cb.name,
 		internalThrottle: t,
-		errWin:           new(errorWindow),
+		errWin:           errWin,
+		reporter:         cb.newDropReporter(errWin),
 	}
 }
 
 func (lt loggedThrottle) allow() (Promise, error) {
 	promise, err := lt.internalThrottle.allow()
 	return PromiseWithReason{
-		promise: promise,
-		errWin:  lt.errWin,
+		promise:  promise,
+		recorder: lt.errWin,
 	}, lt.logError(err)
 }
 
-func (lt loggedThrottle) doReq(req func() error, fallback Fallback, acceptable Acceptable) error {
-	return lt.logError(lt.internalThrottle.doReq(req, fallback, func(err error) bool {
-		accept := acceptable(err)
-		if !accept && err != nil {
-			lt.errWin.add(err.Error())
-		}
-		return accept
-	}))
+// 直接把错误窗口交给内部熔断器记录失败原因, 避免每次请求都包装一个acceptable闭包
+func (lt loggedThrottle) doReq(req func() error, fallback Fallback, classifier resultClassifier) error {
+	return lt.logError(lt.internalThrottle.doReq(req, fallback, classifier, lt.errWin))
+}
+
+func (lt loggedThrottle) lastErrors() []ErrorRecord {
+	return lt.errWin.Export()
+}
+
+func (lt loggedThrottle) reset() {
+	lt.internalThrottle.reset()
+	lt.errWin.reset()
 }
 
 func (lt loggedThrottle) logError(err error) error {
 	if errors.Is(err, ErrServiceUnavailable) {
-		stat.Report(fmt.Sprintf("proc(%s/%d), callee: %s, breaker is open and requests dropped\nlast errors:\n%s",
-			proc.ProcessName(), proc.Pid(), lt.name, lt.errWin))
+		lt.reporter.report()
 	}
 	return err
 }
 
+// ErrorRecord 一条错误记录
+type ErrorRecord struct {
+	Time   time.Time
+	Reason string
+}
+
+// Reason 错误记录
+//
+// Deprecated: 使用 ErrorRecord.
+type Reason = ErrorRecord
+
+// 失败原因记录
+type reasonRecorder interface {
+	add(reason string)
+}
+
 // 错误窗口记录
 type errorWindow struct {
-	reasons [numHistoryReasons]string
+	reasons [numHistoryReasons]Reason
 	index   int
 	count   int
 	lock    sync.Mutex
 }
 
 func (ew *errorWindow) add(reason string) {
+	ew.addReason(ErrorRecord{
+		Time:   time.Now(),
+		Reason: reason,
+	})
+}
+
+func (ew *errorWindow) addReason(reason ErrorRecord) {
 	ew.lock.Lock()
-	ew.reasons[ew.index] = fmt.Sprintf("%s %s", time.Now().Format(timeFormat), reason)
+	ew.reasons[ew.index] = reason
 	ew.index = (ew.index + 1) % numHistoryReasons
 	ew.count = mathx.MinInt(ew.count+1, numHistoryReasons)
 	ew.lock.Unlock()
 }
 
-func (ew *errorWindow) String() string {
-	var reasons []string
+// Export 返回错误记录的拷贝, 保证按时间从新到旧排列, 便于调用方直接使用结构化的记录而不是解析 String 的结果
+func (ew *errorWindow) Export() []ErrorRecord {
 	ew.lock.Lock()
-	for i := ew.index - 1; i >= ew.index-ew.count; i-- {
-		reasons = append(reasons, ew.reasons[(i+numHistoryReasons)%numHistoryReasons])
+	defer ew.lock.Unlock()
+
+	reasons := make([]ErrorRecord, 0, ew.count)
+	// index指向下一个写入位置, 从index-1往回走就是从新到旧
+	for i := 1; i <= ew.count; i++ {
+		reasons = append(reasons, ew.reasons[(ew.index-i+numHistoryReasons)%numHistoryReasons])
+	}
+	return reasons
+}
+
+// 最近的失败原因, 按时间从新到旧, 没有记录时返回nil
+func (ew *errorWindow) recentErrors() []string {
+	if ew == nil {
+		return nil
 	}
+
+	records := ew.Export()
+	if len(records) == 0 {
+		return nil
+	}
+
+	reasons := make([]string, len(records))
+	for i, record := range records {
+		reasons[i] = record.Reason
+	}
+	return reasons
+}
+
+func (ew *errorWindow) reset() {
+	ew.lock.Lock()
+	ew.reasons = [numHistoryReasons]Reason{}
+	ew.index = 0
+	ew.count = 0
 	ew.lock.Unlock()
-	return strings.Join(reasons, "\n")
+}
+
+func (ew *errorWindow) String() string {
+	return formatErrorRecords(ew.Export())
+}
+
+// 按时间从新到旧每行一条, 记录跨天时带上日期
+func formatErrorRecords(reasons []ErrorRecord) string {
+	if len(reasons) == 0 {
+		return ""
+	}
+
+	format := timeFormat
+	newest, oldest := reasons[0].Time, reasons[len(reasons)-1].Time
+	if !sameDay(newest, oldest) {
+		format = dateTimeFormat
+	}
+
+	lines := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		lines = append(lines, fmt.Sprintf("%s %s", reason.Time.Format(format), reason.Reason))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
 }
 
 // 在请求被拒绝时, 记录拒绝的原因， 并将错误信息添加到错误的窗口中
 type PromiseWithReason struct {
-	promise internalPromise
-	errWin  *errorWindow
+	promise  internalPromise
+	recorder reasonRecorder
 }
 
 func (p PromiseWithReason) Accept() {
@@ -198,6 +685,6 @@ func (p PromiseWithReason) Accept() {
 }
 
 func (p PromiseWithReason) Reject(reason string) {
-	p.errWin.add(reason)
+	p.recorder.add(reason)
 	p.promise.Reject()
 }
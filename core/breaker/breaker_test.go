@@ -0,0 +1,462 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/mathx"
+	"go-zero-/core/stat"
+	"go-zero-/core/timex"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 使用固定种子的概率生成器, 保证按概率拒绝的测试结果是确定的
+func withSeededProba(b Breaker) Breaker {
+	b.(*circuitBreaker).throttle.(loggedThrottle).internalThrottle.(*googleBreaker).proba = mathx.NewProbaWithSeed(1)
+	return b
+}
+
+func TestErrorWindowNewestFirst(t *testing.T) {
+	var ew errorWindow
+	assert.Empty(t, ew.Export())
+	assert.Equal(t, "", ew.String())
+
+	// 写满并回绕, 只保留最近的numHistoryReasons条
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local)
+	for i := 0; i < numHistoryReasons+3; i++ {
+		ew.addReason(ErrorRecord{
+			Time:   start.Add(time.Duration(i) * time.Second),
+			Reason: fmt.Sprintf("err-%d", i),
+		})
+	}
+
+	reasons := ew.Export()
+	assert.Len(t, reasons, numHistoryReasons)
+	for i, reason := range reasons {
+		assert.Equal(t, fmt.Sprintf("err-%d", numHistoryReasons+2-i), reason.Reason)
+	}
+	for i := 1; i < len(reasons); i++ {
+		assert.True(t, reasons[i-1].Time.After(reasons[i].Time))
+	}
+
+	lines := strings.Split(ew.String(), "\n")
+	assert.Len(t, lines, numHistoryReasons)
+	assert.Equal(t, "12:00:07 err-7", lines[0])
+}
+
+func TestErrorWindowExport(t *testing.T) {
+	var ew errorWindow
+	start := time.Date(2024, 1, 1, 23, 59, 58, 0, time.Local)
+	for i := 0; i < 3; i++ {
+		ew.addReason(ErrorRecord{
+			Time:   start.Add(time.Duration(i) * time.Second),
+			Reason: fmt.Sprintf("err-%d", i),
+		})
+	}
+
+	// 未写满时只返回已有的记录, 从新到旧
+	records := ew.Export()
+	assert.Equal(t, []ErrorRecord{
+		{Time: start.Add(time.Second * 2), Reason: "err-2"},
+		{Time: start.Add(time.Second), Reason: "err-1"},
+		{Time: start, Reason: "err-0"},
+	}, records)
+	// 返回的是拷贝
+	records[0].Reason = "changed"
+	assert.Equal(t, "err-2", ew.Export()[0].Reason)
+	// String 基于 Export 格式化, 跨天时带上日期
+	assert.Equal(t, "2024-01-02 00:00:00 err-2\n2024-01-01 23:59:59 err-1\n2024-01-01 23:59:58 err-0",
+		ew.String())
+
+	for i := 3; i < numHistoryReasons*2; i++ {
+		ew.addReason(ErrorRecord{
+			Time:   start.Add(time.Duration(i) * time.Second),
+			Reason: fmt.Sprintf("err-%d", i),
+		})
+	}
+	records = ew.Export()
+	assert.Len(t, records, numHistoryReasons)
+	assert.Equal(t, fmt.Sprintf("err-%d", numHistoryReasons*2-1), records[0].Reason)
+	assert.Equal(t, fmt.Sprintf("err-%d", numHistoryReasons), records[numHistoryReasons-1].Reason)
+
+	ew.reset()
+	assert.Empty(t, ew.Export())
+}
+
+func TestErrorWindowAcrossDays(t *testing.T) {
+	var ew errorWindow
+	midnight := time.Date(2024, 1, 2, 0, 0, 0, 0, time.Local)
+	ew.addReason(ErrorRecord{Time: midnight.Add(-time.Second), Reason: "before"})
+	ew.addReason(ErrorRecord{Time: midnight.Add(time.Second), Reason: "after"})
+
+	assert.Equal(t, "2024-01-02 00:00:01 after\n2024-01-01 23:59:59 before", ew.String())
+}
+
+func TestBreakerLastErrors(t *testing.T) {
+	b := NewBreaker()
+	assert.Empty(t, b.LastErrors())
+
+	fail := func(reason string) {
+		assert.Error(t, b.Do(func() error {
+			return errors.New(reason)
+		}))
+	}
+	reject := func() {
+		b.ForceOpen()
+		assert.ErrorIs(t, b.Do(func() error {
+			return nil
+		}), ErrServiceUnavailable)
+		b.ClearForce()
+	}
+
+	fail("err-1")
+	reject()
+	fail("err-2")
+	reject()
+	p, err := b.Allow()
+	assert.Nil(t, err)
+	p.Reject("err-3")
+
+	// 拒绝不产生失败原因, 按时间从新到旧排列
+	records := b.LastErrors()
+	var reasons []string
+	for _, record := range records {
+		reasons = append(reasons, record.Reason)
+		assert.False(t, record.Time.IsZero())
+	}
+	assert.Equal(t, []string{"err-3", "err-2", "err-1"}, reasons)
+
+	// 返回的是拷贝, 修改不影响熔断器内部的记录
+	records[0].Reason = "changed"
+	assert.Equal(t, "err-3", b.LastErrors()[0].Reason)
+	fail("err-4")
+	assert.Equal(t, "changed", records[0].Reason)
+	assert.Equal(t, "err-4", b.LastErrors()[0].Reason)
+}
+
+func TestBreakerSlowCall(t *testing.T) {
+	b := withSeededProba(NewBreaker(WithSlowCallThreshold(time.Millisecond)))
+
+	var opened bool
+	for i := 0; i < 200 && !opened; i++ {
+		err := b.Do(func() error {
+			time.Sleep(time.Millisecond * 2)
+			return nil
+		})
+		if err != nil {
+			assert.ErrorIs(t, err, ErrServiceUnavailable)
+			opened = true
+		}
+	}
+	assert.True(t, opened)
+
+	cb := b.(*circuitBreaker)
+	reasons := cb.throttle.(loggedThrottle).errWin.Export()
+	assert.NotEmpty(t, reasons)
+	assert.True(t, strings.HasPrefix(reasons[0].Reason, "slow call: "))
+}
+
+func TestBreakerSlowCallWithClock(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	b := NewBreaker(WithClock(clock), WithSlowCallThreshold(time.Millisecond*10))
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, b.Do(func() error {
+			clock.Advance(time.Millisecond * 20)
+			return nil
+		}))
+	}
+	assert.Equal(t, int64(0), b.Stats().Accepts)
+	assert.Equal(t, int64(3), b.Stats().Total)
+}
+
+func TestBreakerFastCallNotSlow(t *testing.T) {
+	b := NewBreaker(WithSlowCallThreshold(time.Second))
+	for i := 0; i < 100; i++ {
+		assert.Nil(t, b.Do(func() error {
+			return nil
+		}))
+	}
+	cb := b.(*circuitBreaker)
+	assert.Empty(t, cb.throttle.(loggedThrottle).errWin.Export())
+}
+
+func TestBreakerForceOpen(t *testing.T) {
+	b := NewBreaker()
+	b.ForceOpen()
+	assert.Equal(t, ForcedOpen, b.Stats().Forced)
+
+	for i := 0; i < 100; i++ {
+		err := b.Do(func() error {
+			t.Fatal("request should not be executed when forced open")
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrServiceUnavailable)
+		_, err = b.Allow()
+		assert.ErrorIs(t, err, ErrServiceUnavailable)
+	}
+
+	// 统计数据照常记录
+	st := b.Stats()
+	assert.Equal(t, int64(0), st.Accepts)
+	assert.Equal(t, int64(200), st.Total)
+
+	b.ClearForce()
+	assert.Equal(t, ForcedNone, b.Stats().Forced)
+}
+
+func TestBreakerForceClose(t *testing.T) {
+	b := withSeededProba(NewBreaker())
+	b.ForceClose()
+	assert.Equal(t, ForcedClosed, b.Stats().Forced)
+
+	errDown := errors.New("down")
+	for i := 0; i < 1000; i++ {
+		err := b.Do(func() error {
+			return errDown
+		})
+		assert.Equal(t, errDown, err)
+	}
+
+	st := b.Stats()
+	assert.Equal(t, int64(0), st.Accepts)
+	assert.Equal(t, int64(1000), st.Total)
+
+	// 取消强制之后恢复正常的熔断算法
+	b.ClearForce()
+	_, err := b.Allow()
+	for i := 0; i < 100 && err == nil; i++ {
+		_, err = b.Allow()
+	}
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+}
+
+func TestBreakerCanceledContext(t *testing.T) {
+	b := NewBreaker()
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, b.Do(func() error {
+			return nil
+		}))
+	}
+	before := b.Stats()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for i := 0; i < 1000; i++ {
+		_, err := b.AllowCtx(ctx)
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, context.Canceled, b.DoCtx(ctx, func() error {
+			t.Fatal("request should not be executed with canceled context")
+			return nil
+		}))
+		assert.Equal(t, context.Canceled, b.DoWithFallbackAcceptableCtx(ctx, func() error {
+			return nil
+		}, func(err error) error {
+			t.Fatal("fallback should not be called with canceled context")
+			return err
+		}, defaultAcceptable))
+	}
+
+	assert.Equal(t, before, b.Stats())
+	assert.Empty(t, b.(*circuitBreaker).throttle.(loggedThrottle).errWin.Export())
+
+	// ctx未结束时与普通方法一致
+	assert.Nil(t, b.DoCtx(context.Background(), func() error {
+		return nil
+	}))
+	assert.Equal(t, before.Total+1, b.Stats().Total)
+}
+
+func TestBreakerDoWithRetries(t *testing.T) {
+	noBackoff := func(int) time.Duration {
+		return 0
+	}
+	errDown := errors.New("down")
+
+	t.Run("success on second attempt", func(t *testing.T) {
+		b := NewBreaker()
+		var executed int
+		err := b.DoWithRetries(func() error {
+			executed++
+			if executed == 1 {
+				return errDown
+			}
+			return nil
+		}, 3, noBackoff)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, executed)
+		st := b.Stats()
+		assert.Equal(t, int64(1), st.Accepts)
+		assert.Equal(t, int64(2), st.Total)
+	})
+
+	t.Run("all attempts failed", func(t *testing.T) {
+		b := NewBreaker()
+		var executed int
+		err := b.DoWithRetries(func() error {
+			executed++
+			return errDown
+		}, 3, noBackoff)
+		assert.Equal(t, errDown, err)
+		assert.Equal(t, 3, executed)
+		assert.Equal(t, int64(3), b.Stats().Total)
+	})
+
+	t.Run("opened mid loop", func(t *testing.T) {
+		b := NewBreaker()
+		var executed int
+		err := b.DoWithRetries(func() error {
+			executed++
+			b.ForceOpen()
+			return errDown
+		}, 5, noBackoff)
+		assert.ErrorIs(t, err, ErrServiceUnavailable)
+		assert.Equal(t, 1, executed)
+	})
+
+	t.Run("retry all errors", func(t *testing.T) {
+		b := NewBreaker()
+		var executed int
+		err := b.DoWithRetries(func() error {
+			executed++
+			return context.Canceled
+		}, 3, noBackoff)
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, 3, executed)
+	})
+
+	t.Run("already open", func(t *testing.T) {
+		b := NewBreaker()
+		b.ForceOpen()
+		err := b.DoWithRetries(func() error {
+			t.Fatal("request should not be executed when open")
+			return nil
+		}, 5, nil)
+		assert.ErrorIs(t, err, ErrServiceUnavailable)
+	})
+}
+
+func TestBreakerDoWithRetry(t *testing.T) {
+	errDown := errors.New("down")
+
+	t.Run("success on later attempt", func(t *testing.T) {
+		b := NewBreaker()
+		var executed int
+		err := b.DoWithRetry(func() error {
+			executed++
+			if executed < 3 {
+				return errDown
+			}
+			return nil
+		}, 5, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, executed)
+		// 失败和之后的成功都计入统计
+		st := b.Stats()
+		assert.Equal(t, int64(1), st.Accepts)
+		assert.Equal(t, int64(3), st.Total)
+	})
+
+	t.Run("all attempts failed", func(t *testing.T) {
+		b := NewBreaker()
+		var executed int
+		err := b.DoWithRetry(func() error {
+			executed++
+			return errDown
+		}, 3, time.Millisecond)
+		assert.Equal(t, errDown, err)
+		assert.Equal(t, 3, executed)
+		st := b.Stats()
+		assert.Equal(t, int64(0), st.Accepts)
+		assert.Equal(t, int64(3), st.Total)
+	})
+
+	t.Run("opened mid loop", func(t *testing.T) {
+		b := NewBreaker()
+		var executed int
+		err := b.DoWithRetry(func() error {
+			executed++
+			if executed == 2 {
+				b.ForceOpen()
+			}
+			return errDown
+		}, 5, 0)
+		assert.ErrorIs(t, err, ErrServiceUnavailable)
+		assert.Equal(t, 2, executed)
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		b := NewBreaker(WithRetryableErrors(func(err error) bool {
+			return !errors.Is(err, errDown)
+		}))
+		var executed int
+		err := b.DoWithRetry(func() error {
+			executed++
+			return errDown
+		}, 5, 0)
+		assert.Equal(t, errDown, err)
+		assert.Equal(t, 1, executed)
+	})
+
+	t.Run("context errors not retried by default", func(t *testing.T) {
+		b := NewBreaker()
+		var executed int
+		err := b.DoWithRetry(func() error {
+			executed++
+			return context.DeadlineExceeded
+		}, 5, 0)
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, 1, executed)
+	})
+
+	assert.Panics(t, func() {
+		WithRetryableErrors(nil)
+	})
+}
+
+func TestRetryBackoff(t *testing.T) {
+	backoff := retryBackoff(time.Millisecond * 100)
+	assert.Equal(t, time.Millisecond*100, backoff.Backoff(1))
+	assert.Equal(t, time.Millisecond*200, backoff.Backoff(2))
+	assert.Equal(t, time.Millisecond*400, backoff.Backoff(3))
+	assert.Equal(t, retryMaxBackoff, backoff.Backoff(10))
+
+	// 大于最大等待时间时不再增长
+	assert.Equal(t, time.Second*10, retryBackoff(time.Second*10).Backoff(3))
+	assert.Equal(t, time.Duration(0), retryBackoff(0).Backoff(3))
+}
+
+func TestDefaultRetryBackoff(t *testing.T) {
+	for attempt, base := range map[int]time.Duration{
+		1:   retryBaseBackoff,
+		2:   retryBaseBackoff * 2,
+		3:   retryBaseBackoff * 4,
+		10:  retryMaxBackoff,
+		100: retryMaxBackoff,
+	} {
+		backoff := defaultRetryBackoff(attempt)
+		assert.GreaterOrEqual(t, backoff, time.Duration(float64(base)*(1-retryDeviation)))
+		assert.LessOrEqual(t, backoff, time.Duration(float64(base)*(1+retryDeviation)))
+	}
+}
+
+func TestBreakerOpenReport(t *testing.T) {
+	var levels []stat.Level
+	var msgs []string
+	stat.SetReporter(func(level stat.Level, msg string) {
+		levels = append(levels, level)
+		msgs = append(msgs, msg)
+	})
+	defer stat.SetReporter(nil)
+
+	b := NewBreaker(WithName("report-rpc"))
+	b.ForceOpen()
+	assert.ErrorIs(t, b.Do(func() error {
+		return nil
+	}), ErrServiceUnavailable)
+
+	assert.Equal(t, []stat.Level{stat.LevelError}, levels)
+	assert.True(t, strings.Contains(msgs[0], "callee: report-rpc"))
+}
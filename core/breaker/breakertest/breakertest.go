@@ -0,0 +1,359 @@
+package breakertest
+
+import (
+	"context"
+	"errors"
+	"go-zero-/core/breaker"
+	"sync"
+	"time"
+)
+
+// 用于单元测试的熔断器实现
+// 放行与否完全由预设的决策决定, 不依赖滑动窗口算法, 并记录每一次调用及其结果
+
+var _ breaker.Breaker = (*Breaker)(nil)
+
+type (
+	// Call 一次调用记录
+	Call struct {
+		// 调用的方法名, 例如 Do、Allow、Promise.Reject
+		Method string
+		// 是否被放行
+		Allowed bool
+		// 返回给调用方的错误
+		Err error
+		// Promise.Reject 的拒绝原因
+		Reason string
+	}
+
+	// Recorder 调用记录器
+	Recorder struct {
+		lock  sync.Mutex
+		calls []Call
+	}
+
+	Breaker struct {
+		name     string
+		lock     sync.Mutex
+		decide   func() bool
+		forced   breaker.ForceMode
+		accepts  int64
+		total    int64
+		recorder *Recorder
+	}
+
+	promise struct {
+		b *Breaker
+	}
+)
+
+// NewAlwaysOpen 返回总是拒绝请求的熔断器
+func NewAlwaysOpen() *Breaker {
+	return newBreaker("always-open", func() bool {
+		return false
+	})
+}
+
+// NewAlwaysClosed 返回总是放行请求的熔断器
+func NewAlwaysClosed() *Breaker {
+	return newBreaker("always-closed", func() bool {
+		return true
+	})
+}
+
+// NewScripted 按顺序使用decisions决定每次请求是否放行, true为放行
+// decisions用完之后重复最后一个决策, decisions为空时总是放行
+func NewScripted(decisions []bool) *Breaker {
+	var index int
+	return newBreaker("scripted", func() bool {
+		if len(decisions) == 0 {
+			return true
+		}
+
+		decision := decisions[index]
+		if index < len(decisions)-1 {
+			index++
+		}
+		return decision
+	})
+}
+
+func newBreaker(name string, decide func() bool) *Breaker {
+	return &Breaker{
+		name:     name,
+		decide:   decide,
+		recorder: new(Recorder),
+	}
+}
+
+// Recorder 返回调用记录器
+func (b *Breaker) Recorder() *Recorder {
+	return b.recorder
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+func (b *Breaker) Allow() (breaker.Promise, error) {
+	return b.allow("Allow")
+}
+
+func (b *Breaker) Do(req func() error) error {
+	return b.doReq("Do", req, nil, defaultClassifier)
+}
+
+func (b *Breaker) DoWithAcceptable(req func() error, acceptable breaker.Acceptable) error {
+	return b.doReq("DoWithAcceptable", req, nil, classifyBy(acceptable))
+}
+
+func (b *Breaker) DoWithFallback(req func() error, fallback breaker.Fallback) error {
+	return b.doReq("DoWithFallback", req, fallback, defaultClassifier)
+}
+
+func (b *Breaker) DoWithFallbackAcceptable(req func() error, fallback breaker.Fallback,
+	acceptable breaker.Acceptable) error {
+	return b.doReq("DoWithFallbackAcceptable", req, fallback, classifyBy(acceptable))
+}
+
+func (b *Breaker) DoWithClassifier(req func() error, classifier breaker.Classifier) error {
+	return b.doReq("DoWithClassifier", req, nil, classifier)
+}
+
+// DoWithRetries 与真实熔断器行为一致, backoff为nil时不等待
+func (b *Breaker) DoWithRetries(req func() error, attempts int, backoff func(attempt int) time.Duration) error {
+	var err error
+	for i := 0; i < attempts || i == 0; i++ {
+		if i > 0 && backoff != nil {
+			time.Sleep(backoff(i))
+		}
+		err = b.doReq("DoWithRetries", req, nil, defaultClassifier)
+		if err == nil || errors.Is(err, breaker.ErrServiceUnavailable) {
+			return err
+		}
+	}
+	return err
+}
+
+// DoWithRetry 与真实熔断器的默认行为一致, 熔断器拒绝和ctx结束的错误不重试, backoff不大于0时不等待
+func (b *Breaker) DoWithRetry(req func() error, maxAttempts int, backoff time.Duration) error {
+	var err error
+	for i := 0; i < maxAttempts || i == 0; i++ {
+		if i > 0 && backoff > 0 {
+			time.Sleep(backoff << (i - 1))
+		}
+		err = b.doReq("DoWithRetry", req, nil, defaultClassifier)
+		if err == nil || errors.Is(err, breaker.ErrServiceUnavailable) ||
+			errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+	}
+	return err
+}
+
+// Wrap 每次调用等同于 Do(fn), 调用记录的方法名为 Do
+func (b *Breaker) Wrap(fn func() error) func() error {
+	return func() error {
+		return b.Do(fn)
+	}
+}
+
+// WrapWithFallback 每次调用等同于 DoWithFallback(fn, fallback), 调用记录的方法名为 DoWithFallback
+func (b *Breaker) WrapWithFallback(fn func() error, fallback breaker.Fallback) func() error {
+	return func() error {
+		return b.DoWithFallback(fn, fallback)
+	}
+}
+
+func (b *Breaker) AllowCtx(ctx context.Context) (breaker.Promise, error) {
+	if err := ctx.Err(); err != nil {
+		b.recorder.add(Call{Method: "AllowCtx", Err: err})
+		return nil, err
+	}
+	return b.allow("AllowCtx")
+}
+
+func (b *Breaker) DoCtx(ctx context.Context, req func() error) error {
+	return b.doReqCtx(ctx, "DoCtx", req, nil, defaultClassifier)
+}
+
+func (b *Breaker) DoWithAcceptableCtx(ctx context.Context, req func() error, acceptable breaker.Acceptable) error {
+	return b.doReqCtx(ctx, "DoWithAcceptableCtx", req, nil, classifyBy(acceptable))
+}
+
+func (b *Breaker) DoWithFallbackCtx(ctx context.Context, req func() error, fallback breaker.Fallback) error {
+	return b.doReqCtx(ctx, "DoWithFallbackCtx", req, fallback, defaultClassifier)
+}
+
+func (b *Breaker) DoWithFallbackAcceptableCtx(ctx context.Context, req func() error, fallback breaker.Fallback,
+	acceptable breaker.Acceptable) error {
+	return b.doReqCtx(ctx, "DoWithFallbackAcceptableCtx", req, fallback, classifyBy(acceptable))
+}
+
+func (b *Breaker) ForceOpen() {
+	b.force(breaker.ForcedOpen)
+}
+
+func (b *Breaker) ForceClose() {
+	b.force(breaker.ForcedClosed)
+}
+
+func (b *Breaker) ClearForce() {
+	b.force(breaker.ForcedNone)
+}
+
+func (b *Breaker) Stats() breaker.Stats {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	return breaker.Stats{
+		Name:    b.name,
+		Accepts: b.accepts,
+		Total:   b.total,
+		Forced:  b.forced,
+	}
+}
+
+// LastErrors 测试熔断器不记录失败原因, 通过 Recorder 查看每一次调用的结果
+func (b *Breaker) LastErrors() []breaker.ErrorRecord {
+	return nil
+}
+
+// OpenDuration 测试熔断器不统计熔断时长
+func (b *Breaker) OpenDuration(window time.Duration) time.Duration {
+	return 0
+}
+
+func (b *Breaker) force(mode breaker.ForceMode) {
+	b.lock.Lock()
+	b.forced = mode
+	b.lock.Unlock()
+}
+
+func (b *Breaker) accept() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	switch b.forced {
+	case breaker.ForcedOpen:
+		return false
+	case breaker.ForcedClosed:
+		return true
+	default:
+		return b.decide()
+	}
+}
+
+func (b *Breaker) mark(success bool) {
+	b.lock.Lock()
+	if success {
+		b.accepts++
+	}
+	b.total++
+	b.lock.Unlock()
+}
+
+func (b *Breaker) allow(method string) (breaker.Promise, error) {
+	if !b.accept() {
+		b.mark(false)
+		err := b.rejected()
+		b.recorder.add(Call{Method: method, Err: err})
+		return nil, err
+	}
+
+	b.recorder.add(Call{Method: method, Allowed: true})
+	return promise{b: b}, nil
+}
+
+func (b *Breaker) doReqCtx(ctx context.Context, method string, req func() error, fallback breaker.Fallback,
+	classifier breaker.Classifier) error {
+	if err := ctx.Err(); err != nil {
+		b.recorder.add(Call{Method: method, Err: err})
+		return err
+	}
+	return b.doReq(method, req, fallback, classifier)
+}
+
+func (b *Breaker) doReq(method string, req func() error, fallback breaker.Fallback,
+	classifier breaker.Classifier) error {
+	if !b.accept() {
+		b.mark(false)
+		err := b.rejected()
+		if fallback != nil {
+			// 与真实熔断器一致, fallback失败时包装为FallbackError
+			if fbErr := fallback(err); fbErr == nil || fbErr == err {
+				err = fbErr
+			} else {
+				err = &breaker.FallbackError{Cause: err, FallbackErr: fbErr}
+			}
+		}
+		b.recorder.add(Call{Method: method, Err: err})
+		return err
+	}
+
+	err := req()
+	// 与真实熔断器一致, 被忽略的结果不计入统计
+	if classification := classifier(err); classification != breaker.Ignore {
+		b.mark(classification == breaker.Success)
+	}
+	b.recorder.add(Call{Method: method, Allowed: true, Err: err})
+	return err
+}
+
+// 与真实熔断器一致, 拒绝时返回带名字的 *breaker.BreakerError, 测试熔断器的拒绝是确定的, 丢弃比例为1
+func (b *Breaker) rejected() error {
+	return &breaker.BreakerError{
+		Name:      b.name,
+		DropRatio: 1,
+	}
+}
+
+func (p promise) Accept() {
+	p.b.mark(true)
+	p.b.recorder.add(Call{Method: "Promise.Accept", Allowed: true})
+}
+
+func (p promise) Reject(reason string) {
+	p.b.mark(false)
+	p.b.recorder.add(Call{Method: "Promise.Reject", Allowed: true, Reason: reason})
+}
+
+// Calls 返回所有调用记录的拷贝
+func (r *Recorder) Calls() []Call {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	calls := make([]Call, len(r.calls))
+	copy(calls, r.calls)
+	return calls
+}
+
+// Reset 清空调用记录
+func (r *Recorder) Reset() {
+	r.lock.Lock()
+	r.calls = nil
+	r.lock.Unlock()
+}
+
+func (r *Recorder) add(call Call) {
+	r.lock.Lock()
+	r.calls = append(r.calls, call)
+	r.lock.Unlock()
+}
+
+// 与真实熔断器一样使用 breaker.SetDefaultAcceptable 设置的默认判定方式
+func defaultClassifier(err error) breaker.Classification {
+	if breaker.DefaultAcceptable()(err) {
+		return breaker.Success
+	}
+	return breaker.Failure
+}
+
+func classifyBy(acceptable breaker.Acceptable) breaker.Classifier {
+	return func(err error) breaker.Classification {
+		if acceptable(err) {
+			return breaker.Success
+		}
+		return breaker.Failure
+	}
+}
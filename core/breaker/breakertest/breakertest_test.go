@@ -0,0 +1,254 @@
+package breakertest
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/breaker"
+	"testing"
+)
+
+// 业务代码示例: 熔断时走降级逻辑返回缓存数据
+type userHandler struct {
+	brk   breaker.Breaker
+	fetch func() (string, error)
+}
+
+func (h userHandler) GetUser() (string, error) {
+	var user string
+	err := h.brk.DoWithFallback(func() error {
+		var err error
+		user, err = h.fetch()
+		return err
+	}, func(err error) error {
+		user = "cached-user"
+		return nil
+	})
+	return user, err
+}
+
+// 业务代码示例: 手动上报结果
+func callManually(brk breaker.Breaker, req func() error) error {
+	promise, err := brk.Allow()
+	if err != nil {
+		return err
+	}
+
+	if err = req(); err != nil {
+		promise.Reject(err.Error())
+		return err
+	}
+	promise.Accept()
+	return nil
+}
+
+func TestHandlerFallbackWhenOpen(t *testing.T) {
+	brk := NewAlwaysOpen()
+	h := userHandler{
+		brk: brk,
+		fetch: func() (string, error) {
+			t.Fatal("downstream should not be called when breaker is open")
+			return "", nil
+		},
+	}
+
+	user, err := h.GetUser()
+	assert.Nil(t, err)
+	assert.Equal(t, "cached-user", user)
+	assert.Equal(t, []Call{{Method: "DoWithFallback"}}, brk.Recorder().Calls())
+}
+
+func TestHandlerWhenClosed(t *testing.T) {
+	brk := NewAlwaysClosed()
+	h := userHandler{
+		brk: brk,
+		fetch: func() (string, error) {
+			return "real-user", nil
+		},
+	}
+
+	user, err := h.GetUser()
+	assert.Nil(t, err)
+	assert.Equal(t, "real-user", user)
+	assert.Equal(t, []Call{{Method: "DoWithFallback", Allowed: true}}, brk.Recorder().Calls())
+	assert.Equal(t, int64(1), brk.Stats().Accepts)
+}
+
+func TestScripted(t *testing.T) {
+	brk := NewScripted([]bool{true, false, true})
+	var executed int
+	req := func() error {
+		executed++
+		return nil
+	}
+
+	assert.Nil(t, brk.Do(req))
+	assert.ErrorIs(t, brk.Do(req), breaker.ErrServiceUnavailable)
+	assert.Nil(t, brk.Do(req))
+	// 决策用完之后重复最后一个
+	assert.Nil(t, brk.Do(req))
+	assert.Equal(t, 3, executed)
+
+	st := brk.Stats()
+	assert.Equal(t, int64(3), st.Accepts)
+	assert.Equal(t, int64(4), st.Total)
+}
+
+func TestManualMode(t *testing.T) {
+	errDown := errors.New("down")
+	brk := NewScripted([]bool{true, true, false})
+
+	assert.Nil(t, callManually(brk, func() error {
+		return nil
+	}))
+	assert.Equal(t, errDown, callManually(brk, func() error {
+		return errDown
+	}))
+	assert.ErrorIs(t, callManually(brk, func() error {
+		return nil
+	}), breaker.ErrServiceUnavailable)
+
+	assert.Equal(t, []Call{
+		{Method: "Allow", Allowed: true},
+		{Method: "Promise.Accept", Allowed: true},
+		{Method: "Allow", Allowed: true},
+		{Method: "Promise.Reject", Allowed: true, Reason: "down"},
+		{Method: "Allow", Err: &breaker.BreakerError{Name: "scripted", DropRatio: 1}},
+	}, brk.Recorder().Calls())
+
+	brk.Recorder().Reset()
+	assert.Empty(t, brk.Recorder().Calls())
+}
+
+func TestForce(t *testing.T) {
+	brk := NewAlwaysClosed()
+	brk.ForceOpen()
+	assert.Equal(t, breaker.ForcedOpen, brk.Stats().Forced)
+	assert.ErrorIs(t, brk.Do(func() error {
+		return nil
+	}), breaker.ErrServiceUnavailable)
+
+	brk.ClearForce()
+	assert.Nil(t, brk.Do(func() error {
+		return nil
+	}))
+}
+
+func TestBreakerError(t *testing.T) {
+	brk := NewAlwaysOpen()
+	_, allowErr := brk.Allow()
+	for _, err := range []error{
+		brk.Do(func() error {
+			return nil
+		}),
+		allowErr,
+		brk.DoWithFallback(func() error {
+			return nil
+		}, func(err error) error {
+			return err
+		}),
+	} {
+		// 与真实熔断器一致, 可以取出熔断器名字
+		assert.ErrorIs(t, err, breaker.ErrServiceUnavailable)
+		var be *breaker.BreakerError
+		if assert.True(t, errors.As(err, &be)) {
+			assert.Equal(t, brk.Name(), be.Name)
+		}
+		var oe *breaker.CircuitOpenError
+		if assert.True(t, errors.As(err, &oe)) {
+			assert.Equal(t, brk.Name(), oe.BreakerName)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	brk := NewScripted([]bool{true, false})
+	call := brk.Wrap(func() error {
+		return nil
+	})
+	assert.Nil(t, call())
+	assert.ErrorIs(t, call(), breaker.ErrServiceUnavailable)
+
+	fallback := brk.WrapWithFallback(func() error {
+		return nil
+	}, func(err error) error {
+		return nil
+	})
+	assert.Nil(t, fallback())
+
+	calls := brk.Recorder().Calls()
+	if assert.Len(t, calls, 3) {
+		assert.Equal(t, Call{Method: "Do", Allowed: true}, calls[0])
+		assert.Equal(t, "Do", calls[1].Method)
+		assert.False(t, calls[1].Allowed)
+		assert.Equal(t, Call{Method: "DoWithFallback"}, calls[2])
+	}
+}
+
+func TestFallbackError(t *testing.T) {
+	errCache := errors.New("cache miss")
+	err := NewAlwaysOpen().DoWithFallback(func() error {
+		return nil
+	}, func(err error) error {
+		return errCache
+	})
+	assert.ErrorIs(t, err, errCache)
+	assert.ErrorIs(t, err, breaker.ErrServiceUnavailable)
+	var fe *breaker.FallbackError
+	assert.True(t, errors.As(err, &fe))
+}
+
+func TestDoWithClassifier(t *testing.T) {
+	b := NewAlwaysClosed()
+	errBadRequest := errors.New("bad request")
+	classify := func(err error) breaker.Classification {
+		switch {
+		case err == nil:
+			return breaker.Success
+		case errors.Is(err, errBadRequest):
+			return breaker.Ignore
+		default:
+			return breaker.Failure
+		}
+	}
+
+	assert.Equal(t, errBadRequest, b.DoWithClassifier(func() error {
+		return errBadRequest
+	}, classify))
+	assert.Nil(t, b.DoWithClassifier(func() error {
+		return nil
+	}, classify))
+	// 被忽略的结果不计入统计, 但仍然有调用记录
+	st := b.Stats()
+	assert.Equal(t, int64(1), st.Accepts)
+	assert.Equal(t, int64(1), st.Total)
+	assert.Equal(t, []Call{
+		{Method: "DoWithClassifier", Allowed: true, Err: errBadRequest},
+		{Method: "DoWithClassifier", Allowed: true},
+	}, b.Recorder().Calls())
+}
+
+func TestDefaultAcceptable(t *testing.T) {
+	errNotFound := errors.New("not found")
+	breaker.SetDefaultAcceptable(breaker.AcceptAny(breaker.AcceptNil, func(err error) bool {
+		return errors.Is(err, errNotFound)
+	}))
+	defer breaker.SetDefaultAcceptable(breaker.AcceptNil)
+
+	// 与真实熔断器按同样的方式判定
+	fake := NewAlwaysClosed()
+	actual := breaker.NewBreaker()
+	for _, b := range []breaker.Breaker{fake, actual} {
+		_ = b.Do(func() error {
+			return errNotFound
+		})
+		_ = b.DoWithFallback(func() error {
+			return errNotFound
+		}, nil)
+		_ = b.Do(func() error {
+			return errors.New("down")
+		})
+	}
+	assert.Equal(t, actual.Stats().Accepts, fake.Stats().Accepts)
+	assert.Equal(t, int64(2), fake.Stats().Accepts)
+	assert.Equal(t, int64(3), fake.Stats().Total)
+}
@@ -0,0 +1,41 @@
+package breaker
+
+import "time"
+
+// Builder 熔断器配置的链式构建器, 只是把配置累积为Option, 最终调用NewBreaker创建
+type Builder struct {
+	opts []Option
+}
+
+func NewBuilder() *Builder {
+	return new(Builder)
+}
+
+func (b *Builder) Name(name string) *Builder {
+	return b.with(WithName(name))
+}
+
+func (b *Builder) Window(d time.Duration) *Builder {
+	return b.with(WithWindow(d))
+}
+
+func (b *Builder) Buckets(n int) *Builder {
+	return b.with(WithBuckets(n))
+}
+
+func (b *Builder) K(k float64) *Builder {
+	return b.with(WithK(k))
+}
+
+func (b *Builder) Protection(n int64) *Builder {
+	return b.with(WithProtection(n))
+}
+
+func (b *Builder) Build() Breaker {
+	return NewBreaker(b.opts...)
+}
+
+func (b *Builder) with(opt Option) *Builder {
+	b.opts = append(b.opts, opt)
+	return b
+}
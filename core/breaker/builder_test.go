@@ -0,0 +1,44 @@
+package breaker
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestBuilder(t *testing.T) {
+	b := NewBuilder().
+		Name("user-rpc").
+		Window(time.Second * 5).
+		Buckets(10).
+		K(2).
+		Protection(20).
+		Build()
+
+	assert.Equal(t, "user-rpc", b.Name())
+	st := b.Stats()
+	assert.Equal(t, "user-rpc", st.Name)
+	assert.Equal(t, time.Second*5, st.Window)
+	assert.Equal(t, 10, st.Buckets)
+	assert.Equal(t, 2.0, st.K)
+	assert.Equal(t, int64(20), st.Protection)
+}
+
+func TestBuilderDefaults(t *testing.T) {
+	st := NewBuilder().Build().Stats()
+	assert.NotEmpty(t, st.Name)
+	assert.Equal(t, window, st.Window)
+	assert.Equal(t, buckets, st.Buckets)
+	assert.Equal(t, k, st.K)
+	assert.Equal(t, int64(protection), st.Protection)
+}
+
+func TestBuilderProtection(t *testing.T) {
+	// 保护请求数内, 即使全部失败也不会熔断
+	b := NewBuilder().Protection(100).Build()
+	for i := 0; i < 100; i++ {
+		p, err := b.Allow()
+		assert.Nil(t, err)
+		p.Reject("down")
+	}
+}
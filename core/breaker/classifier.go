@@ -0,0 +1,48 @@
+package breaker
+
+const (
+	// Success 请求成功
+	Success Classification = iota
+	// Failure 请求失败, 计入熔断器的失败统计
+	Failure
+	// Ignore 错误照常返回给调用方, 但既不计为成功也不计为失败, 例如客户端参数错误、被下游限流
+	Ignore
+)
+
+type (
+	// Classification 请求结果的分类
+	Classification int
+
+	// Classifier 自定义请求结果的分类, 比 Acceptable 多了一种不影响熔断器的 Ignore
+	Classifier func(err error) Classification
+
+	// 请求结果的判定方式, Acceptable 和 Classifier 都实现了该接口
+	// 函数值可以直接存入接口, 不需要像包装成闭包一样每次请求都分配内存
+	resultClassifier interface {
+		classify(err error) Classification
+	}
+)
+
+func (c Classification) String() string {
+	switch c {
+	case Success:
+		return "success"
+	case Failure:
+		return "failure"
+	case Ignore:
+		return "ignore"
+	default:
+		return "unknown"
+	}
+}
+
+func (a Acceptable) classify(err error) Classification {
+	if a(err) {
+		return Success
+	}
+	return Failure
+}
+
+func (c Classifier) classify(err error) Classification {
+	return c(err)
+}
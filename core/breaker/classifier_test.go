@@ -0,0 +1,113 @@
+package breaker
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+var (
+	errBadRequest  = errors.New("bad request")
+	errRateLimited = errors.New("rate limited")
+)
+
+func classifyTestErrors(err error) Classification {
+	switch {
+	case err == nil, errors.Is(err, errBiz):
+		return Success
+	case errors.Is(err, errBadRequest), errors.Is(err, errRateLimited):
+		return Ignore
+	default:
+		return Failure
+	}
+}
+
+func TestDoWithClassifierIgnore(t *testing.T) {
+	b := NewBreaker()
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, b.Do(func() error {
+			return nil
+		}))
+	}
+	before := b.Stats()
+
+	// 全部被忽略的错误照常返回, 但不改变滑动窗口
+	for i := 0; i < 1000; i++ {
+		err := errBadRequest
+		if i%2 == 0 {
+			err = errRateLimited
+		}
+		assert.Equal(t, err, b.DoWithClassifier(func() error {
+			return err
+		}, classifyTestErrors))
+	}
+	assert.Equal(t, before, b.Stats())
+	assert.Empty(t, b.LastErrors())
+	assert.Nil(t, b.Do(func() error {
+		return nil
+	}))
+}
+
+func TestDoWithClassifier(t *testing.T) {
+	b := NewBreaker()
+	errDown := errors.New("down")
+
+	assert.Equal(t, errBiz, b.DoWithClassifier(func() error {
+		return errBiz
+	}, classifyTestErrors))
+	assert.Equal(t, errDown, b.DoWithClassifier(func() error {
+		return errDown
+	}, classifyTestErrors))
+	// 未知的分类按失败处理
+	assert.Nil(t, b.DoWithClassifier(func() error {
+		return nil
+	}, func(err error) Classification {
+		return Classification(100)
+	}))
+
+	st := b.Stats()
+	assert.Equal(t, int64(1), st.Accepts)
+	assert.Equal(t, int64(3), st.Total)
+	errs := b.LastErrors()
+	assert.Len(t, errs, 1)
+	assert.Equal(t, "down", errs[0].Reason)
+}
+
+func TestDoWithClassifierPanic(t *testing.T) {
+	b := NewBreaker(WithPanicAsError())
+	err := b.DoWithClassifier(func() error {
+		panic("boom")
+	}, func(err error) Classification {
+		return Ignore
+	})
+	assert.Error(t, err)
+	// panic不经过分类, 总是记为失败
+	st := b.Stats()
+	assert.Equal(t, int64(0), st.Accepts)
+	assert.Equal(t, int64(1), st.Total)
+}
+
+func TestDoWithClassifierHierarchy(t *testing.T) {
+	h := NewHierarchy("classifier-rpc")
+	child := h.Child("GetUser")
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, errBadRequest, child.DoWithClassifier(func() error {
+			return errBadRequest
+		}, classifyTestErrors))
+	}
+	assert.Equal(t, int64(0), child.Stats().Total)
+	assert.Equal(t, int64(0), h.Stats().Total)
+
+	assert.Nil(t, child.DoWithClassifier(func() error {
+		return nil
+	}, classifyTestErrors))
+	assert.Equal(t, int64(1), child.Stats().Accepts)
+	assert.Equal(t, int64(1), h.Stats().Accepts)
+}
+
+func TestClassificationString(t *testing.T) {
+	assert.Equal(t, "success", Success.String())
+	assert.Equal(t, "failure", Failure.String())
+	assert.Equal(t, "ignore", Ignore.String())
+	assert.Equal(t, "unknown", Classification(100).String())
+}
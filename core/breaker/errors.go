@@ -0,0 +1,93 @@
+package breaker
+
+import (
+	"fmt"
+	"time"
+)
+
+// FallbackError fallback本身失败时返回的错误, errors.Is 对触发fallback的原因和fallback返回的错误都成立
+type FallbackError struct {
+	// 触发fallback的原因, 即熔断器拒绝请求的错误
+	Cause error
+	// fallback返回的错误
+	FallbackErr error
+}
+
+// BreakerError 熔断器拒绝请求时返回的错误, errors.Is(err, ErrServiceUnavailable) 仍然成立
+type BreakerError struct {
+	// 拒绝请求的熔断器名字, 层级熔断器中可能是父熔断器
+	Name string
+	// 拒绝时的丢弃比例, 强制熔断或冷却期内为1
+	DropRatio float64
+	// 建议客户端等待多久再重试, 可用于设置 Retry-After
+	RetryAfter time.Duration
+	// 拒绝时该熔断器最近的失败原因, 按时间从新到旧, 不需要查日志就能知道为什么熔断
+	RecentErrors []string
+}
+
+// BreakerOpenError 与 BreakerError 是同一个类型, errors.As 使用任意一个都可以取出熔断器名字
+type BreakerOpenError = BreakerError
+
+// CircuitOpenError 熔断器拒绝请求时的错误信息, errors.Is(err, ErrServiceUnavailable) 成立
+// 熔断器返回的是 *BreakerError, 可以通过 errors.As 转换为 *CircuitOpenError
+type CircuitOpenError struct {
+	// 拒绝请求的熔断器名字, 层级熔断器中可能是父熔断器
+	BreakerName string
+	// 拒绝时的丢弃比例, 强制熔断或冷却期内为1
+	DropRatio float64
+	// 拒绝时该熔断器最近的失败原因, 按时间从新到旧
+	RecentErrors []string
+}
+
+func (e *BreakerError) Error() string {
+	return fmt.Sprintf("%s: %s, drop ratio: %.2f, retry after: %s",
+		ErrServiceUnavailable, e.Name, e.DropRatio, e.RetryAfter)
+}
+
+func (e *BreakerError) Unwrap() error {
+	return ErrServiceUnavailable
+}
+
+// As 支持 errors.As 取出 *CircuitOpenError
+func (e *BreakerError) As(target any) bool {
+	oe, ok := target.(**CircuitOpenError)
+	if !ok {
+		return false
+	}
+
+	*oe = &CircuitOpenError{
+		BreakerName:  e.Name,
+		DropRatio:    e.DropRatio,
+		RecentErrors: e.RecentErrors,
+	}
+	return true
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s: %s, drop ratio: %.2f", ErrServiceUnavailable, e.BreakerName, e.DropRatio)
+}
+
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrServiceUnavailable
+}
+
+func (e *FallbackError) Error() string {
+	return fmt.Sprintf("fallback failed: %v (cause: %v)", e.FallbackErr, e.Cause)
+}
+
+func (e *FallbackError) Unwrap() []error {
+	return []error{e.FallbackErr, e.Cause}
+}
+
+// 执行fallback, fallback原样返回cause时不再包装
+func doFallback(fallback Fallback, cause error) error {
+	err := fallback(cause)
+	if err == nil || err == cause {
+		return err
+	}
+
+	return &FallbackError{
+		Cause:       cause,
+		FallbackErr: err,
+	}
+}
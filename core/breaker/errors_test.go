@@ -0,0 +1,241 @@
+package breaker
+
+import (
+	"errors"
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBreakerError(t *testing.T) {
+	b := NewBreaker(WithName("error-rpc"), WithWindow(time.Second*4), WithBuckets(4))
+	b.ForceOpen()
+
+	err := b.Do(func() error {
+		return nil
+	})
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+	var be *BreakerError
+	assert.True(t, errors.As(err, &be))
+	assert.Equal(t, "error-rpc", be.Name)
+	assert.Equal(t, 1.0, be.DropRatio)
+	assert.Equal(t, time.Second, be.RetryAfter)
+	assert.True(t, strings.HasPrefix(err.Error(), ErrServiceUnavailable.Error()))
+
+	_, err = b.Allow()
+	assert.True(t, errors.As(err, &be))
+	assert.Equal(t, "error-rpc", be.Name)
+
+	// fallback拿到的也是带名字的错误
+	assert.Nil(t, b.DoWithFallback(func() error {
+		return nil
+	}, func(err error) error {
+		assert.True(t, errors.As(err, &be))
+		assert.Equal(t, "error-rpc", be.Name)
+		return nil
+	}))
+}
+
+func TestBreakerOpenError(t *testing.T) {
+	b := NewBreaker(WithName("open-error-rpc"))
+	b.ForceOpen()
+
+	for _, err := range []error{
+		b.Do(func() error {
+			return nil
+		}),
+		func() error {
+			_, err := b.Allow()
+			return err
+		}(),
+	} {
+		assert.True(t, errors.Is(err, ErrServiceUnavailable))
+		var oe *BreakerOpenError
+		if assert.True(t, errors.As(err, &oe)) {
+			assert.Equal(t, b.Name(), oe.Name)
+		}
+	}
+}
+
+func TestBreakerErrorDropRatio(t *testing.T) {
+	b := newTestGoogleBreaker()
+	errDown := errors.New("down")
+	var be *BreakerError
+	for i := 0; i < 100 && be == nil; i++ {
+		err := b.doReq(func() error {
+			return errDown
+		}, nil, defaultAcceptable, nil)
+		errors.As(err, &be)
+	}
+	if assert.NotNil(t, be) {
+		assert.Equal(t, "test", be.Name)
+		assert.True(t, be.DropRatio > 0 && be.DropRatio < 1)
+		assert.Equal(t, window/buckets, be.RetryAfter)
+	}
+}
+
+func TestBreakerErrorCooldown(t *testing.T) {
+	b := newTestGoogleBreaker(func(s *googleSettings) {
+		s.cooldown = time.Minute
+	})
+	// 开始冷却之前可能已经按概率拒绝过, 取最后一次的错误
+	var be *BreakerError
+	for i := 0; i < 100; i++ {
+		errors.As(b.doReq(func() error {
+			return errors.New("down")
+		}, nil, defaultAcceptable, nil), &be)
+	}
+	if assert.NotNil(t, be) {
+		assert.Equal(t, 1.0, be.DropRatio)
+		assert.True(t, be.RetryAfter > time.Second*59)
+	}
+}
+
+func TestBreakerErrorHierarchy(t *testing.T) {
+	h := NewHierarchy("error-hierarchy-rpc")
+	child := h.Child("GetUser")
+
+	var be *BreakerError
+	child.ForceOpen()
+	assert.True(t, errors.As(child.Do(func() error {
+		return nil
+	}), &be))
+	assert.Equal(t, "error-hierarchy-rpc/GetUser", be.Name)
+
+	child.ClearForce()
+	h.ForceOpen()
+	assert.True(t, errors.As(child.Do(func() error {
+		return nil
+	}), &be))
+	assert.Equal(t, "error-hierarchy-rpc", be.Name)
+}
+
+func TestFallbackError(t *testing.T) {
+	errCache := errors.New("cache miss")
+	b := NewBreaker(WithName("fallback-rpc"))
+	b.ForceOpen()
+
+	err := b.DoWithFallback(func() error {
+		return nil
+	}, func(err error) error {
+		return errCache
+	})
+	assert.ErrorIs(t, err, errCache)
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+	var fe *FallbackError
+	if assert.True(t, errors.As(err, &fe)) {
+		assert.Equal(t, errCache, fe.FallbackErr)
+		var be *BreakerError
+		assert.True(t, errors.As(fe.Cause, &be))
+		assert.Equal(t, "fallback-rpc", be.Name)
+	}
+	assert.True(t, strings.HasPrefix(err.Error(), "fallback failed: cache miss (cause: "))
+
+	// fallback成功或者原样返回原因时, 与之前的行为一致
+	assert.Nil(t, b.DoWithFallback(func() error {
+		return nil
+	}, func(err error) error {
+		return nil
+	}))
+	err = b.DoWithFallback(func() error {
+		return nil
+	}, func(err error) error {
+		return err
+	})
+	assert.False(t, errors.As(err, &fe))
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+
+	// 请求真正执行时不会调用fallback, 返回请求本身的错误
+	b.ClearForce()
+	errReq := errors.New("request failed")
+	err = b.DoWithFallback(func() error {
+		return errReq
+	}, func(err error) error {
+		return errCache
+	})
+	assert.Equal(t, errReq, err)
+}
+
+func TestCircuitOpenError(t *testing.T) {
+	b := NewBreaker(WithName("circuit-rpc"))
+	var oe *CircuitOpenError
+	b.ForceOpen()
+	assert.True(t, errors.As(b.Do(func() error {
+		return nil
+	}), &oe))
+	assert.Empty(t, oe.RecentErrors)
+
+	b.ClearForce()
+	for _, reason := range []string{"err-1", "err-2"} {
+		assert.Error(t, b.Do(func() error {
+			return errors.New(reason)
+		}))
+	}
+	b.ForceOpen()
+
+	for _, err := range []error{
+		b.Do(func() error {
+			return nil
+		}),
+		func() error {
+			_, err := b.Allow()
+			return err
+		}(),
+		b.DoWithFallback(func() error {
+			return nil
+		}, func(err error) error {
+			return err
+		}),
+	} {
+		assert.ErrorIs(t, err, ErrServiceUnavailable)
+		if assert.True(t, errors.As(err, &oe)) {
+			assert.Equal(t, "circuit-rpc", oe.BreakerName)
+			assert.Equal(t, 1.0, oe.DropRatio)
+			assert.Equal(t, []string{"err-2", "err-1"}, oe.RecentErrors)
+		}
+	}
+}
+
+func TestCircuitOpenErrorIs(t *testing.T) {
+	err := &CircuitOpenError{
+		BreakerName:  "circuit-is-rpc",
+		DropRatio:    0.5,
+		RecentErrors: []string{"err-1"},
+	}
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+	assert.ErrorIs(t, fmt.Errorf("wrapped: %w", err), ErrServiceUnavailable)
+	assert.False(t, errors.Is(err, errors.New("circuit breaker is open")))
+	assert.Equal(t, "circuit breaker is open: circuit-is-rpc, drop ratio: 0.50", err.Error())
+
+	var oe *CircuitOpenError
+	assert.True(t, errors.As(err, &oe))
+	assert.Same(t, err, oe)
+	// 其它错误不能转换
+	assert.False(t, errors.As(errors.New("down"), &oe))
+}
+
+func TestCircuitOpenErrorHierarchy(t *testing.T) {
+	h := NewHierarchy("circuit-hierarchy-rpc")
+	child := h.Child("GetUser")
+	assert.Error(t, child.Do(func() error {
+		return errors.New("get failed")
+	}))
+
+	var oe *CircuitOpenError
+	child.ForceOpen()
+	assert.True(t, errors.As(child.Do(func() error {
+		return nil
+	}), &oe))
+	assert.Equal(t, []string{"get failed"}, oe.RecentErrors)
+
+	// 父熔断器拒绝时带上父熔断器的错误记录
+	child.ClearForce()
+	h.ForceOpen()
+	assert.True(t, errors.As(child.Do(func() error {
+		return nil
+	}), &oe))
+	assert.Equal(t, "circuit-hierarchy-rpc", oe.BreakerName)
+	assert.Equal(t, []string{"GetUser: get failed"}, oe.RecentErrors)
+}
@@ -1,8 +1,11 @@
 package breaker
 
 import (
+	"fmt"
 	"go-zero-/core/collection"
 	"go-zero-/core/mathx"
+	"go-zero-/core/timex"
+	"sync/atomic"
 	"time"
 )
 
@@ -11,52 +14,246 @@ const (
 	buckets    = 40
 	k          = 1.5
 	protection = 5
+	// 默认不限制最大丢弃比例
+	defaultMaxDropRatio = 1.0
+	// 丢弃比例超过该值时开始冷却
+	defaultCooldownRatio = 0.5
+	// 未处于熔断中
+	notOpened = -1
 )
 
-type googleBreaker struct {
-	// 敏感度
-	k float64
-	// 滑动窗口
-	stat *collection.RollingWindow
-	// 概率生成器 0.0 - 1.0 之间
-	proba *mathx.Proba
+type (
+	googleBreaker struct {
+		googleSettings
+		name string
+		// 滑动窗口
+		stat *collection.RollingWindow
+		// 概率生成器 0.0 - 1.0 之间
+		proba probability
+		// 强制模式, 原子读写
+		forced int32
+		// 本次熔断开始冷却的时间, notOpened表示未处于熔断中, 原子读写
+		openedAt int64
+		// 从这个时间开始一直拒绝请求, notOpened表示最近放行过请求, 原子读写
+		rejectingSince int64
+		clock          timex.Clock
+		// 定期上报的计数, 未开启时为nil
+		counter *summaryCounter
+		// 处于熔断中的时间段
+		openTime openTracker
+		// 失败原因的记录, 拒绝请求时带上最近的失败原因, 为nil时不带
+		errWin *errorWindow
+		// 请求耗时的观察者, 未设置时为nil
+		observer *latencyObserver
+	}
+
+	// 熔断器配置, 可比较, 用于判断同名熔断器的配置是否冲突
+	googleSettings struct {
+		// 敏感度
+		k float64
+		// 请求数低于该值时不会熔断
+		protection int64
+		// 滑动窗口时长以及桶的数量
+		window  time.Duration
+		buckets int
+		// 最大丢弃比例, 保证下游完全不可用时仍有少量真实请求去探测下游是否恢复
+		maxDropRatio float64
+		// 拒绝请求时是否记为一次失败
+		markFailureOnReject bool
+		// req panic时是否recover并作为错误返回, 默认继续panic
+		panicAsError bool
+		// 慢调用阈值, 大于0时耗时超过该值的请求记为失败
+		slowCallThreshold time.Duration
+		// 冷却时长, 丢弃比例第一次超过cooldownRatio之后, 冷却期内拒绝所有请求
+		cooldown      time.Duration
+		cooldownRatio float64
+		// 连续拒绝超过该时长时放行一个探测请求, 0表示不开启
+		maxOpenDuration time.Duration
+	}
+
+	googleOption func(s *googleSettings)
+
+	// 请求结果的记录方式, 层级熔断器的子熔断器需要同时记录到父熔断器
+	outcomeMarker interface {
+		markSuccess()
+		markFailure()
+	}
+
+	// 概率生成器, 测试时可替换为确定性的实现
+	probability interface {
+		TrueOnProba(proba float64) bool
+	}
+)
+
+func newGoogleBreaker(name string, clock timex.Clock, errWin *errorWindow, opts ...googleOption) *googleBreaker {
+	b := &googleBreaker{
+		googleSettings: newGoogleSettings(opts...),
+		name:           name,
+		proba:          mathx.NewProba(),
+		openedAt:       notOpened,
+		rejectingSince: notOpened,
+		clock:          clock,
+		openTime:       newOpenTracker(),
+		errWin:         errWin,
+	}
+
+	bucketDuration := time.Duration(int64(b.window) / int64(b.buckets))
+	b.stat = collection.NewRollingWindow(b.buckets, bucketDuration, collection.WithWindowClock(clock))
+	return b
 }
 
-func newGoogleBreaker() *googleBreaker {
-	bucketDuration := time.Duration(int64(window) / int64(buckets))
-	st := collection.NewRollingWindow(buckets, bucketDuration)
-	return &googleBreaker{
-		stat:  st,
-		k:     k,
-		proba: mathx.NewProba(),
+func newGoogleSettings(opts ...googleOption) googleSettings {
+	s := googleSettings{
+		k:                   k,
+		protection:          protection,
+		window:              window,
+		buckets:             buckets,
+		maxDropRatio:        defaultMaxDropRatio,
+		markFailureOnReject: true,
+		cooldownRatio:       defaultCooldownRatio,
+	}
+	for _, opt := range opts {
+		opt(&s)
 	}
+	return s
 }
 
 func (b *googleBreaker) accept() error {
+	open, err := b.judge()
+	b.openTime.mark(open, b.clock.Now())
+	return err
+}
+
+// 判定是否放行请求, open表示当前是否处于熔断中, 即丢弃比例大于0
+func (b *googleBreaker) judge() (open bool, err error) {
+	switch ForceMode(atomic.LoadInt32(&b.forced)) {
+	case ForcedOpen:
+		return true, b.reject(1)
+	case ForcedClosed:
+		return false, nil
+	}
+
+	// 冷却期内直接拒绝, 不需要计算丢弃比例
+	if b.inCooldown() {
+		return true, b.reject(1)
+	}
+
 	accepts, total := b.history()
 
 	weightedAccepts := b.k + float64(accepts)
-	dropRatio := (float64(total-protection) - weightedAccepts) / float64(total+1)
+	// 分母的+1来自Google SRE的公式, 没有请求时分子为负数, 不会熔断
+	dropRatio := mathx.SafeDiv(float64(total-b.protection)-weightedAccepts, float64(total+1))
 	if dropRatio <= 0 {
-		return nil
+		if b.cooldown > 0 {
+			// 已恢复, 结束本次熔断
+			atomic.StoreInt64(&b.openedAt, notOpened)
+		}
+		b.markAdmitted()
+		return false, nil
+	}
+	if b.cooldown > 0 && dropRatio >= b.cooldownRatio &&
+		atomic.CompareAndSwapInt64(&b.openedAt, notOpened, int64(b.clock.Now())) {
+		// 本次熔断第一次超过阈值, 开始冷却
+		return true, b.reject(1)
 	}
-	if b.proba.TrueOnProba(dropRatio) {
-		return ErrServiceUnavailable
+	// maxDropRatio 不超过1, 丢弃比例限制在 [0, maxDropRatio]
+	dropRatio = mathx.Clamp(dropRatio, 0, b.maxDropRatio)
+	if b.proba.TrueOnProba(dropRatio) && !b.shouldProbe() {
+		return true, b.reject(dropRatio)
 	}
-	return nil
+	b.markAdmitted()
+	return true, nil
+}
+
+// 连续拒绝超过maxOpenDuration时, 不管丢弃比例是多少都放行一个探测请求
+// 避免流量很小时所有请求都被拒绝, 下游恢复了熔断器却一直无法恢复, 并发时只有一个请求能成为探测请求
+func (b *googleBreaker) shouldProbe() bool {
+	if b.maxOpenDuration <= 0 {
+		return false
+	}
+
+	since := atomic.LoadInt64(&b.rejectingSince)
+	if since == notOpened || b.clock.Since(time.Duration(since)) < b.maxOpenDuration {
+		return false
+	}
+	return atomic.CompareAndSwapInt64(&b.rejectingSince, since, notOpened)
+}
+
+func (b *googleBreaker) markAdmitted() {
+	if b.maxOpenDuration > 0 && atomic.LoadInt64(&b.rejectingSince) != notOpened {
+		atomic.StoreInt64(&b.rejectingSince, notOpened)
+	}
+}
+
+// 建议的重试时间为一个桶的时长, 即窗口往前滑动一次的时间, 冷却期内至少等到冷却结束
+func (b *googleBreaker) reject(dropRatio float64) error {
+	// 记录连续拒绝的开始时间
+	if b.maxOpenDuration > 0 && atomic.LoadInt64(&b.rejectingSince) == notOpened {
+		atomic.CompareAndSwapInt64(&b.rejectingSince, notOpened, int64(b.clock.Now()))
+	}
+
+	retryAfter := time.Duration(int64(b.window) / int64(b.buckets))
+	if b.cooldown > 0 {
+		if openedAt := atomic.LoadInt64(&b.openedAt); openedAt != notOpened {
+			if remain := b.cooldown - b.clock.Since(time.Duration(openedAt)); remain > retryAfter {
+				retryAfter = remain
+			}
+		}
+	}
+
+	return &BreakerError{
+		Name:         b.name,
+		DropRatio:    dropRatio,
+		RetryAfter:   retryAfter,
+		RecentErrors: b.errWin.recentErrors(),
+	}
+}
+
+func (b *googleBreaker) inCooldown() bool {
+	if b.cooldown <= 0 {
+		return false
+	}
+
+	openedAt := atomic.LoadInt64(&b.openedAt)
+	return openedAt != notOpened && b.clock.Since(time.Duration(openedAt)) < b.cooldown
 }
 
 func (b *googleBreaker) history() (accepts, total int64) {
-	b.stat.Reduce(func(b *collection.Bucket) {
-		accepts += int64(b.Sum)
-		total += b.Count
-	})
-	return
+	sum, total := b.stat.SumAndCount()
+	return int64(sum), total
+}
+
+func (b *googleBreaker) force(mode ForceMode) {
+	atomic.StoreInt32(&b.forced, int32(mode))
+}
+
+func (b *googleBreaker) openDuration(window time.Duration) time.Duration {
+	return b.openTime.duration(b.clock.Now(), window)
+}
+
+// 熔断中的时间段属于历史记录, 用于计算SLO, 不会被清空
+func (b *googleBreaker) reset() {
+	b.stat.Reset()
+	atomic.StoreInt64(&b.openedAt, notOpened)
+	atomic.StoreInt64(&b.rejectingSince, notOpened)
+}
+
+func (b *googleBreaker) stats() Stats {
+	accepts, total := b.history()
+	return Stats{
+		Accepts:    accepts,
+		Total:      total,
+		Forced:     ForceMode(atomic.LoadInt32(&b.forced)),
+		Window:     b.window,
+		Buckets:    b.buckets,
+		K:          b.k,
+		Protection: b.protection,
+	}
 }
 
 func (b *googleBreaker) allow() (internalPromise, error) {
 	if err := b.accept(); err != nil {
-		b.markFailure()
+		b.markRejected()
 		return nil, err
 	}
 
@@ -65,40 +262,94 @@ func (b *googleBreaker) allow() (internalPromise, error) {
 	}, nil
 }
 
-func (b *googleBreaker) doReq(req func() error, fallback Fallback, acceptable Acceptable) error {
+func (b *googleBreaker) doReq(req func() error, fallback Fallback, classifier resultClassifier,
+	recorder reasonRecorder) error {
 	if err := b.accept(); err != nil {
-		b.markFailure()
+		b.markRejected()
+		b.observer.observeRejected(err)
 		if fallback != nil {
-			return fallback(err)
+			return doFallback(fallback, err)
 		}
 
 		return err
 	}
 
-	var success bool
+	return b.execute(req, classifier, recorder, b)
+}
+
+// 执行已经放行的请求, 结果通过marker记录, 被忽略的结果不记录
+func (b *googleBreaker) execute(req func() error, classifier resultClassifier, recorder reasonRecorder,
+	marker outcomeMarker) (err error) {
+	// if req() panic, outcome is Failure, mark as failure
+	outcome := Failure
 	defer func() {
-		// if req() panic, success is false, mark as failure
-		if success {
-			b.markSuccess()
-		} else {
-			b.markFailure()
+		switch outcome {
+		case Success:
+			marker.markSuccess()
+		case Failure:
+			marker.markFailure()
 		}
 	}()
+	if b.panicAsError {
+		// 后注册先执行, 先recover再由上面的defer记为失败
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("breaker: panic recovered: %v", r)
+			}
+		}()
+	}
+
+	var start time.Duration
+	if b.slowCallThreshold > 0 || b.observer != nil {
+		start = b.clock.Now()
+	}
+	err = req()
+	if b.observer != nil {
+		b.observer.observe(b.clock.Since(start), err)
+	}
+	// 未知的分类按失败处理
+	classification := classifier.classify(err)
+	if classification == Ignore {
+		outcome = Ignore
+		return err
+	}
+	if classification != Success {
+		if err != nil && recorder != nil {
+			recorder.add(err.Error())
+		}
+		return err
+	}
 
-	err := req()
-	if acceptable(err) {
-		success = true
+	// 慢调用只影响统计, 调用方拿到的仍然是原始结果
+	if b.slowCallThreshold > 0 {
+		if elapsed := b.clock.Since(start); elapsed > b.slowCallThreshold {
+			if recorder != nil {
+				recorder.add(fmt.Sprintf("slow call: %s", elapsed.Round(time.Millisecond)))
+			}
+			return err
+		}
 	}
 
+	outcome = Success
 	return err
 }
 
 func (b *googleBreaker) markSuccess() {
 	b.stat.Add(1)
+	b.counter.admit(false)
 }
 
 func (b *googleBreaker) markFailure() {
 	b.stat.Add(0)
+	b.counter.admit(true)
+}
+
+// 请求被熔断拒绝, 并没有真正执行
+func (b *googleBreaker) markRejected() {
+	if b.markFailureOnReject {
+		b.stat.Add(0)
+	}
+	b.counter.drop()
 }
 
 type googlePromise struct {
@@ -0,0 +1,352 @@
+package breaker
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/timex"
+	"testing"
+	"time"
+)
+
+// 确定性的概率生成器, 按比例累积, 累积满1时返回true
+type stepProba struct {
+	acc float64
+}
+
+func (p *stepProba) TrueOnProba(proba float64) bool {
+	p.acc += proba
+	if p.acc >= 1 {
+		p.acc--
+		return true
+	}
+	return false
+}
+
+func newTestGoogleBreaker(opts ...googleOption) *googleBreaker {
+	return newTestGoogleBreakerWithClock(timex.RealClock{}, opts...)
+}
+
+func newTestGoogleBreakerWithClock(clock timex.Clock, opts ...googleOption) *googleBreaker {
+	b := newGoogleBreaker("test", clock, nil, opts...)
+	b.proba = new(stepProba)
+	return b
+}
+
+func withMaxDropRatio(r float64) googleOption {
+	return func(s *googleSettings) {
+		s.maxDropRatio = r
+	}
+}
+
+func TestGoogleBreakerNoTraffic(t *testing.T) {
+	// 没有任何请求时, 即使不设保护请求数也不会熔断
+	for _, protection := range []int64{protection, 0} {
+		b := newTestGoogleBreaker(func(s *googleSettings) {
+			s.protection = protection
+		})
+		open, err := b.judge()
+		assert.False(t, open)
+		assert.Nil(t, err)
+		assert.Nil(t, b.accept())
+	}
+}
+
+func TestGoogleBreakerMaxDropRatio(t *testing.T) {
+	b := newTestGoogleBreaker(withMaxDropRatio(0.9))
+	errDown := errors.New("down")
+
+	const attempts = 1000
+	var executed int
+	for i := 0; i < attempts; i++ {
+		_ = b.doReq(func() error {
+			executed++
+			return errDown
+		}, nil, defaultAcceptable, nil)
+	}
+	assert.GreaterOrEqual(t, executed, attempts/10)
+
+	// 下游恢复后, 有限次数内就会有真实请求到达下游
+	var healed int
+	for i := 0; i < 20 && healed == 0; i++ {
+		_ = b.doReq(func() error {
+			healed++
+			return nil
+		}, nil, defaultAcceptable, nil)
+	}
+	assert.Equal(t, 1, healed)
+}
+
+func TestGoogleBreakerNoMaxDropRatio(t *testing.T) {
+	b := newTestGoogleBreaker()
+	errDown := errors.New("down")
+
+	const attempts = 1000
+	var executed int
+	for i := 0; i < attempts; i++ {
+		_ = b.doReq(func() error {
+			executed++
+			return errDown
+		}, nil, defaultAcceptable, nil)
+	}
+	assert.Less(t, executed, attempts/10)
+}
+
+func TestWithMaxDropRatioInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		WithMaxDropRatio(0)
+	})
+	assert.Panics(t, func() {
+		WithMaxDropRatio(1.5)
+	})
+	assert.NotPanics(t, func() {
+		NewBreaker(WithMaxDropRatio(0.9))
+	})
+}
+
+func TestGoogleBreakerMarkFailureOnReject(t *testing.T) {
+	tests := []struct {
+		name string
+		mark bool
+	}{
+		{"default", true},
+		{"disabled", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := newTestGoogleBreaker(func(s *googleSettings) {
+				s.markFailureOnReject = test.mark
+			})
+			errDown := errors.New("down")
+			for i := 0; i < 100; i++ {
+				_ = b.doReq(func() error {
+					return errDown
+				}, nil, defaultAcceptable, nil)
+			}
+
+			var rejected int
+			for i := 0; i < 100; i++ {
+				_, before := b.history()
+				_, err := b.allow()
+				_, after := b.history()
+				if err == nil {
+					continue
+				}
+
+				rejected++
+				assert.ErrorIs(t, err, ErrServiceUnavailable)
+				if test.mark {
+					assert.Equal(t, before+1, after)
+				} else {
+					assert.Equal(t, before, after)
+				}
+			}
+			assert.Greater(t, rejected, 0)
+		})
+	}
+}
+
+func TestGoogleBreakerPanic(t *testing.T) {
+	b := newTestGoogleBreaker()
+	assert.Panics(t, func() {
+		_ = b.doReq(func() error {
+			panic("boom")
+		}, nil, defaultAcceptable, nil)
+	})
+	accepts, total := b.history()
+	assert.Equal(t, int64(0), accepts)
+	assert.Equal(t, int64(1), total)
+}
+
+func TestGoogleBreakerPanicAsError(t *testing.T) {
+	b := newTestGoogleBreaker(func(s *googleSettings) {
+		s.panicAsError = true
+	})
+	var err error
+	assert.NotPanics(t, func() {
+		err = b.doReq(func() error {
+			panic("boom")
+		}, nil, defaultAcceptable, nil)
+	})
+	assert.EqualError(t, err, "breaker: panic recovered: boom")
+	accepts, total := b.history()
+	assert.Equal(t, int64(0), accepts)
+	assert.Equal(t, int64(1), total)
+
+	// 未panic时行为不变
+	assert.Nil(t, b.doReq(func() error {
+		return nil
+	}, nil, defaultAcceptable, nil))
+	accepts, total = b.history()
+	assert.Equal(t, int64(1), accepts)
+	assert.Equal(t, int64(2), total)
+}
+
+func TestGoogleBreakerCooldown(t *testing.T) {
+	const cooldown = time.Second * 3
+	clock := timex.NewMockClock(0)
+	b := newTestGoogleBreakerWithClock(clock, func(s *googleSettings) {
+		s.cooldown = cooldown
+		s.maxDropRatio = 0.9
+	})
+	errDown := errors.New("down")
+	req := func() error {
+		return errDown
+	}
+
+	// 持续失败直到开始冷却
+	for i := 0; i < 100 && !b.inCooldown(); i++ {
+		_ = b.doReq(req, nil, defaultAcceptable, nil)
+	}
+	assert.True(t, b.inCooldown())
+
+	var admitted int
+	for i := 0; i < 100; i++ {
+		clock.Advance(cooldown / 200)
+		_ = b.doReq(func() error {
+			admitted++
+			return errDown
+		}, nil, defaultAcceptable, nil)
+	}
+	assert.Equal(t, 0, admitted)
+
+	// 冷却结束之后恢复概率放行, 不会因为丢弃比例仍然很高而再次冷却
+	clock.Advance(cooldown)
+	for i := 0; i < 100; i++ {
+		_ = b.doReq(func() error {
+			admitted++
+			return errDown
+		}, nil, defaultAcceptable, nil)
+	}
+	assert.False(t, b.inCooldown())
+	assert.GreaterOrEqual(t, admitted, 10)
+}
+
+func TestGoogleBreakerCooldownReset(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	b := newTestGoogleBreakerWithClock(clock, func(s *googleSettings) {
+		s.cooldown = time.Minute
+	})
+	errDown := errors.New("down")
+	for i := 0; i < 100 && !b.inCooldown(); i++ {
+		_ = b.doReq(func() error {
+			return errDown
+		}, nil, defaultAcceptable, nil)
+	}
+	assert.True(t, b.inCooldown())
+
+	// 冷却结束且下游恢复后(整个窗口滑过), 本次熔断结束
+	clock.Advance(time.Minute)
+	assert.Nil(t, b.accept())
+	assert.Equal(t, int64(notOpened), b.openedAt)
+}
+
+// 总是按丢弃比例拒绝, 模拟完全打开的熔断器
+type alwaysProba struct{}
+
+func (alwaysProba) TrueOnProba(float64) bool {
+	return true
+}
+
+func TestGoogleBreakerMaxOpenDuration(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	b := newTestGoogleBreakerWithClock(clock, func(s *googleSettings) {
+		s.maxOpenDuration = time.Second
+	})
+	b.proba = alwaysProba{}
+	errDown := errors.New("down")
+	var executed int
+	req := func() error {
+		executed++
+		return errDown
+	}
+	for i := 0; i < 100; i++ {
+		b.markFailure()
+	}
+
+	// 连续拒绝不超过d时不会放行
+	for i := 0; i < 10; i++ {
+		assert.ErrorIs(t, b.doReq(req, nil, defaultAcceptable, nil), ErrServiceUnavailable)
+		clock.Advance(time.Millisecond * 99)
+	}
+	assert.Equal(t, 0, executed)
+
+	// 超过d之后放行一个探测请求, 之后重新计时
+	clock.Advance(time.Millisecond * 10)
+	assert.Equal(t, errDown, b.doReq(req, nil, defaultAcceptable, nil))
+	assert.Equal(t, 1, executed)
+	assert.ErrorIs(t, b.doReq(req, nil, defaultAcceptable, nil), ErrServiceUnavailable)
+	clock.Advance(time.Millisecond * 999)
+	assert.ErrorIs(t, b.doReq(req, nil, defaultAcceptable, nil), ErrServiceUnavailable)
+	clock.Advance(time.Millisecond)
+	assert.Equal(t, errDown, b.doReq(req, nil, defaultAcceptable, nil))
+	assert.Equal(t, 2, executed)
+
+	// 保持打开足够久, 大约每秒探测一次
+	for i := 0; i < 100; i++ {
+		clock.Advance(time.Millisecond * 100)
+		_ = b.doReq(req, nil, defaultAcceptable, nil)
+	}
+	assert.InDelta(t, 11, executed, 1)
+}
+
+func TestGoogleBreakerMaxOpenDurationNotForced(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	b := newTestGoogleBreakerWithClock(clock, func(s *googleSettings) {
+		s.maxOpenDuration = time.Second
+	})
+	b.force(ForcedOpen)
+	for i := 0; i < 10; i++ {
+		assert.ErrorIs(t, b.doReq(func() error {
+			t.Fatal("forced open breaker should not probe")
+			return nil
+		}, nil, defaultAcceptable, nil), ErrServiceUnavailable)
+		clock.Advance(time.Second)
+	}
+}
+
+func TestGoogleBreakerWithoutMaxOpenDuration(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	b := newTestGoogleBreakerWithClock(clock)
+	b.proba = alwaysProba{}
+	for i := 0; i < 100; i++ {
+		b.markFailure()
+	}
+	for i := 0; i < 100; i++ {
+		assert.ErrorIs(t, b.doReq(func() error {
+			t.Fatal("request should not be executed")
+			return nil
+		}, nil, defaultAcceptable, nil), ErrServiceUnavailable)
+		clock.Advance(time.Millisecond * 100)
+	}
+}
+
+func TestWithMaxOpenDuration(t *testing.T) {
+	assert.Panics(t, func() {
+		WithMaxOpenDuration(-time.Second)
+	})
+
+	clock := timex.NewMockClock(0)
+	b := NewBreaker(WithClock(clock), WithMaxOpenDuration(time.Second))
+	gb := b.(*circuitBreaker).throttle.(loggedThrottle).internalThrottle.(*googleBreaker)
+	assert.Equal(t, time.Second, gb.maxOpenDuration)
+	gb.proba = alwaysProba{}
+
+	errDown := errors.New("down")
+	for i := 0; i < 100; i++ {
+		_ = b.Do(func() error {
+			return errDown
+		})
+	}
+	// 下游已经恢复, 熔断器靠探测请求逐步恢复
+	var executed int
+	for i := 0; i < 200 && executed == 0; i++ {
+		clock.Advance(time.Millisecond * 100)
+		_ = b.Do(func() error {
+			executed++
+			return nil
+		})
+	}
+	assert.Equal(t, 1, executed)
+	assert.Equal(t, int64(1), b.Stats().Accepts)
+}
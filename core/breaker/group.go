@@ -0,0 +1,100 @@
+package breaker
+
+import (
+	"io"
+	"sync"
+)
+
+// BreakerGroup 管理一组相关的熔断器, 例如一个服务依赖的所有下游, 每个下游一个熔断器
+// 组内的熔断器注册在进程级注册表中, 与 NewSharedBreaker 创建的同名熔断器是同一个
+// Close 只从注册表中移除由本组创建的熔断器, 其它地方先创建的同名熔断器保持不变
+type BreakerGroup struct {
+	opts     []Option
+	lock     sync.Mutex
+	breakers map[string]Breaker
+	// 由本组创建并注册的熔断器, Close 时需要从注册表中移除
+	owned map[string]bool
+}
+
+// NewBreakerGroup 创建熔断器组, defaultOpts作用于组内所有的熔断器
+func NewBreakerGroup(defaultOpts ...Option) *BreakerGroup {
+	return &BreakerGroup{
+		opts:     defaultOpts,
+		breakers: make(map[string]Breaker),
+		owned:    make(map[string]bool),
+	}
+}
+
+// Get 返回名为name的熔断器, 不存在时创建, opts只在创建时生效并覆盖组的默认配置
+// 注册表中已经有同名的熔断器时直接使用, 配置以已有的熔断器为准
+func (g *BreakerGroup) Get(name string, opts ...Option) Breaker {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if b, ok := g.breakers[name]; ok {
+		return b
+	}
+
+	all := make([]Option, 0, len(g.opts)+len(opts)+1)
+	all = append(all, g.opts...)
+	all = append(all, opts...)
+	// 名字以Get的参数为准
+	all = append(all, WithName(name))
+	b, created := registerSharedBreaker(all...)
+	g.breakers[name] = b
+	if created {
+		g.owned[name] = true
+	}
+	return b
+}
+
+// All 返回组内所有的熔断器, 返回的是拷贝
+func (g *BreakerGroup) All() map[string]Breaker {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	breakers := make(map[string]Breaker, len(g.breakers))
+	for name, b := range g.breakers {
+		breakers[name] = b
+	}
+	return breakers
+}
+
+// Stats 返回组内每个熔断器的统计数据
+func (g *BreakerGroup) Stats() map[string]BreakerStats {
+	stats := make(map[string]BreakerStats)
+	for name, b := range g.All() {
+		stats[name] = b.Stats()
+	}
+	return stats
+}
+
+// Reset 清空组内所有熔断器的滑动窗口, 冷却状态以及失败原因, 强制模式保持不变
+func (g *BreakerGroup) Reset() {
+	for _, b := range g.All() {
+		if cb, ok := b.(*circuitBreaker); ok {
+			cb.throttle.reset()
+		}
+	}
+}
+
+// Close 从注册表中移除由本组创建的熔断器并停止它们的定期上报, 然后清空组, 之后再 Get 会重新创建
+func (g *BreakerGroup) Close() error {
+	g.lock.Lock()
+	breakers, owned := g.breakers, g.owned
+	g.breakers = make(map[string]Breaker)
+	g.owned = make(map[string]bool)
+	g.lock.Unlock()
+
+	for name, b := range breakers {
+		if !owned[name] {
+			continue
+		}
+
+		unregisterSharedBreaker(name, b)
+		if closer, ok := b.(io.Closer); ok {
+			_ = closer.Close()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package breaker
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBreakerGroup(t *testing.T) {
+	g := NewBreakerGroup(WithK(2))
+	defer g.Close()
+
+	user := g.Get("group-user-rpc")
+	assert.Same(t, user, g.Get("group-user-rpc"))
+	assert.Equal(t, "group-user-rpc", user.Name())
+	// Get的配置覆盖组的默认配置, 名字以Get的参数为准
+	order := g.Get("group-order-rpc", WithK(3), WithName("ignored"))
+	assert.NotSame(t, user, order)
+	assert.Equal(t, "group-order-rpc", order.Name())
+	assert.Equal(t, 2.0, user.Stats().K)
+	assert.Equal(t, 3.0, order.Stats().K)
+	// 注册在进程级注册表中
+	assert.Same(t, user, NewSharedBreaker(WithName("group-user-rpc"), WithK(2)))
+
+	assert.Nil(t, user.Do(func() error {
+		return nil
+	}))
+	stats := g.Stats()
+	assert.Len(t, stats, 2)
+	assert.Equal(t, int64(1), stats["group-user-rpc"].Total)
+	assert.Equal(t, "group-user-rpc", stats["group-user-rpc"].Name)
+	assert.Equal(t, int64(0), stats["group-order-rpc"].Total)
+
+	all := g.All()
+	assert.Len(t, all, 2)
+	assert.Same(t, order, all["group-order-rpc"])
+	// 返回的是拷贝
+	delete(all, "group-order-rpc")
+	assert.Len(t, g.All(), 2)
+}
+
+func TestBreakerGroupReset(t *testing.T) {
+	g := NewBreakerGroup()
+	defer g.Close()
+
+	b := g.Get("group-reset-rpc")
+	_ = b.Do(func() error {
+		return errors.New("timeout")
+	})
+	b.ForceOpen()
+	assert.Equal(t, int64(1), b.Stats().Total)
+	assert.Len(t, b.LastErrors(), 1)
+
+	g.Reset()
+	assert.Equal(t, int64(0), b.Stats().Total)
+	assert.Empty(t, b.LastErrors())
+	// 强制模式保持不变
+	assert.Equal(t, ForcedOpen, b.Stats().Forced)
+}
+
+func TestBreakerGroupClose(t *testing.T) {
+	const name = "group-close-rpc"
+	g := NewBreakerGroup()
+	b := g.Get(name)
+	assert.Same(t, b, NewSharedBreaker(WithName(name)))
+	assert.Nil(t, g.Close())
+	assert.Empty(t, g.All())
+	// 已经从注册表中移除
+	assert.NotSame(t, b, NewSharedBreaker(WithName(name)))
+	// 之后再 Get 拿到的是注册表中新的熔断器
+	assert.NotSame(t, b, g.Get(name))
+	assert.Nil(t, g.Close())
+}
+
+func TestBreakerGroupsSharingName(t *testing.T) {
+	const name = "group-shared-name-rpc"
+	shared := NewSharedBreaker(WithName(name))
+	first := NewBreakerGroup()
+	second := NewBreakerGroup()
+	defer second.Close()
+
+	// 注册表中已有的同名熔断器直接使用
+	a := first.Get(name)
+	b := second.Get(name)
+	assert.Same(t, shared, a)
+	assert.Same(t, shared, b)
+
+	// 不是本组创建的熔断器, Close 时保留在注册表中
+	assert.Nil(t, first.Close())
+	assert.Same(t, shared, NewSharedBreaker(WithName(name)))
+	assert.Same(t, shared, second.Get(name))
+	assert.Nil(t, shared.Do(func() error {
+		return nil
+	}))
+	assert.Equal(t, int64(1), shared.Stats().Total)
+}
+
+func TestBreakerGroupsOwnership(t *testing.T) {
+	const name = "group-ownership-rpc"
+	first := NewBreakerGroup()
+	second := NewBreakerGroup()
+	defer second.Close()
+
+	a := first.Get(name)
+	assert.Same(t, a, second.Get(name))
+
+	// 创建者 Close 之后从注册表中移除, 另一个组仍然持有原来的熔断器
+	assert.Nil(t, first.Close())
+	assert.Same(t, a, second.Get(name))
+	c := NewSharedBreaker(WithName(name))
+	assert.NotSame(t, a, c)
+	// 另一个组 Close 时不会误删之后同名注册的熔断器
+	assert.Nil(t, second.Close())
+	assert.Same(t, c, NewSharedBreaker(WithName(name)))
+}
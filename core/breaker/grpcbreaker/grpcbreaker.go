@@ -0,0 +1,80 @@
+package grpcbreaker
+
+import (
+	"context"
+	"errors"
+	"go-zero-/core/breaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// gRPC客户端的熔断拦截器
+// 只有表示服务端异常或者过载的状态码记为失败, 其余的状态码(例如参数错误、找不到数据)说明服务端正常处理了请求, 记为成功
+// 熔断器拒绝时不发起调用, 返回的错误对应 codes.Unavailable 状态码, 与服务端不可用时一样可以被gRPC的重试策略识别
+// 同时保留熔断器的错误, 可以通过 errors.Is(err, breaker.ErrServiceUnavailable) 判断, 或者用 errors.As 取出 *breaker.BreakerError
+
+// NewUnaryClientInterceptor 返回经过熔断器b发起一元调用的拦截器
+func NewUnaryClientInterceptor(b breaker.Breaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		var called bool
+		err := b.DoWithAcceptableCtx(ctx, func() error {
+			called = true
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}, Acceptable)
+		return wrapRejected(err, called)
+	}
+}
+
+// NewStreamClientInterceptor 返回经过熔断器b建立流的拦截器, 只统计建立流的结果, 不统计流中每条消息的收发
+func NewStreamClientInterceptor(b breaker.Breaker) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string,
+		streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		var stream grpc.ClientStream
+		var called bool
+		err := b.DoWithAcceptableCtx(ctx, func() error {
+			var err error
+			called = true
+			stream, err = streamer(ctx, desc, cc, method, opts...)
+			return err
+		}, Acceptable)
+		return stream, wrapRejected(err, called)
+	}
+}
+
+// Acceptable 判定gRPC调用的结果, Unavailable、DeadlineExceeded、Internal、ResourceExhausted 记为失败
+func Acceptable(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.Internal, codes.ResourceExhausted:
+		return false
+	default:
+		return true
+	}
+}
+
+// 熔断器拒绝的请求, 通过 GRPCStatus 让 status.Code 返回 codes.Unavailable
+type rejectedError struct {
+	err error
+}
+
+func (e rejectedError) Error() string {
+	return e.err.Error()
+}
+
+func (e rejectedError) Unwrap() error {
+	return e.err
+}
+
+func (e rejectedError) GRPCStatus() *status.Status {
+	return status.New(codes.Unavailable, e.err.Error())
+}
+
+// 只包装熔断器拒绝的错误, 调用本身返回的错误原样返回
+func wrapRejected(err error, called bool) error {
+	if called || !errors.Is(err, breaker.ErrServiceUnavailable) {
+		return err
+	}
+
+	return rejectedError{err: err}
+}
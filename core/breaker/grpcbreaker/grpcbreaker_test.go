@@ -0,0 +1,218 @@
+package grpcbreaker
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/breaker"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"sync"
+	"testing"
+)
+
+// 按预设的状态码依次返回的健康检查服务, 用完之后返回OK
+type scriptedServer struct {
+	grpc_health_v1.UnimplementedHealthServer
+	lock  sync.Mutex
+	codes []codes.Code
+	calls int
+}
+
+func (s *scriptedServer) Check(context.Context, *grpc_health_v1.HealthCheckRequest) (
+	*grpc_health_v1.HealthCheckResponse, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.calls++
+	if len(s.codes) > 0 {
+		code := s.codes[0]
+		s.codes = s.codes[1:]
+		if code != codes.OK {
+			return nil, status.Error(code, code.String())
+		}
+	}
+	return &grpc_health_v1.HealthCheckResponse{
+		Status: grpc_health_v1.HealthCheckResponse_SERVING,
+	}, nil
+}
+
+func (s *scriptedServer) Watch(_ *grpc_health_v1.HealthCheckRequest, stream grpc_health_v1.Health_WatchServer) error {
+	return stream.Send(&grpc_health_v1.HealthCheckResponse{
+		Status: grpc_health_v1.HealthCheckResponse_SERVING,
+	})
+}
+
+func (s *scriptedServer) callCount() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.calls
+}
+
+// 启动基于bufconn的服务, 返回经过熔断器b调用的客户端
+func newTestClient(t *testing.T, b breaker.Breaker, server *scriptedServer) grpc_health_v1.HealthClient {
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, server)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(NewUnaryClientInterceptor(b)),
+		grpc.WithStreamInterceptor(NewStreamClientInterceptor(b)))
+	assert.Nil(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func TestUnaryClientInterceptorTrip(t *testing.T) {
+	const failures = 200
+	server := &scriptedServer{
+		codes: make([]codes.Code, failures),
+	}
+	for i := range server.codes {
+		server.codes[i] = codes.Unavailable
+	}
+	b := breaker.NewBreaker()
+	client := newTestClient(t, b, server)
+
+	// 连续的Unavailable触发熔断, 被拒绝的调用不会发到服务端
+	var rejected int
+	for i := 0; i < failures; i++ {
+		_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+		assert.Error(t, err)
+		// 被拒绝时同样返回 Unavailable
+		assert.Equal(t, codes.Unavailable, status.Code(err))
+		if errors.Is(err, breaker.ErrServiceUnavailable) {
+			rejected++
+		}
+	}
+	assert.Greater(t, rejected, failures/2)
+	assert.Equal(t, failures-rejected, server.callCount())
+	st := b.Stats()
+	assert.Equal(t, int64(0), st.Accepts)
+	assert.Equal(t, int64(failures), st.Total)
+}
+
+func TestUnaryClientInterceptorRejected(t *testing.T) {
+	b := breaker.NewBreaker(breaker.WithName("user-rpc"))
+	b.ForceOpen()
+	server := new(scriptedServer)
+	client := newTestClient(t, b, server)
+
+	_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.ErrorIs(t, err, breaker.ErrServiceUnavailable)
+	var be *breaker.BreakerError
+	assert.True(t, errors.As(err, &be))
+	assert.Equal(t, "user-rpc", be.Name)
+	assert.Equal(t, 0, server.callCount())
+
+	// 下游返回的错误原样返回, 即使其中包含熔断器的错误
+	interceptor := NewUnaryClientInterceptor(breaker.NewBreaker())
+	err = interceptor(context.Background(), "/test.Service/Check", nil, nil, nil,
+		func(context.Context, string, any, any, *grpc.ClientConn, ...grpc.CallOption) error {
+			return breaker.ErrServiceUnavailable
+		})
+	assert.Equal(t, breaker.ErrServiceUnavailable, err)
+}
+
+func TestUnaryClientInterceptorCodes(t *testing.T) {
+	tests := []struct {
+		code    codes.Code
+		failure bool
+	}{
+		{codes.OK, false},
+		{codes.NotFound, false},
+		{codes.InvalidArgument, false},
+		{codes.PermissionDenied, false},
+		{codes.Unavailable, true},
+		{codes.DeadlineExceeded, true},
+		{codes.Internal, true},
+		{codes.ResourceExhausted, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.code.String(), func(t *testing.T) {
+			b := breaker.NewBreaker()
+			client := newTestClient(t, b, &scriptedServer{
+				codes: []codes.Code{test.code},
+			})
+			_, err := client.Check(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+			assert.Equal(t, test.code, status.Code(err))
+
+			st := b.Stats()
+			assert.Equal(t, int64(1), st.Total)
+			if test.failure {
+				assert.Equal(t, int64(0), st.Accepts)
+			} else {
+				assert.Equal(t, int64(1), st.Accepts)
+			}
+		})
+	}
+}
+
+func TestUnaryClientInterceptorCanceled(t *testing.T) {
+	b := breaker.NewBreaker()
+	server := new(scriptedServer)
+	client := newTestClient(t, b, server)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	assert.Equal(t, context.Canceled, err)
+	// 调用方已经取消的请求不经过熔断器
+	assert.Equal(t, int64(0), b.Stats().Total)
+	assert.Equal(t, 0, server.callCount())
+}
+
+func TestStreamClientInterceptor(t *testing.T) {
+	b := breaker.NewBreaker()
+	client := newTestClient(t, b, new(scriptedServer))
+
+	stream, err := client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	assert.Nil(t, err)
+	resp, err := stream.Recv()
+	assert.Nil(t, err)
+	assert.Equal(t, grpc_health_v1.HealthCheckResponse_SERVING, resp.Status)
+	assert.Equal(t, int64(1), b.Stats().Accepts)
+
+	// 熔断时不建立流
+	b.ForceOpen()
+	_, err = client.Watch(context.Background(), &grpc_health_v1.HealthCheckRequest{})
+	assert.ErrorIs(t, err, breaker.ErrServiceUnavailable)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	b.ClearForce()
+
+	// 建立流失败时记为失败
+	interceptor := NewStreamClientInterceptor(b)
+	_, err = interceptor(context.Background(), &grpc.StreamDesc{}, nil, "/test.Service/Watch",
+		func(context.Context, *grpc.StreamDesc, *grpc.ClientConn, string, ...grpc.CallOption) (grpc.ClientStream, error) {
+			return nil, status.Error(codes.Unavailable, "connection refused")
+		})
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	st := b.Stats()
+	assert.Equal(t, int64(1), st.Accepts)
+	assert.Equal(t, int64(3), st.Total)
+}
+
+func TestAcceptable(t *testing.T) {
+	assert.True(t, Acceptable(nil))
+	assert.True(t, Acceptable(status.Error(codes.NotFound, "not found")))
+	assert.False(t, Acceptable(status.Error(codes.Unavailable, "unavailable")))
+	// 非gRPC的错误状态码为Unknown, 记为成功
+	assert.True(t, Acceptable(errors.New("unknown")))
+}
@@ -0,0 +1,177 @@
+package breaker
+
+import (
+	"sync"
+	"time"
+)
+
+// 层级熔断器, 例如服务 user-rpc 作为父熔断器, 每个接口 user-rpc/GetUser 作为子熔断器
+// 子熔断器只有自己和父熔断器都放行时才放行请求, 执行结果同时记录到两个滑动窗口
+// 单个接口异常只会触发该接口自己的熔断, 整个服务异常时父熔断器熔断, 所有接口一起被拒绝
+
+type (
+	// Hierarchy 父熔断器, 本身可以作为一个普通的熔断器使用
+	Hierarchy struct {
+		*circuitBreaker
+		parent *googleBreaker
+		// 父熔断器的错误记录, 子熔断器的错误带上子熔断器的名字
+		errWin   *errorWindow
+		reporter *dropReporter
+		opts     []Option
+		lock     sync.Mutex
+		children map[string]Breaker
+	}
+
+	childThrottle struct {
+		// 完整的名字, 父熔断器名字/子熔断器名字
+		name string
+		// 子熔断器自己的名字, 用于父熔断器的错误记录
+		shortName string
+		child     *googleBreaker
+		errWin    *errorWindow
+		reporter  *dropReporter
+		h         *Hierarchy
+	}
+
+	childPromise struct {
+		t *childThrottle
+	}
+)
+
+// NewHierarchy 创建名为parentName的父熔断器, opts同时作用于父熔断器和所有的子熔断器
+func NewHierarchy(parentName string, opts ...Option) *Hierarchy {
+	b := newCircuitBreaker(opts...)
+	b.name = parentName
+	errWin := new(errorWindow)
+	parent := b.newGoogleBreaker(parentName, errWin)
+	lt := newLoggedThrottle(b, parent, errWin)
+	b.throttle = lt
+	b.startSummary(parent)
+
+	return &Hierarchy{
+		circuitBreaker: b,
+		parent:         parent,
+		errWin:         errWin,
+		reporter:       lt.reporter,
+		opts:           opts,
+		children:       make(map[string]Breaker),
+	}
+}
+
+// Child 返回名为name的子熔断器, 同名的子熔断器只会创建一次
+// 子熔断器的名字为 父熔断器名字/name, 强制模式只作用于子熔断器自己, 父熔断器强制熔断时所有子熔断器都会拒绝请求
+func (h *Hierarchy) Child(name string) Breaker {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if child, ok := h.children[name]; ok {
+		return child
+	}
+
+	b := newCircuitBreaker(h.opts...)
+	b.name = h.name + "/" + name
+	errWin := new(errorWindow)
+	child := b.newGoogleBreaker(b.name, errWin)
+	b.throttle = &childThrottle{
+		name:      b.name,
+		shortName: name,
+		child:     child,
+		errWin:    errWin,
+		reporter:  b.newDropReporter(errWin),
+		h:         h,
+	}
+	b.startSummary(child)
+	h.children[name] = b
+	return b
+}
+
+func (t *childThrottle) allow() (Promise, error) {
+	if err := t.accept(); err != nil {
+		return nil, err
+	}
+
+	return PromiseWithReason{
+		promise:  childPromise{t: t},
+		recorder: t,
+	}, nil
+}
+
+func (t *childThrottle) doReq(req func() error, fallback Fallback, classifier resultClassifier) error {
+	if err := t.accept(); err != nil {
+		t.child.observer.observeRejected(err)
+		if fallback != nil {
+			return doFallback(fallback, err)
+		}
+
+		return err
+	}
+
+	return t.child.execute(req, classifier, t, t)
+}
+
+// 子熔断器只作用于自己, 不影响父熔断器和其它子熔断器
+func (t *childThrottle) force(mode ForceMode) {
+	t.child.force(mode)
+}
+
+func (t *childThrottle) stats() Stats {
+	return t.child.stats()
+}
+
+// 只清空子熔断器自己
+func (t *childThrottle) reset() {
+	t.child.reset()
+	t.errWin.reset()
+}
+
+func (t *childThrottle) lastErrors() []ErrorRecord {
+	return t.errWin.Export()
+}
+
+// 只统计子熔断器自己, 不包括父熔断器熔断的时间
+func (t *childThrottle) openDuration(window time.Duration) time.Duration {
+	return t.child.openDuration(window)
+}
+
+// 先由子熔断器判定, 再由父熔断器判定
+// 拒绝只记录在做出拒绝的熔断器上, 被子熔断器拒绝的请求不会计入父熔断器, 避免一个异常的接口拖垮整个服务
+// 被父熔断器拒绝的请求也不会计入子熔断器, 避免服务恢复之后子熔断器还要再恢复一次
+func (t *childThrottle) accept() error {
+	if err := t.child.accept(); err != nil {
+		t.child.markRejected()
+		t.reporter.report()
+		return err
+	}
+
+	if err := t.h.parent.accept(); err != nil {
+		t.h.parent.markRejected()
+		t.h.reporter.report()
+		return err
+	}
+
+	return nil
+}
+
+// 错误同时记录到父熔断器, 父熔断器的错误记录带上子熔断器的名字, 方便定位是哪些接口出错
+func (t *childThrottle) add(reason string) {
+	t.errWin.add(reason)
+	t.h.errWin.add(t.shortName + ": " + reason)
+}
+
+func (t *childThrottle) markSuccess() {
+	t.child.markSuccess()
+	t.h.parent.markSuccess()
+}
+
+func (t *childThrottle) markFailure() {
+	t.child.markFailure()
+	t.h.parent.markFailure()
+}
+
+func (p childPromise) Accept() {
+	p.t.markSuccess()
+}
+
+func (p childPromise) Reject() {
+	p.t.markFailure()
+}
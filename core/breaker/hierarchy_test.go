@@ -0,0 +1,155 @@
+package breaker
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/stat"
+	"strings"
+	"testing"
+)
+
+func newTestHierarchy(name string, opts ...Option) *Hierarchy {
+	h := NewHierarchy(name, opts...)
+	h.parent.proba = new(stepProba)
+	return h
+}
+
+func testChild(h *Hierarchy, name string) (Breaker, *childThrottle) {
+	b := h.Child(name)
+	t := b.(*circuitBreaker).throttle.(*childThrottle)
+	t.child.proba = new(stepProba)
+	return b, t
+}
+
+func TestHierarchyChild(t *testing.T) {
+	h := newTestHierarchy("user-rpc")
+	b, _ := testChild(h, "GetUser")
+	assert.Equal(t, "user-rpc", h.Name())
+	assert.Equal(t, "user-rpc/GetUser", b.Name())
+	assert.Same(t, b, h.Child("GetUser"))
+	assert.NotSame(t, b, h.Child("ListUsers"))
+}
+
+func TestHierarchyOneBadChild(t *testing.T) {
+	h := newTestHierarchy("user-rpc")
+	bad, bt := testChild(h, "GetUser")
+	good, _ := testChild(h, "ListUsers")
+	errDown := errors.New("down")
+
+	var badExecuted, badRejected, goodRejected int
+	for i := 0; i < 100; i++ {
+		if err := bad.Do(func() error {
+			badExecuted++
+			return errDown
+		}); errors.Is(err, ErrServiceUnavailable) {
+			badRejected++
+		}
+		for j := 0; j < 10; j++ {
+			if err := good.Do(func() error {
+				return nil
+			}); err != nil {
+				goodRejected++
+			}
+		}
+	}
+
+	// 异常的接口被自己的熔断器拒绝, 正常的接口几乎不受影响
+	assert.Greater(t, badRejected, 70)
+	assert.Less(t, goodRejected, 100)
+	// 被子熔断器拒绝的请求不计入父熔断器
+	childRejected := bt.child.stats().Total - int64(badExecuted)
+	assert.Greater(t, childRejected, int64(0))
+	assert.Equal(t, int64(1000+badExecuted+badRejected)-childRejected, h.Stats().Total)
+}
+
+func TestHierarchyServiceOutage(t *testing.T) {
+	h := newTestHierarchy("user-rpc")
+	errDown := errors.New("down")
+	var children []Breaker
+	for _, name := range []string{"GetUser", "ListUsers", "UpdateUser", "DeleteUser"} {
+		b, _ := testChild(h, name)
+		children = append(children, b)
+	}
+	for i := 0; i < 100; i++ {
+		for _, b := range children {
+			_ = b.Do(func() error {
+				return errDown
+			})
+		}
+	}
+
+	// 整个服务不可用时父熔断器熔断, 之前没有出错的接口也会被拒绝
+	fresh, ft := testChild(h, "CreateUser")
+	var rejected int
+	for i := 0; i < 10; i++ {
+		if err := fresh.Do(func() error {
+			return errDown
+		}); errors.Is(err, ErrServiceUnavailable) {
+			rejected++
+		}
+	}
+	assert.GreaterOrEqual(t, rejected, 8)
+	// 被父熔断器拒绝的请求不计入子熔断器
+	assert.Equal(t, int64(10-rejected), ft.child.stats().Total)
+}
+
+func TestHierarchyParentReport(t *testing.T) {
+	var msgs []string
+	stat.SetReporter(func(level stat.Level, msg string) {
+		msgs = append(msgs, msg)
+	})
+	defer stat.SetReporter(nil)
+
+	h := newTestHierarchy("report-hierarchy-rpc")
+	get, _ := testChild(h, "GetUser")
+	list, _ := testChild(h, "ListUsers")
+	_ = get.Do(func() error {
+		return errors.New("get failed")
+	})
+	_ = list.Do(func() error {
+		return errors.New("list failed")
+	})
+
+	h.ForceOpen()
+	assert.ErrorIs(t, get.Do(func() error {
+		return nil
+	}), ErrServiceUnavailable)
+	assert.Len(t, msgs, 1)
+	assert.True(t, strings.Contains(msgs[0], "callee: report-hierarchy-rpc,"))
+	assert.True(t, strings.Contains(msgs[0], "GetUser: get failed"))
+	assert.True(t, strings.Contains(msgs[0], "ListUsers: list failed"))
+}
+
+func TestHierarchyChildForce(t *testing.T) {
+	h := newTestHierarchy("force-rpc")
+	a, _ := testChild(h, "A")
+	b, _ := testChild(h, "B")
+
+	a.ForceOpen()
+	assert.ErrorIs(t, a.Do(func() error {
+		return nil
+	}), ErrServiceUnavailable)
+	assert.Nil(t, b.Do(func() error {
+		return nil
+	}))
+	assert.Equal(t, ForcedNone, h.Stats().Forced)
+}
+
+func TestHierarchyAllow(t *testing.T) {
+	h := newTestHierarchy("allow-rpc")
+	b, ct := testChild(h, "GetUser")
+
+	p, err := b.Allow()
+	assert.Nil(t, err)
+	p.Accept()
+	p, err = b.Allow()
+	assert.Nil(t, err)
+	p.Reject("bad response")
+
+	assert.Equal(t, int64(1), ct.child.stats().Accepts)
+	assert.Equal(t, int64(2), ct.child.stats().Total)
+	assert.Equal(t, int64(1), h.Stats().Accepts)
+	assert.Equal(t, int64(2), h.Stats().Total)
+	assert.Equal(t, "GetUser: bad response", h.errWin.Export()[0].Reason)
+	assert.Equal(t, "bad response", ct.errWin.Export()[0].Reason)
+}
@@ -0,0 +1,84 @@
+package breaker
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type (
+	// HTTPOption RoundTripper 的配置
+	HTTPOption func(rt *roundTripper)
+
+	roundTripper struct {
+		breaker Breaker
+		base    http.RoundTripper
+		// 判定一次请求是否失败, resp和err与 http.RoundTripper 的返回值一致
+		isFailure func(resp *http.Response, err error) bool
+	}
+)
+
+// NewRoundTripper 返回经过熔断器b发送请求的 http.RoundTripper, base为nil时使用 http.DefaultTransport
+// 默认网络错误(连接失败、超时等)以及状态码>=500的响应记为失败, 其余的响应记为成功
+// 熔断器拒绝时不发送请求, 直接返回熔断器的错误, 请求的context已经结束时同样不经过熔断器
+func NewRoundTripper(b Breaker, base http.RoundTripper, opts ...HTTPOption) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	rt := &roundTripper{
+		breaker:   b,
+		base:      base,
+		isFailure: isHTTPFailure,
+	}
+	for _, opt := range opts {
+		opt(rt)
+	}
+	return rt
+}
+
+// WrapHTTPClient 返回使用 NewRoundTripper 包装了Transport的新client, 不修改原来的client
+// client为nil时基于零值的 http.Client 创建
+func WrapHTTPClient(b Breaker, client *http.Client, opts ...HTTPOption) *http.Client {
+	var wrapped http.Client
+	if client != nil {
+		wrapped = *client
+	}
+	wrapped.Transport = NewRoundTripper(b, wrapped.Transport, opts...)
+	return &wrapped
+}
+
+// WithHTTPFailureClassifier 自定义判定一次请求是否失败, 例如把429也记为失败
+func WithHTTPFailureClassifier(fn func(resp *http.Response, err error) bool) HTTPOption {
+	if fn == nil {
+		panic("http failure classifier must not be nil")
+	}
+	return func(rt *roundTripper) {
+		rt.isFailure = fn
+	}
+}
+
+func (rt *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	promise, err := rt.breaker.AllowCtx(req.Context())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := rt.base.RoundTrip(req)
+	if rt.isFailure(resp, err) {
+		promise.Reject(httpFailureReason(req, resp, err))
+	} else {
+		promise.Accept()
+	}
+	return resp, err
+}
+
+func isHTTPFailure(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+func httpFailureReason(req *http.Request, resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("%s %s: %s", req.Method, req.URL.Path, resp.Status)
+}
@@ -0,0 +1,191 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/timex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 返回预设状态码的测试服务, 统计实际收到的请求数
+type statusServer struct {
+	*httptest.Server
+	status int32
+	hits   int32
+}
+
+func newStatusServer(t *testing.T, status int) *statusServer {
+	s := &statusServer{
+		status: int32(status),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&s.hits, 1)
+		w.WriteHeader(int(atomic.LoadInt32(&s.status)))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *statusServer) get(t *testing.T, client *http.Client) (int, error) {
+	resp, err := client.Get(s.URL + "/users")
+	if err != nil {
+		return 0, err
+	}
+	assert.Nil(t, resp.Body.Close())
+	return resp.StatusCode, nil
+}
+
+func TestRoundTripperTripAndRecover(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	b := withSeededProba(NewBreaker(WithClock(clock)))
+	server := newStatusServer(t, http.StatusInternalServerError)
+	client := WrapHTTPClient(b, server.Client())
+
+	// 连续的500响应触发熔断, 被拒绝的请求不会发送到服务端
+	const total = 200
+	var rejected int
+	for i := 0; i < total; i++ {
+		status, err := server.get(t, client)
+		if err != nil {
+			assert.ErrorIs(t, err, ErrServiceUnavailable)
+			rejected++
+			continue
+		}
+		assert.Equal(t, http.StatusInternalServerError, status)
+	}
+	assert.Greater(t, rejected, total/2)
+	assert.Equal(t, int32(total-rejected), atomic.LoadInt32(&server.hits))
+	errs := b.LastErrors()
+	assert.NotEmpty(t, errs)
+	assert.Equal(t, "GET /users: 500 Internal Server Error", errs[0].Reason)
+
+	// 服务恢复之后, 200的响应逐步让熔断器恢复
+	atomic.StoreInt32(&server.status, http.StatusOK)
+	var recovered bool
+	for i := 0; i < 1000 && !recovered; i++ {
+		clock.Advance(time.Millisecond * 100)
+		status, err := server.get(t, client)
+		recovered = err == nil && status == http.StatusOK && b.Stats().Total == b.Stats().Accepts
+	}
+	assert.True(t, recovered)
+	for i := 0; i < 10; i++ {
+		status, err := server.get(t, client)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, status)
+	}
+}
+
+func TestRoundTripperClassify(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		failure bool
+	}{
+		{"ok", http.StatusOK, false},
+		{"not found", http.StatusNotFound, false},
+		{"too many requests", http.StatusTooManyRequests, false},
+		{"internal error", http.StatusInternalServerError, true},
+		{"unavailable", http.StatusServiceUnavailable, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			b := NewBreaker()
+			server := newStatusServer(t, test.status)
+			status, err := server.get(t, WrapHTTPClient(b, server.Client()))
+			assert.Nil(t, err)
+			assert.Equal(t, test.status, status)
+			st := b.Stats()
+			assert.Equal(t, int64(1), st.Total)
+			if test.failure {
+				assert.Equal(t, int64(0), st.Accepts)
+			} else {
+				assert.Equal(t, int64(1), st.Accepts)
+			}
+		})
+	}
+}
+
+func TestRoundTripperTransportError(t *testing.T) {
+	b := NewBreaker()
+	server := newStatusServer(t, http.StatusOK)
+	client := WrapHTTPClient(b, server.Client())
+	addr := server.URL
+	server.Close()
+
+	_, err := client.Get(addr)
+	assert.Error(t, err)
+	st := b.Stats()
+	assert.Equal(t, int64(1), st.Total)
+	assert.Equal(t, int64(0), st.Accepts)
+	assert.Len(t, b.LastErrors(), 1)
+}
+
+func TestRoundTripperCanceledContext(t *testing.T) {
+	b := NewBreaker()
+	server := newStatusServer(t, http.StatusOK)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	assert.Nil(t, err)
+
+	_, err = WrapHTTPClient(b, server.Client()).Do(req)
+	assert.ErrorIs(t, err, context.Canceled)
+	// 调用方已经取消的请求不计入统计
+	assert.Equal(t, int64(0), b.Stats().Total)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&server.hits))
+}
+
+func TestWithHTTPFailureClassifier(t *testing.T) {
+	b := NewBreaker()
+	server := newStatusServer(t, http.StatusTooManyRequests)
+	client := WrapHTTPClient(b, server.Client(), WithHTTPFailureClassifier(func(resp *http.Response, err error) bool {
+		return err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	}))
+
+	status, err := server.get(t, client)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, status)
+	assert.Equal(t, int64(0), b.Stats().Accepts)
+	assert.True(t, strings.HasSuffix(b.LastErrors()[0].Reason, "429 Too Many Requests"))
+
+	assert.Panics(t, func() {
+		WithHTTPFailureClassifier(nil)
+	})
+}
+
+func TestWrapHTTPClient(t *testing.T) {
+	b := NewBreaker()
+	base := &http.Client{
+		Timeout: time.Second,
+	}
+	client := WrapHTTPClient(b, base)
+	assert.NotSame(t, base, client)
+	assert.Nil(t, base.Transport)
+	assert.Equal(t, time.Second, client.Timeout)
+	rt, ok := client.Transport.(*roundTripper)
+	assert.True(t, ok)
+	assert.Equal(t, http.DefaultTransport, rt.base)
+
+	assert.NotNil(t, WrapHTTPClient(b, nil).Transport)
+}
+
+func TestNewRoundTripperRejected(t *testing.T) {
+	b := NewBreaker()
+	b.ForceOpen()
+	server := newStatusServer(t, http.StatusOK)
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	assert.Nil(t, err)
+
+	resp, err := NewRoundTripper(b, server.Client().Transport).RoundTrip(req)
+	assert.Nil(t, resp)
+	var be *BreakerError
+	assert.True(t, errors.As(err, &be))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&server.hits))
+}
@@ -0,0 +1,50 @@
+package breaker
+
+import "time"
+
+// 请求耗时的观察者, 只有 DoXXX 方法会计时, Allow 由调用方自己执行请求, 无法计时
+type latencyObserver struct {
+	fn func(d time.Duration, err error)
+	// 被熔断拒绝的请求是否也通知, 耗时为0, err为拒绝的错误
+	rejected bool
+}
+
+// WithLatencyObserver 设置请求耗时的观察者, 每个执行过的请求结束之后以耗时和req返回的错误调用fn
+// 不管执行结果是否可接受都会调用, fn需要尽快返回, 不能阻塞请求
+func WithLatencyObserver(fn func(d time.Duration, err error)) Option {
+	if fn == nil {
+		panic("latency observer must not be nil")
+	}
+	return func(b *circuitBreaker) {
+		b.latencyFn = fn
+	}
+}
+
+// WithObserveRejected 被熔断拒绝的请求也通知 WithLatencyObserver 设置的观察者, 耗时为0
+func WithObserveRejected() Option {
+	return func(b *circuitBreaker) {
+		b.observeRejected = true
+	}
+}
+
+func newLatencyObserver(fn func(d time.Duration, err error), rejected bool) *latencyObserver {
+	if fn == nil {
+		return nil
+	}
+	return &latencyObserver{
+		fn:       fn,
+		rejected: rejected,
+	}
+}
+
+func (o *latencyObserver) observe(d time.Duration, err error) {
+	if o != nil {
+		o.fn(d, err)
+	}
+}
+
+func (o *latencyObserver) observeRejected(err error) {
+	if o != nil && o.rejected {
+		o.fn(0, err)
+	}
+}
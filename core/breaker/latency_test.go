@@ -0,0 +1,107 @@
+package breaker
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/timex"
+	"testing"
+	"time"
+)
+
+type latencySample struct {
+	d   time.Duration
+	err error
+}
+
+func TestLatencyObserver(t *testing.T) {
+	var samples []latencySample
+	b := NewBreaker(WithLatencyObserver(func(d time.Duration, err error) {
+		samples = append(samples, latencySample{d: d, err: err})
+	}))
+
+	errDown := errors.New("down")
+	assert.Nil(t, b.Do(func() error {
+		time.Sleep(time.Millisecond * 20)
+		return nil
+	}))
+	// 不可接受的结果也会通知
+	assert.Equal(t, errDown, b.DoWithAcceptable(func() error {
+		time.Sleep(time.Millisecond * 10)
+		return errDown
+	}, func(err error) bool {
+		return false
+	}))
+
+	if assert.Len(t, samples, 2) {
+		assert.GreaterOrEqual(t, samples[0].d, time.Millisecond*20)
+		assert.Less(t, samples[0].d, time.Second)
+		assert.Nil(t, samples[0].err)
+		assert.GreaterOrEqual(t, samples[1].d, time.Millisecond*10)
+		assert.Less(t, samples[1].d, time.Second)
+		assert.Equal(t, errDown, samples[1].err)
+	}
+
+	// 默认不通知被拒绝的请求
+	b.ForceOpen()
+	assert.ErrorIs(t, b.Do(func() error {
+		return nil
+	}), ErrServiceUnavailable)
+	assert.Len(t, samples, 2)
+}
+
+func TestLatencyObserverWithClock(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	var durations []time.Duration
+	b := NewBreaker(WithClock(clock), WithLatencyObserver(func(d time.Duration, err error) {
+		durations = append(durations, d)
+	}))
+	for _, d := range []time.Duration{time.Millisecond, time.Second, 0} {
+		_ = b.Do(func() error {
+			clock.Advance(d)
+			return nil
+		})
+	}
+	assert.Equal(t, []time.Duration{time.Millisecond, time.Second, 0}, durations)
+}
+
+func TestLatencyObserverRejected(t *testing.T) {
+	var samples []latencySample
+	observer := WithLatencyObserver(func(d time.Duration, err error) {
+		samples = append(samples, latencySample{d: d, err: err})
+	})
+
+	b := NewBreaker(observer, WithObserveRejected())
+	b.ForceOpen()
+	err := b.DoWithFallback(func() error {
+		return nil
+	}, func(err error) error {
+		return nil
+	})
+	assert.Nil(t, err)
+	if assert.Len(t, samples, 1) {
+		assert.Equal(t, time.Duration(0), samples[0].d)
+		assert.ErrorIs(t, samples[0].err, ErrServiceUnavailable)
+	}
+
+	// 层级熔断器的子熔断器只通知一次
+	samples = nil
+	h := NewHierarchy("latency-rpc", observer, WithObserveRejected())
+	child := h.Child("GetUser")
+	assert.Nil(t, child.Do(func() error {
+		return nil
+	}))
+	h.ForceOpen()
+	assert.ErrorIs(t, child.Do(func() error {
+		return nil
+	}), ErrServiceUnavailable)
+	if assert.Len(t, samples, 2) {
+		assert.Nil(t, samples[0].err)
+		assert.ErrorIs(t, samples[1].err, ErrServiceUnavailable)
+	}
+}
+
+func TestWithLatencyObserverNil(t *testing.T) {
+	assert.Panics(t, func() {
+		WithLatencyObserver(nil)
+	})
+}
@@ -0,0 +1,105 @@
+package breaker
+
+import (
+	"fmt"
+	"go-zero-/core/proc"
+	"go-zero-/core/stat"
+	"go-zero-/core/timex"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// 熔断后每个被拒绝的请求都会触发上报, 高QPS时会刷屏, 这里限制每个熔断器的上报频率
+
+const (
+	defaultOpenReportInterval = time.Second
+	// 还没有上报过
+	neverReported = -1
+)
+
+type (
+	// OpenReport 熔断器拒绝请求时的上报内容
+	OpenReport struct {
+		Name string
+		// 最近的失败原因, 按时间从新到旧排列
+		Errors []ErrorRecord
+		// 距离上一次上报被忽略的次数
+		Suppressed int64
+	}
+
+	// OpenReportFormatter 将上报内容格式化为上报给stat的字符串
+	OpenReportFormatter func(report OpenReport) string
+
+	dropReporter struct {
+		name     string
+		errWin   *errorWindow
+		interval time.Duration
+		format   OpenReportFormatter
+		clock    timex.Clock
+		// 最后一次上报的时间, 原子读写
+		lastReport int64
+		suppressed int64
+	}
+)
+
+// WithOpenReportInterval 设置熔断拒绝请求时的最小上报间隔, 默认1s, 间隔内被忽略的次数附在下一次上报中
+func WithOpenReportInterval(d time.Duration) Option {
+	if d < 0 {
+		panic("open report interval must not be negative")
+	}
+	return func(b *circuitBreaker) {
+		b.openReportInterval = d
+	}
+}
+
+// WithOpenReportFormatter 设置熔断拒绝请求时上报内容的格式, 例如输出单行的json, 默认为多行文本
+func WithOpenReportFormatter(format OpenReportFormatter) Option {
+	return func(b *circuitBreaker) {
+		b.openReportFormatter = format
+	}
+}
+
+func (cb *circuitBreaker) newDropReporter(errWin *errorWindow) *dropReporter {
+	format := cb.openReportFormatter
+	if format == nil {
+		format = formatOpenReport
+	}
+
+	return &dropReporter{
+		name:       cb.name,
+		errWin:     errWin,
+		interval:   cb.openReportInterval,
+		format:     format,
+		clock:      cb.clock,
+		lastReport: neverReported,
+	}
+}
+
+func (r *dropReporter) report() {
+	now := int64(r.clock.Now())
+	last := atomic.LoadInt64(&r.lastReport)
+	if last != neverReported && now-last < int64(r.interval) ||
+		!atomic.CompareAndSwapInt64(&r.lastReport, last, now) {
+		atomic.AddInt64(&r.suppressed, 1)
+		return
+	}
+
+	stat.Report(r.format(OpenReport{
+		Name:       r.name,
+		Errors:     r.errWin.Export(),
+		Suppressed: atomic.SwapInt64(&r.suppressed, 0),
+	}))
+}
+
+func formatOpenReport(report OpenReport) string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "proc(%s/%d), callee: %s, breaker is open and requests dropped",
+		proc.ProcessName(), proc.Pid(), report.Name)
+	if report.Suppressed > 0 {
+		fmt.Fprintf(&builder, ", %d reports suppressed", report.Suppressed)
+	}
+	builder.WriteString("\nlast errors:\n")
+	builder.WriteString(formatErrorRecords(report.Errors))
+	return builder.String()
+}
@@ -0,0 +1,115 @@
+package breaker
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/stat"
+	"go-zero-/core/timex"
+	"strings"
+	"testing"
+	"time"
+)
+
+func captureOpenReports(t *testing.T) *[]string {
+	var msgs []string
+	stat.SetReporter(func(level stat.Level, msg string) {
+		if level == stat.LevelError {
+			msgs = append(msgs, msg)
+		}
+	})
+	t.Cleanup(func() {
+		stat.SetReporter(nil)
+	})
+	return &msgs
+}
+
+func TestOpenReportRateLimit(t *testing.T) {
+	msgs := captureOpenReports(t)
+	var reports []OpenReport
+	clock := timex.NewMockClock(0)
+	b := NewBreaker(WithName("flood-rpc"), WithClock(clock), WithOpenReportFormatter(func(report OpenReport) string {
+		reports = append(reports, report)
+		return formatOpenReport(report)
+	}))
+	b.ForceOpen()
+
+	for i := 0; i < 10000; i++ {
+		assert.ErrorIs(t, b.Do(func() error {
+			return nil
+		}), ErrServiceUnavailable)
+		clock.Advance(time.Millisecond)
+	}
+
+	// 每秒最多上报一次, 间隔内被忽略的次数附在下一次上报中
+	assert.Len(t, *msgs, 10)
+	assert.Len(t, reports, 10)
+	assert.Equal(t, int64(0), reports[0].Suppressed)
+	for _, report := range reports[1:] {
+		assert.Equal(t, "flood-rpc", report.Name)
+		assert.Equal(t, int64(999), report.Suppressed)
+	}
+	assert.False(t, strings.Contains((*msgs)[0], "suppressed"))
+	assert.True(t, strings.Contains((*msgs)[1], "flood-rpc, breaker is open and requests dropped, 999 reports suppressed"))
+}
+
+func TestOpenReportWithoutInterval(t *testing.T) {
+	msgs := captureOpenReports(t)
+	b := NewBreaker(WithOpenReportInterval(0))
+	b.ForceOpen()
+	for i := 0; i < 5; i++ {
+		_ = b.Do(func() error {
+			return nil
+		})
+	}
+	assert.Len(t, *msgs, 5)
+}
+
+func TestOpenReportFormatter(t *testing.T) {
+	msgs := captureOpenReports(t)
+	b := NewBreaker(WithName("json-rpc"), WithOpenReportFormatter(func(report OpenReport) string {
+		reasons := make([]string, 0, len(report.Errors))
+		for _, record := range report.Errors {
+			reasons = append(reasons, record.Reason)
+		}
+		data, _ := json.Marshal(map[string]any{
+			"breaker":    report.Name,
+			"errors":     reasons,
+			"suppressed": report.Suppressed,
+		})
+		return string(data)
+	}))
+	_ = b.Do(func() error {
+		return errors.New("timeout")
+	})
+	b.ForceOpen()
+	_ = b.Do(func() error {
+		return nil
+	})
+
+	assert.Equal(t, []string{`{"breaker":"json-rpc","errors":["timeout"],"suppressed":0}`}, *msgs)
+}
+
+func TestOpenReportHierarchy(t *testing.T) {
+	msgs := captureOpenReports(t)
+	h := NewHierarchy("report-limit-rpc")
+	child := h.Child("GetUser")
+	h.ForceOpen()
+	for i := 0; i < 100; i++ {
+		_ = child.Do(func() error {
+			return nil
+		})
+	}
+	// 父熔断器与直接使用父熔断器共用同一个频率限制
+	_ = h.Do(func() error {
+		return nil
+	})
+	assert.Len(t, *msgs, 1)
+	assert.True(t, strings.Contains((*msgs)[0], "callee: report-limit-rpc,"))
+}
+
+func TestWithOpenReportIntervalInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		WithOpenReportInterval(-time.Second)
+	})
+}
@@ -0,0 +1,139 @@
+package breaker
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// 按秒累计熔断时长, 统计窗口的起点落在某一秒中间时, 这一秒可能多算, 误差不超过一秒
+	openBucketDuration = time.Second
+	// 只保留最近两分钟, 更长的统计窗口按两分钟计算
+	openBuckets = 120
+)
+
+type (
+	// 记录熔断器处于熔断中的时长, 熔断状态只在请求到来时更新
+	// 与熔断状态切换的次数无关, 频繁切换时也能统计完整的窗口
+	openTracker struct {
+		// 本次熔断开始的时间, notOpened表示未处于熔断中, 原子读写, 状态不变时无需加锁
+		since int64
+		lock  sync.Mutex
+		// 已经结束的熔断按秒拆分后累加, 环形存储, 通过桶的序号判断是否已经被新的一秒复用
+		buckets [openBuckets]openBucket
+	}
+
+	openBucket struct {
+		// 桶对应第几秒, 即 start / openBucketDuration
+		index int64
+		open  time.Duration
+	}
+
+	openSpan struct {
+		start time.Duration
+		end   time.Duration
+	}
+)
+
+func newOpenTracker() openTracker {
+	return openTracker{
+		since: notOpened,
+	}
+}
+
+func (t *openTracker) mark(open bool, now time.Duration) {
+	since := atomic.LoadInt64(&t.since)
+	if open == (since != notOpened) {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// 加锁后再检查一次, 避免并发时重复记录
+	since = atomic.LoadInt64(&t.since)
+	switch {
+	case open && since == notOpened:
+		atomic.StoreInt64(&t.since, int64(now))
+	case !open && since != notOpened:
+		t.add(openSpan{start: time.Duration(since), end: now})
+		atomic.StoreInt64(&t.since, notOpened)
+	}
+}
+
+// 调用方需要持有锁, 把熔断时间段拆分到所在的每一秒, 超出保留范围的部分直接丢弃
+func (t *openTracker) add(span openSpan) {
+	if span.end <= span.start {
+		return
+	}
+
+	first := int64(span.start / openBucketDuration)
+	last := int64((span.end - 1) / openBucketDuration)
+	if oldest := last - openBuckets + 1; first < oldest {
+		first = oldest
+	}
+	for i := first; i <= last; i++ {
+		start := time.Duration(i) * openBucketDuration
+		d := overlap(span, start, start+openBucketDuration)
+		b := &t.buckets[i%openBuckets]
+		if b.index != i {
+			b.index = i
+			b.open = 0
+		}
+		b.open += d
+	}
+}
+
+// 统计 [now-window, now] 之内处于熔断中的时长, 包括还没有结束的本次熔断
+func (t *openTracker) duration(now, window time.Duration) time.Duration {
+	if limit := openBuckets * openBucketDuration; window > limit {
+		window = limit
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	// 时钟从0开始时窗口可能早于时钟起点, 从起点开始统计, 避免桶序号为负数
+	from := now - window
+	if from < 0 {
+		from = 0
+	}
+	first := int64(from / openBucketDuration)
+	last := int64(now / openBucketDuration)
+	var total time.Duration
+	for i := first; i <= last; i++ {
+		b := t.buckets[i%openBuckets]
+		if b.index != i || b.open == 0 {
+			continue
+		}
+
+		// 不知道最旧的一秒内具体哪一段处于熔断中, 最多只算窗口覆盖的部分
+		if covered := overlap(openSpan{
+			start: time.Duration(i) * openBucketDuration,
+			end:   time.Duration(i+1) * openBucketDuration,
+		}, from, now); b.open > covered {
+			total += covered
+		} else {
+			total += b.open
+		}
+	}
+	if since := atomic.LoadInt64(&t.since); since != notOpened {
+		total += overlap(openSpan{start: time.Duration(since), end: now}, from, now)
+	}
+	return total
+}
+
+func overlap(span openSpan, from, to time.Duration) time.Duration {
+	start, end := span.start, span.end
+	if start < from {
+		start = from
+	}
+	if end > to {
+		end = to
+	}
+	if end <= start {
+		return 0
+	}
+	return end - start
+}
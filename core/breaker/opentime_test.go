@@ -0,0 +1,107 @@
+package breaker
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/timex"
+	"testing"
+	"time"
+)
+
+func TestOpenDurationNeverOpened(t *testing.T) {
+	b := NewBreaker()
+	for i := 0; i < 10; i++ {
+		assert.Nil(t, b.Do(func() error {
+			return nil
+		}))
+	}
+	assert.Equal(t, time.Duration(0), b.OpenDuration(time.Minute))
+}
+
+func TestOpenDurationBeforeWindow(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	b := NewBreaker(WithClock(clock))
+	assert.Equal(t, time.Duration(0), b.OpenDuration(time.Minute))
+
+	// 统计窗口大于时钟已经走过的时长
+	clock.Advance(time.Second * 10)
+	b.ForceOpen()
+	_ = b.Do(func() error {
+		return nil
+	})
+	clock.Advance(time.Second * 5)
+	assert.Equal(t, time.Second*5, b.OpenDuration(time.Minute))
+	assert.Equal(t, time.Second*5, b.OpenDuration(time.Hour))
+
+	b.ClearForce()
+	_ = b.Do(func() error {
+		return nil
+	})
+	assert.Equal(t, time.Second*5, b.OpenDuration(time.Minute))
+}
+
+func TestOpenDuration(t *testing.T) {
+	clock := timex.NewMockClock(time.Hour)
+	b := NewBreaker(WithClock(clock))
+	req := func() error {
+		return nil
+	}
+
+	b.ForceOpen()
+	_ = b.Do(req)
+	clock.Advance(time.Second * 20)
+	// 还没有结束的熔断也统计在内
+	assert.Equal(t, time.Second*20, b.OpenDuration(time.Minute))
+
+	b.ClearForce()
+	assert.Nil(t, b.Do(req))
+	clock.Advance(time.Second * 10)
+	assert.Equal(t, time.Second*20, b.OpenDuration(time.Minute))
+	// 只统计窗口内的部分
+	assert.Equal(t, time.Second*5, b.OpenDuration(time.Second*15))
+	assert.Equal(t, time.Duration(0), b.OpenDuration(time.Second*10))
+
+	// 多次熔断累加
+	b.ForceOpen()
+	_ = b.Do(req)
+	clock.Advance(time.Second * 5)
+	b.ClearForce()
+	assert.Nil(t, b.Do(req))
+	assert.Equal(t, time.Second*25, b.OpenDuration(time.Minute))
+
+	// 窗口滑过之后不再统计
+	clock.Advance(time.Minute)
+	assert.Equal(t, time.Duration(0), b.OpenDuration(time.Minute))
+}
+
+func TestOpenTrackerFlapping(t *testing.T) {
+	tracker := newOpenTracker()
+	var now time.Duration
+	// 熔断状态每10ms切换一次, 切换的次数远多于桶的数量
+	for i := 0; i < 6000; i++ {
+		tracker.mark(true, now)
+		now += time.Millisecond * 10
+		tracker.mark(false, now)
+		now += time.Millisecond * 10
+	}
+	assert.Equal(t, time.Minute*2, now)
+	assert.Equal(t, time.Second*30, tracker.duration(now, time.Minute))
+	assert.Equal(t, time.Second*5, tracker.duration(now, time.Second*10))
+
+	// 超出保留范围的窗口按保留范围统计
+	assert.Equal(t, time.Minute, tracker.duration(now, time.Hour))
+}
+
+func TestOpenTrackerLongSpan(t *testing.T) {
+	tracker := newOpenTracker()
+	tracker.mark(true, time.Second*10)
+	tracker.mark(false, time.Minute*10)
+	now := time.Minute*10 + time.Second*30
+	assert.Equal(t, time.Second*30, tracker.duration(now, time.Minute))
+	// 窗口起点落在某一秒中间时最多算窗口覆盖的部分
+	assert.Equal(t, time.Millisecond*500, tracker.duration(now, time.Second*30+time.Millisecond*500))
+
+	// 很久之后同一个桶被新的一秒复用
+	tracker.mark(true, time.Hour)
+	tracker.mark(false, time.Hour+time.Second)
+	assert.Equal(t, time.Second, tracker.duration(time.Hour+time.Minute, time.Hour))
+}
@@ -0,0 +1,68 @@
+package breaker
+
+import (
+	"go-zero-/core/proc"
+	"go-zero-/core/stat"
+	"sync"
+)
+
+// 同名熔断器的进程级注册表
+
+var (
+	sharedLock     sync.Mutex
+	sharedBreakers = make(map[string]*sharedBreaker)
+)
+
+type sharedBreaker struct {
+	breaker  Breaker
+	settings googleSettings
+}
+
+// NewSharedBreaker 创建进程内共享的熔断器, 同名的熔断器共用一份统计数据
+// 以第一次创建时的配置为准, 之后同名创建时配置不一致会通过stat上报, 并忽略新的配置
+// 未设置名字时等同于 NewBreaker, 每次都创建独立的熔断器
+func NewSharedBreaker(opts ...Option) Breaker {
+	b, _ := registerSharedBreaker(opts...)
+	return b
+}
+
+// 返回注册表中的熔断器, 不存在时创建并注册, created表示是否为本次创建
+func registerSharedBreaker(opts ...Option) (b Breaker, created bool) {
+	var cb circuitBreaker
+	for _, opt := range opts {
+		opt(&cb)
+	}
+	if len(cb.name) == 0 {
+		return NewBreaker(opts...), true
+	}
+
+	settings := newGoogleSettings(cb.googleOpts...)
+
+	sharedLock.Lock()
+	defer sharedLock.Unlock()
+
+	if shared, ok := sharedBreakers[cb.name]; ok {
+		if shared.settings != settings {
+			stat.ReportLevel(stat.LevelWarn, "proc(%s/%d), breaker %s already exists with different options, "+
+				"new options are ignored", proc.ProcessName(), proc.Pid(), cb.name)
+		}
+		return shared.breaker, false
+	}
+
+	shared := &sharedBreaker{
+		breaker:  NewBreaker(opts...),
+		settings: settings,
+	}
+	sharedBreakers[cb.name] = shared
+	return shared.breaker, true
+}
+
+// 从注册表中移除名为name的熔断器, 只有注册的就是b时才移除, 避免误删之后同名创建的熔断器
+func unregisterSharedBreaker(name string, b Breaker) {
+	sharedLock.Lock()
+	defer sharedLock.Unlock()
+
+	if shared, ok := sharedBreakers[name]; ok && shared.breaker == b {
+		delete(sharedBreakers, name)
+	}
+}
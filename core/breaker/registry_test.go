@@ -0,0 +1,68 @@
+package breaker
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/stat"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestNewSharedBreaker(t *testing.T) {
+	var reports []string
+	stat.SetReporter(func(level stat.Level, msg string) {
+		assert.Equal(t, stat.LevelWarn, level)
+		reports = append(reports, msg)
+	})
+	defer stat.SetReporter(nil)
+
+	b1 := NewSharedBreaker(WithName("shared-user-rpc"), WithK(2))
+	b2 := NewSharedBreaker(WithName("shared-user-rpc"), WithK(2))
+	assert.Same(t, b1, b2)
+	assert.Empty(t, reports)
+
+	// 配置冲突时上报, 并返回已有的熔断器
+	b3 := NewSharedBreaker(WithName("shared-user-rpc"), WithK(3))
+	assert.Same(t, b1, b3)
+	assert.Len(t, reports, 1)
+	assert.True(t, strings.Contains(reports[0], "shared-user-rpc"))
+	// 以第一次创建的配置为准
+	assert.Equal(t, 2.0, b2.Stats().K)
+
+	assert.Nil(t, b1.Do(func() error {
+		return nil
+	}))
+	assert.Equal(t, int64(1), b2.Stats().Total)
+
+	other := NewSharedBreaker(WithName("shared-order-rpc"))
+	assert.NotSame(t, b1, other)
+}
+
+func TestNewSharedBreakerUnnamed(t *testing.T) {
+	b1 := NewSharedBreaker()
+	b2 := NewSharedBreaker()
+	assert.NotSame(t, b1, b2)
+	assert.NotEqual(t, b1.Name(), b2.Name())
+}
+
+func TestNewSharedBreakerConcurrent(t *testing.T) {
+	const total = 100
+	breakers := make([]Breaker, total)
+	var wg sync.WaitGroup
+	for i := 0; i < total; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			breakers[i] = NewSharedBreaker(WithName("shared-concurrent"))
+			_ = breakers[i].Do(func() error {
+				return nil
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i < total; i++ {
+		assert.Same(t, breakers[0], breakers[i])
+	}
+	assert.Equal(t, int64(total), breakers[0].Stats().Total)
+}
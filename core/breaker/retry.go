@@ -0,0 +1,106 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"go-zero-/core/timex"
+	"time"
+)
+
+type (
+	// Backoff 重试的等待策略
+	Backoff interface {
+		// Backoff 返回第attempt次重试之前的等待时间, attempt从1开始
+		Backoff(attempt int) time.Duration
+	}
+
+	// BackoffFunc 将函数转换为 Backoff
+	BackoffFunc func(attempt int) time.Duration
+
+	// RetryOption 自定义 DoWithRetry 的重试策略
+	RetryOption func(opts *retryOptions)
+
+	retryOptions struct {
+		backoff   Backoff
+		retryable func(err error) bool
+	}
+
+	exponentialBackoff struct {
+		base time.Duration
+		max  time.Duration
+	}
+)
+
+// DoWithRetry 通过熔断器执行req, 失败时最多执行attempts次
+// 熔断器拒绝或者ctx结束时立即停止, 不会在熔断期间放大下游的压力, 等待下一次重试时同样响应ctx的取消
+func DoWithRetry(b Breaker, ctx context.Context, req func() error, attempts int, opts ...RetryOption) error {
+	options := retryOptions{
+		backoff:   BackoffFunc(defaultRetryBackoff),
+		retryable: defaultRetryable,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var err error
+	for i := 0; i < attempts || i == 0; i++ {
+		if i > 0 {
+			if err := timex.SleepWithContext(ctx, options.backoff.Backoff(i)); err != nil {
+				return err
+			}
+		}
+
+		err = b.DoCtx(ctx, req)
+		if err == nil || errors.Is(err, ErrServiceUnavailable) || ctx.Err() != nil || !options.retryable(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// WithRetryBackoff 设置重试的等待策略, 默认为100ms开始、最大5s的带抖动指数退避
+func WithRetryBackoff(backoff Backoff) RetryOption {
+	return func(opts *retryOptions) {
+		opts.backoff = backoff
+	}
+}
+
+// WithRetryable 设置哪些错误需要重试, 默认除了熔断器拒绝和ctx结束之外的错误都重试
+func WithRetryable(retryable func(err error) bool) RetryOption {
+	return func(opts *retryOptions) {
+		opts.retryable = retryable
+	}
+}
+
+// NewExponentialBackoff 返回带抖动的指数退避, base, base*2, base*4 ... 最大不超过max
+func NewExponentialBackoff(base, max time.Duration) Backoff {
+	if base <= 0 || max < base {
+		panic("backoff base must be greater than 0 and not greater than max")
+	}
+	return exponentialBackoff{
+		base: base,
+		max:  max,
+	}
+}
+
+func (f BackoffFunc) Backoff(attempt int) time.Duration {
+	return f(attempt)
+}
+
+func (b exponentialBackoff) Backoff(attempt int) time.Duration {
+	return retryJitter.AroundDuration(exponential(b.base, b.max, attempt))
+}
+
+// 第attempt次的指数退避时间, 不带抖动
+func exponential(base, max time.Duration, attempt int) time.Duration {
+	// 避免左移溢出
+	if attempt < 32 && base<<(attempt-1) < max {
+		return base << (attempt - 1)
+	}
+	return max
+}
+
+func defaultRetryable(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
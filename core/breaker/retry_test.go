@@ -0,0 +1,136 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+var noRetryBackoff = WithRetryBackoff(BackoffFunc(func(int) time.Duration {
+	return 0
+}))
+
+func TestDoWithRetry(t *testing.T) {
+	errDown := errors.New("down")
+
+	t.Run("exhausted", func(t *testing.T) {
+		b := NewBreaker()
+		var executed int
+		err := DoWithRetry(b, context.Background(), func() error {
+			executed++
+			return errDown
+		}, 3, noRetryBackoff)
+		assert.Equal(t, errDown, err)
+		assert.Equal(t, 3, executed)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		b := NewBreaker()
+		var executed int
+		assert.Nil(t, DoWithRetry(b, context.Background(), func() error {
+			executed++
+			if executed < 2 {
+				return errDown
+			}
+			return nil
+		}, 5, noRetryBackoff))
+		assert.Equal(t, 2, executed)
+		// 每次执行都计入统计, 之后的成功也会记录
+		st := b.Stats()
+		assert.Equal(t, int64(1), st.Accepts)
+		assert.Equal(t, int64(2), st.Total)
+	})
+
+	t.Run("breaker open", func(t *testing.T) {
+		b := NewBreaker()
+		var executed int
+		err := DoWithRetry(b, context.Background(), func() error {
+			executed++
+			b.ForceOpen()
+			return errDown
+		}, 5, noRetryBackoff)
+		assert.ErrorIs(t, err, ErrServiceUnavailable)
+		assert.Equal(t, 1, executed)
+	})
+
+	t.Run("not retryable", func(t *testing.T) {
+		b := NewBreaker()
+		var executed int
+		err := DoWithRetry(b, context.Background(), func() error {
+			executed++
+			return errDown
+		}, 5, noRetryBackoff, WithRetryable(func(err error) bool {
+			return !errors.Is(err, errDown)
+		}))
+		assert.Equal(t, errDown, err)
+		assert.Equal(t, 1, executed)
+	})
+
+	t.Run("context canceled while waiting", func(t *testing.T) {
+		b := NewBreaker()
+		ctx, cancel := context.WithCancel(context.Background())
+		var executed int
+		start := time.Now()
+		err := DoWithRetry(b, ctx, func() error {
+			executed++
+			cancel()
+			return errDown
+		}, 5, WithRetryBackoff(BackoffFunc(func(int) time.Duration {
+			return time.Hour
+		})))
+		assert.Equal(t, errDown, err)
+		assert.Equal(t, 1, executed)
+		assert.True(t, time.Since(start) < time.Second)
+	})
+
+	t.Run("context deadline during backoff", func(t *testing.T) {
+		b := NewBreaker()
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+		var executed int
+		err := DoWithRetry(b, ctx, func() error {
+			executed++
+			return errDown
+		}, 5, WithRetryBackoff(BackoffFunc(func(int) time.Duration {
+			return time.Hour
+		})))
+		assert.Equal(t, context.DeadlineExceeded, err)
+		assert.Equal(t, 1, executed)
+	})
+
+	t.Run("context already done", func(t *testing.T) {
+		b := NewBreaker()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		err := DoWithRetry(b, ctx, func() error {
+			t.Fatal("request should not be executed")
+			return nil
+		}, 5)
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, int64(0), b.Stats().Total)
+	})
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	backoff := NewExponentialBackoff(time.Millisecond*10, time.Millisecond*50)
+	for attempt, base := range map[int]time.Duration{
+		1:  time.Millisecond * 10,
+		2:  time.Millisecond * 20,
+		3:  time.Millisecond * 40,
+		4:  time.Millisecond * 50,
+		40: time.Millisecond * 50,
+	} {
+		d := backoff.Backoff(attempt)
+		assert.True(t, d >= time.Duration(float64(base)*(1-retryDeviation)), "attempt %d: %s", attempt, d)
+		assert.True(t, d <= time.Duration(float64(base)*(1+retryDeviation)), "attempt %d: %s", attempt, d)
+	}
+
+	assert.Panics(t, func() {
+		NewExponentialBackoff(0, time.Second)
+	})
+	assert.Panics(t, func() {
+		NewExponentialBackoff(time.Second, time.Millisecond)
+	})
+}
@@ -0,0 +1,125 @@
+package breaker
+
+import (
+	"go-zero-/core/stat"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 定期上报熔断器的请求汇总, 例如 breaker user-rpc: requests 10231, drops 322, errors 518
+// 只在熔断时上报的话, 故障期间很难从日志中看出流量的变化
+
+type (
+	// 一个上报周期内的计数, 原子读写
+	summaryCounter struct {
+		// 放行并执行的请求数
+		admits int64
+		// 被拒绝的请求数
+		drops int64
+		// 执行失败的请求数
+		failures int64
+	}
+
+	summarizer struct {
+		name    string
+		counter *summaryCounter
+		done    chan struct{}
+		once    sync.Once
+	}
+)
+
+// WithPeriodicReport 每隔interval通过stat上报一次该周期内的请求数、拒绝数和失败数, 没有请求的周期不上报
+// 熔断器被回收或者调用Close之后停止上报, Close 通过 io.Closer 调用: b.(io.Closer).Close()
+func WithPeriodicReport(interval time.Duration) Option {
+	if interval <= 0 {
+		panic("report interval must be greater than 0")
+	}
+	return func(b *circuitBreaker) {
+		b.reportInterval = interval
+	}
+}
+
+// 为googleBreaker开启定期上报, 上报的协程不持有熔断器, 熔断器被回收时通过finalizer停止
+func (cb *circuitBreaker) startSummary(gb *googleBreaker) {
+	if cb.reportInterval <= 0 {
+		return
+	}
+
+	s := newSummarizer(cb.name)
+	gb.counter = s.counter
+	cb.summary = s
+	go s.run(cb.reportInterval)
+	runtime.SetFinalizer(cb, func(cb *circuitBreaker) {
+		cb.summary.stop()
+	})
+}
+
+// Close 停止定期上报, 未开启定期上报时什么都不做
+func (cb *circuitBreaker) Close() error {
+	if cb.summary != nil {
+		cb.summary.stop()
+	}
+	return nil
+}
+
+func newSummarizer(name string) *summarizer {
+	return &summarizer{
+		name:    name,
+		counter: new(summaryCounter),
+		done:    make(chan struct{}),
+	}
+}
+
+func (s *summarizer) run(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.report()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// 上报并清零本周期的计数
+func (s *summarizer) report() {
+	admits := atomic.SwapInt64(&s.counter.admits, 0)
+	drops := atomic.SwapInt64(&s.counter.drops, 0)
+	failures := atomic.SwapInt64(&s.counter.failures, 0)
+	if admits == 0 && drops == 0 {
+		return
+	}
+
+	stat.ReportLevel(stat.LevelInfo, "breaker %s: requests %d, drops %d, errors %d",
+		s.name, admits+drops, drops, failures)
+}
+
+func (s *summarizer) stop() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+// 以下方法在未开启定期上报时c为nil, 直接忽略
+
+func (c *summaryCounter) admit(failed bool) {
+	if c == nil {
+		return
+	}
+
+	atomic.AddInt64(&c.admits, 1)
+	if failed {
+		atomic.AddInt64(&c.failures, 1)
+	}
+}
+
+func (c *summaryCounter) drop() {
+	if c != nil {
+		atomic.AddInt64(&c.drops, 1)
+	}
+}
@@ -0,0 +1,150 @@
+package breaker
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/stat"
+	"io"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+type summaryReports struct {
+	lock sync.Mutex
+	msgs []string
+}
+
+func captureSummaryReports(t *testing.T) *summaryReports {
+	reports := new(summaryReports)
+	stat.SetReporter(func(level stat.Level, msg string) {
+		if level != stat.LevelInfo {
+			return
+		}
+
+		reports.lock.Lock()
+		reports.msgs = append(reports.msgs, msg)
+		reports.lock.Unlock()
+	})
+	t.Cleanup(func() {
+		stat.SetReporter(nil)
+	})
+	return reports
+}
+
+func (r *summaryReports) take() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	msgs := r.msgs
+	r.msgs = nil
+	return msgs
+}
+
+func TestSummaryReport(t *testing.T) {
+	reports := captureSummaryReports(t)
+	b := NewBreaker(WithName("summary-rpc"), WithPeriodicReport(time.Hour))
+	defer b.(io.Closer).Close()
+	s := b.(*circuitBreaker).summary
+
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, b.Do(func() error {
+			return nil
+		}))
+	}
+	_ = b.Do(func() error {
+		return errors.New("failed")
+	})
+	b.ForceOpen()
+	_ = b.Do(func() error {
+		return nil
+	})
+	_, _ = b.Allow()
+	s.report()
+	assert.Equal(t, []string{"breaker summary-rpc: requests 6, drops 2, errors 1"}, reports.take())
+
+	// 每个周期重新计数, 没有请求的周期不上报
+	s.report()
+	assert.Empty(t, reports.take())
+	b.ClearForce()
+	p, err := b.Allow()
+	assert.Nil(t, err)
+	p.Reject("failed")
+	s.report()
+	assert.Equal(t, []string{"breaker summary-rpc: requests 1, drops 0, errors 1"}, reports.take())
+}
+
+func TestSummaryReportPeriodically(t *testing.T) {
+	reports := captureSummaryReports(t)
+	b := NewBreaker(WithName("periodic-rpc"), WithPeriodicReport(time.Millisecond*10))
+	assert.Nil(t, b.Do(func() error {
+		return nil
+	}))
+
+	var msgs []string
+	for i := 0; i < 100 && len(msgs) == 0; i++ {
+		time.Sleep(time.Millisecond * 10)
+		msgs = reports.take()
+	}
+	assert.Equal(t, []string{"breaker periodic-rpc: requests 1, drops 0, errors 0"}, msgs)
+
+	// Close之后不再上报
+	assert.Nil(t, b.(io.Closer).Close())
+	assert.Nil(t, b.(io.Closer).Close())
+	time.Sleep(time.Millisecond * 30)
+	_ = b.Do(func() error {
+		return nil
+	})
+	time.Sleep(time.Millisecond * 30)
+	assert.Empty(t, reports.take())
+}
+
+func TestSummaryStopOnGC(t *testing.T) {
+	b := NewBreaker(WithPeriodicReport(time.Hour))
+	done := b.(*circuitBreaker).summary.done
+	b = nil
+
+	var stopped bool
+	for i := 0; i < 100 && !stopped; i++ {
+		runtime.GC()
+		select {
+		case <-done:
+			stopped = true
+		case <-time.After(time.Millisecond * 10):
+		}
+	}
+	assert.True(t, stopped)
+}
+
+func TestSummaryHierarchy(t *testing.T) {
+	reports := captureSummaryReports(t)
+	h := NewHierarchy("summary-hierarchy-rpc", WithPeriodicReport(time.Hour))
+	defer h.Close()
+	child := h.Child("GetUser")
+	defer child.(io.Closer).Close()
+
+	assert.Nil(t, child.Do(func() error {
+		return nil
+	}))
+	child.ForceOpen()
+	_ = child.Do(func() error {
+		return nil
+	})
+
+	h.summary.report()
+	child.(*circuitBreaker).summary.report()
+	// 被子熔断器拒绝的请求不计入父熔断器
+	assert.Equal(t, []string{
+		"breaker summary-hierarchy-rpc: requests 1, drops 0, errors 0",
+		"breaker summary-hierarchy-rpc/GetUser: requests 2, drops 1, errors 0",
+	}, reports.take())
+}
+
+func TestSummaryDisabled(t *testing.T) {
+	b := NewBreaker()
+	assert.Nil(t, b.(*circuitBreaker).summary)
+	assert.Nil(t, b.(io.Closer).Close())
+	assert.Panics(t, func() {
+		WithPeriodicReport(0)
+	})
+}
@@ -0,0 +1,26 @@
+package breaker
+
+func (cb *circuitBreaker) Wrap(fn func() error) func() error {
+	return func() error {
+		return cb.Do(fn)
+	}
+}
+
+func (cb *circuitBreaker) WrapWithFallback(fn func() error, fallback Fallback) func() error {
+	return func() error {
+		return cb.DoWithFallback(fn, fallback)
+	}
+}
+
+// WrapFunc 同 Breaker.Wrap, 用于有返回值的函数, 熔断器拒绝请求时返回T的零值和拒绝的错误
+func WrapFunc[T any](b Breaker, fn func() (T, error)) func() (T, error) {
+	return func() (T, error) {
+		var val T
+		err := b.Do(func() error {
+			var err error
+			val, err = fn()
+			return err
+		})
+		return val, err
+	}
+}
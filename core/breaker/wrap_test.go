@@ -0,0 +1,88 @@
+package breaker
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestWrap(t *testing.T) {
+	errDown := errors.New("down")
+	newFn := func(calls *int) func() error {
+		return func() error {
+			*calls++
+			if *calls%2 == 0 {
+				return errDown
+			}
+			return nil
+		}
+	}
+
+	// 与直接调用 b.Do 的结果和统计一致
+	var directCalls, wrappedCalls int
+	direct := NewBreaker()
+	directFn := newFn(&directCalls)
+	wrapped := NewBreaker()
+	call := wrapped.Wrap(newFn(&wrappedCalls))
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, direct.Do(directFn), call())
+	}
+	assert.Equal(t, 10, wrappedCalls)
+	ds, ws := direct.Stats(), wrapped.Stats()
+	assert.Equal(t, ds.Accepts, ws.Accepts)
+	assert.Equal(t, ds.Total, ws.Total)
+
+	wrapped.ForceOpen()
+	assert.ErrorIs(t, call(), ErrServiceUnavailable)
+	assert.Equal(t, 10, wrappedCalls)
+}
+
+func TestWrapWithFallback(t *testing.T) {
+	b := NewBreaker()
+	errFallback := errors.New("fallback")
+	var fallbacks int
+	call := b.WrapWithFallback(func() error {
+		return nil
+	}, func(err error) error {
+		fallbacks++
+		assert.ErrorIs(t, err, ErrServiceUnavailable)
+		return errFallback
+	})
+
+	assert.Nil(t, call())
+	b.ForceOpen()
+	err := call()
+	assert.ErrorIs(t, err, errFallback)
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+	assert.Equal(t, 1, fallbacks)
+}
+
+func TestWrapFunc(t *testing.T) {
+	b := NewBreaker()
+	errDown := errors.New("down")
+	var fail bool
+	call := WrapFunc(b, func() (int, error) {
+		if fail {
+			return -1, errDown
+		}
+		return 42, nil
+	})
+
+	v, err := call()
+	assert.Nil(t, err)
+	assert.Equal(t, 42, v)
+
+	// 原样返回fn的结果
+	fail = true
+	v, err = call()
+	assert.Equal(t, errDown, err)
+	assert.Equal(t, -1, v)
+	assert.Equal(t, int64(1), b.Stats().Accepts)
+	assert.Equal(t, int64(2), b.Stats().Total)
+
+	// 熔断时返回零值
+	b.ForceOpen()
+	v, err = call()
+	assert.ErrorIs(t, err, ErrServiceUnavailable)
+	assert.Equal(t, 0, v)
+}
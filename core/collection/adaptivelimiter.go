@@ -0,0 +1,193 @@
+package collection
+
+import (
+	"go-zero-/core/timex"
+	"math"
+	"sync"
+	"time"
+)
+
+// 自适应并发限制, 根据滑动窗口内的平均延迟和成功率按AIMD调整允许的并发数
+// 状态良好且使用了一半以上的并发数时, 每个成功的请求增加 1/limit, 即并发数用满的每一轮请求大约加0.5
+// 平均延迟超过目标或者成功率低于阈值时乘以 decreaseRatio, 每个桶的时长内最多减少一次, 避免连续减到最小值
+
+const (
+	defaultLimiterBuckets       = 10
+	defaultLimiterInterval      = time.Millisecond * 100
+	defaultLimiterSuccessRatio  = 0.9
+	defaultLimiterDecreaseRatio = 0.9
+)
+
+type (
+	AdaptiveLimiter struct {
+		lock     sync.Mutex
+		limit    float64
+		minLimit int
+		maxLimit int
+		inflight int
+		// 请求的耗时, Sum为纳秒
+		latency *RollingWindow
+		// 请求的结果, 成功为1失败为0
+		results      *RollingWindow
+		lastDecrease time.Duration
+		options      adaptiveLimiterOptions
+	}
+
+	// AdaptiveLimiterOption 自适应并发限制的配置
+	AdaptiveLimiterOption func(opts *adaptiveLimiterOptions)
+
+	adaptiveLimiterOptions struct {
+		buckets       int
+		interval      time.Duration
+		targetLatency time.Duration
+		successRatio  float64
+		decreaseRatio float64
+		clock         timex.Clock
+	}
+)
+
+// NewAdaptiveLimiter 创建自适应并发限制, 初始并发数为initial, 调整范围为 [minLimit, maxLimit]
+func NewAdaptiveLimiter(initial, minLimit, maxLimit int, opts ...AdaptiveLimiterOption) *AdaptiveLimiter {
+	if minLimit < 1 || maxLimit < minLimit {
+		panic("limits must satisfy 1 <= minLimit <= maxLimit")
+	}
+	if initial < minLimit || initial > maxLimit {
+		panic("initial must be in [minLimit, maxLimit]")
+	}
+
+	options := adaptiveLimiterOptions{
+		buckets:       defaultLimiterBuckets,
+		interval:      defaultLimiterInterval,
+		successRatio:  defaultLimiterSuccessRatio,
+		decreaseRatio: defaultLimiterDecreaseRatio,
+		clock:         timex.RealClock{},
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return &AdaptiveLimiter{
+		limit:    float64(initial),
+		minLimit: minLimit,
+		maxLimit: maxLimit,
+		latency: NewRollingWindow(options.buckets, options.interval,
+			WithWindowClock(options.clock)),
+		results: NewRollingWindow(options.buckets, options.interval,
+			WithWindowClock(options.clock)),
+		lastDecrease: options.clock.Now() - options.interval,
+		options:      options,
+	}
+}
+
+// Acquire 获取一个并发名额, 达到当前的并发限制时返回false
+// 获取成功时, 请求结束之后必须调用一次release, err为nil表示请求成功
+func (l *AdaptiveLimiter) Acquire() (release func(err error), ok bool) {
+	l.lock.Lock()
+	if l.inflight >= int(l.limit) {
+		l.lock.Unlock()
+		return nil, false
+	}
+	l.inflight++
+	l.lock.Unlock()
+
+	start := l.options.clock.Now()
+	return func(err error) {
+		l.release(l.options.clock.Since(start), err)
+	}, true
+}
+
+// Limit 返回当前的并发限制
+func (l *AdaptiveLimiter) Limit() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return int(l.limit)
+}
+
+// Inflight 返回正在执行的请求数
+func (l *AdaptiveLimiter) Inflight() int {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.inflight
+}
+
+func (l *AdaptiveLimiter) release(latency time.Duration, err error) {
+	l.latency.Add(float64(latency))
+	if err == nil {
+		l.results.Add(1)
+	} else {
+		l.results.Add(0)
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	// 并发数使用不到一半时增加并发数并不能说明下游能承受更多的请求
+	busy := l.inflight*2 >= int(l.limit)
+	l.inflight--
+	if l.overloaded() {
+		now := l.options.clock.Now()
+		if now-l.lastDecrease >= l.options.interval {
+			l.lastDecrease = now
+			l.limit = math.Max(float64(l.minLimit), l.limit*l.options.decreaseRatio)
+		}
+		return
+	}
+
+	if err == nil && busy {
+		l.limit = math.Min(float64(l.maxLimit), l.limit+1/l.limit)
+	}
+}
+
+func (l *AdaptiveLimiter) overloaded() bool {
+	if l.options.targetLatency > 0 && time.Duration(l.latency.Avg()) > l.options.targetLatency {
+		return true
+	}
+
+	sum, count := l.results.SumAndCount()
+	return count > 0 && sum/float64(count) < l.options.successRatio
+}
+
+// WithLimiterWindow 设置统计延迟和成功率的滑动窗口, 默认10个100ms的桶
+func WithLimiterWindow(buckets int, interval time.Duration) AdaptiveLimiterOption {
+	return func(opts *adaptiveLimiterOptions) {
+		opts.buckets = buckets
+		opts.interval = interval
+	}
+}
+
+// WithTargetLatency 设置目标延迟, 窗口内的平均延迟超过d时减少并发数, 默认不按延迟调整
+func WithTargetLatency(d time.Duration) AdaptiveLimiterOption {
+	if d <= 0 {
+		panic("target latency must be greater than 0")
+	}
+	return func(opts *adaptiveLimiterOptions) {
+		opts.targetLatency = d
+	}
+}
+
+// WithSuccessRatio 设置成功率阈值, 窗口内的成功率低于ratio时减少并发数, 默认0.9
+func WithSuccessRatio(ratio float64) AdaptiveLimiterOption {
+	if ratio < 0 || ratio > 1 {
+		panic("success ratio must be in [0, 1]")
+	}
+	return func(opts *adaptiveLimiterOptions) {
+		opts.successRatio = ratio
+	}
+}
+
+// WithDecreaseRatio 设置每次减少并发数时乘以的比例, 默认0.9
+func WithDecreaseRatio(ratio float64) AdaptiveLimiterOption {
+	if ratio <= 0 || ratio >= 1 {
+		panic("decrease ratio must be in (0, 1)")
+	}
+	return func(opts *adaptiveLimiterOptions) {
+		opts.decreaseRatio = ratio
+	}
+}
+
+// WithLimiterClock 设置使用的时钟
+func WithLimiterClock(clock timex.Clock) AdaptiveLimiterOption {
+	return func(opts *adaptiveLimiterOptions) {
+		opts.clock = clock
+	}
+}
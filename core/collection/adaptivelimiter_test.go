@@ -0,0 +1,131 @@
+package collection
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/timex"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 一轮请求: 用满当前的并发数, 每个请求耗时latency
+func runLimiterRound(l *AdaptiveLimiter, clock *timex.MockClock, latency time.Duration, err error) {
+	var releases []func(error)
+	for {
+		release, ok := l.Acquire()
+		if !ok {
+			break
+		}
+		releases = append(releases, release)
+	}
+	clock.Advance(latency)
+	for _, release := range releases {
+		release(err)
+	}
+}
+
+func TestAdaptiveLimiterAcquire(t *testing.T) {
+	l := NewAdaptiveLimiter(2, 1, 10)
+	r1, ok := l.Acquire()
+	assert.True(t, ok)
+	r2, ok := l.Acquire()
+	assert.True(t, ok)
+	_, ok = l.Acquire()
+	assert.False(t, ok)
+	assert.Equal(t, 2, l.Inflight())
+
+	r1(nil)
+	r2(nil)
+	assert.Equal(t, 0, l.Inflight())
+	_, ok = l.Acquire()
+	assert.True(t, ok)
+}
+
+func TestAdaptiveLimiterGrowAndShrink(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	l := NewAdaptiveLimiter(5, 2, 50, WithLimiterClock(clock), WithTargetLatency(time.Millisecond*100))
+
+	// 延迟正常时, 每一轮并发数大约加0.5
+	for i := 0; i < 40; i++ {
+		runLimiterRound(l, clock, time.Millisecond*10, nil)
+	}
+	grown := l.Limit()
+	assert.Greater(t, grown, 20)
+	assert.LessOrEqual(t, grown, 50)
+
+	// 延迟上升之后, 窗口内的平均延迟超过目标, 并发数逐渐减少到最小值
+	var limits []int
+	for i := 0; i < 30; i++ {
+		runLimiterRound(l, clock, time.Millisecond*300, nil)
+		limits = append(limits, l.Limit())
+	}
+	assert.Less(t, limits[5], grown)
+	for i := 6; i < len(limits); i++ {
+		assert.LessOrEqual(t, limits[i], limits[i-1])
+	}
+	assert.Equal(t, 2, l.Limit())
+}
+
+func TestAdaptiveLimiterFailures(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	l := NewAdaptiveLimiter(20, 1, 50, WithLimiterClock(clock))
+	errDown := errors.New("down")
+	for i := 0; i < 5; i++ {
+		runLimiterRound(l, clock, time.Millisecond*100, errDown)
+	}
+	// 每个桶的时长内最多减少一次, 20 * 0.9^5
+	assert.Equal(t, 11, l.Limit())
+}
+
+func TestAdaptiveLimiterIdleNotGrow(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	l := NewAdaptiveLimiter(5, 1, 50, WithLimiterClock(clock))
+	for i := 0; i < 100; i++ {
+		release, ok := l.Acquire()
+		assert.True(t, ok)
+		clock.Advance(time.Millisecond)
+		release(nil)
+	}
+	assert.Equal(t, 5, l.Limit())
+}
+
+func TestNewAdaptiveLimiterInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		NewAdaptiveLimiter(1, 0, 10)
+	})
+	assert.Panics(t, func() {
+		NewAdaptiveLimiter(5, 10, 1)
+	})
+	assert.Panics(t, func() {
+		NewAdaptiveLimiter(20, 1, 10)
+	})
+	assert.Panics(t, func() {
+		WithDecreaseRatio(1)
+	})
+	assert.Panics(t, func() {
+		WithSuccessRatio(1.5)
+	})
+	assert.Panics(t, func() {
+		WithTargetLatency(0)
+	})
+}
+
+func TestAdaptiveLimiterConcurrent(t *testing.T) {
+	l := NewAdaptiveLimiter(10, 1, 100)
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				if release, ok := l.Acquire(); ok {
+					assert.LessOrEqual(t, l.Inflight(), 100)
+					release(nil)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 0, l.Inflight())
+}
@@ -0,0 +1,247 @@
+package collection
+
+import (
+	"container/list"
+	"go-zero-/core/syncx"
+	"go-zero-/core/timex"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// 带过期时间的内存缓存
+// 超出容量限制时按LRU淘汰, 过期数据由时间轮主动删除, 同时在访问时惰性删除
+// 时间轮的操作都在释放锁之后进行, 避免持有锁等待时间轮的goroutine
+
+const (
+	// 时间轮的槽位数, 默认过期时间转一圈
+	cacheSlots = 300
+	// 时间轮的最小刻度, 避免过期时间很短时ticker过于频繁
+	minCacheTick = time.Millisecond
+)
+
+type (
+	// Cache 每个缓存都有一个时间轮的goroutine, 不再使用时必须调用 Close
+	Cache struct {
+		lock sync.Mutex
+		// 默认过期时间
+		expire time.Duration
+		// 最大容量, 0表示不限制
+		limit int
+		data  map[string]*list.Element
+		// 链表头部为最近访问的数据
+		lru   *list.List
+		clock timex.Clock
+		// 到期时主动删除数据, 删除数据时不移除定时任务, 到期时发现数据不存在直接忽略
+		// 这样释放锁之后再操作时间轮, 也不会因为并发的删除和写入丢失数据的定时任务
+		timingWheel *TimingWheel
+		// 合并 Take 中相同key的并发加载
+		barrier syncx.SingleFlight
+		hits    uint64
+		misses  uint64
+	}
+
+	CacheOption func(cache *Cache)
+
+	// CacheStats 缓存的命中统计, 用于上报
+	CacheStats struct {
+		Hits   uint64
+		Misses uint64
+	}
+
+	cacheEntry struct {
+		key      string
+		value    any
+		expireAt time.Duration
+	}
+)
+
+func NewCache(expire time.Duration, opts ...CacheOption) *Cache {
+	if expire <= 0 {
+		panic("expire must be greater than 0")
+	}
+
+	c := &Cache{
+		expire:  expire,
+		data:    make(map[string]*list.Element),
+		lru:     list.New(),
+		clock:   timex.RealClock{},
+		barrier: syncx.NewSingleFlight(),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	tick := expire / cacheSlots
+	if tick < minCacheTick {
+		tick = minCacheTick
+	}
+	// 参数都已经校验过, 不会返回错误
+	c.timingWheel, _ = NewTimingWheel(tick, cacheSlots, c.onExpire)
+	return c
+}
+
+// WithLimit 设置缓存的最大容量
+func WithLimit(limit int) CacheOption {
+	return func(cache *Cache) {
+		cache.limit = limit
+	}
+}
+
+// WithCacheClock 设置缓存使用的时钟
+func WithCacheClock(clock timex.Clock) CacheOption {
+	return func(cache *Cache) {
+		cache.clock = clock
+	}
+}
+
+// Set 写入数据, 使用默认的过期时间
+func (c *Cache) Set(key string, value any) {
+	c.SetWithExpire(key, value, c.expire)
+}
+
+// SetWithExpire 写入数据并指定过期时间
+func (c *Cache) SetWithExpire(key string, value any, expire time.Duration) {
+	c.lock.Lock()
+	c.set(key, value, expire)
+	c.lock.Unlock()
+
+	// key已有定时任务时覆盖, 时间轮已经停止时只剩惰性删除
+	_ = c.timingWheel.SetTimer(key, nil, expire)
+}
+
+// 调用方需要持有锁
+func (c *Cache) set(key string, value any, expire time.Duration) {
+	now := c.clock.Now()
+	if elem, ok := c.data[key]; ok {
+		entry := elem.Value.(*cacheEntry)
+		entry.value = value
+		entry.expireAt = now + expire
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	c.data[key] = c.lru.PushFront(&cacheEntry{
+		key:      key,
+		value:    value,
+		expireAt: now + expire,
+	})
+	if c.limit > 0 && c.lru.Len() > c.limit {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+// Get 读取数据, 过期的数据视为不存在
+func (c *Cache) Get(key string) (any, bool) {
+	c.lock.Lock()
+	value, ok := c.get(key)
+	c.lock.Unlock()
+
+	if ok {
+		atomic.AddUint64(&c.hits, 1)
+	} else {
+		atomic.AddUint64(&c.misses, 1)
+	}
+	return value, ok
+}
+
+// Take 读取数据, 不存在时调用fetch加载并使用默认的过期时间写入缓存
+// 同一个key同时只有一个fetch在执行, 其它调用等待并共享其结果, fetch返回错误时不写入缓存
+func (c *Cache) Take(key string, fetch func() (any, error)) (any, error) {
+	if value, ok := c.Get(key); ok {
+		return value, nil
+	}
+
+	return c.barrier.Do(key, func() (any, error) {
+		// 等待期间其它调用可能已经写入
+		c.lock.Lock()
+		value, ok := c.get(key)
+		c.lock.Unlock()
+		if ok {
+			return value, nil
+		}
+
+		value, err := fetch()
+		if err != nil {
+			return nil, err
+		}
+
+		c.Set(key, value)
+		return value, nil
+	})
+}
+
+// Del 删除数据
+func (c *Cache) Del(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if elem, ok := c.data[key]; ok {
+		c.removeElement(elem)
+	}
+}
+
+// Stats 返回命中统计, Take 按其中的 Get 统计
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{
+		Hits:   atomic.LoadUint64(&c.hits),
+		Misses: atomic.LoadUint64(&c.misses),
+	}
+}
+
+// Close 停止时间轮, 之后过期数据只在访问时删除
+func (c *Cache) Close() error {
+	c.timingWheel.Stop()
+	return nil
+}
+
+// 调用方需要持有锁, 不计入命中统计
+func (c *Cache) get(key string) (any, bool) {
+	elem, ok := c.data[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := elem.Value.(*cacheEntry)
+	if entry.expireAt <= c.clock.Now() {
+		c.removeElement(elem)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(elem)
+	return entry.value, true
+}
+
+// 时间轮到期回调, 按缓存的时钟确认已经过期再删除
+func (c *Cache) onExpire(key, _ any) {
+	c.lock.Lock()
+	remain, ok := c.expireLocked(key.(string))
+	c.lock.Unlock()
+
+	// 缓存的时钟与时间轮的ticker不一致时, 例如测试中使用 MockClock, 等到按缓存的时钟过期时再删除
+	// 与此同时写入的新定时任务可能被覆盖, 提前到期时会再次走到这里重新计算
+	if ok {
+		_ = c.timingWheel.SetTimer(key, nil, remain)
+	}
+}
+
+// 调用方需要持有锁, 数据还没有过期时返回剩余时间和true, 否则删除数据
+func (c *Cache) expireLocked(key string) (time.Duration, bool) {
+	elem, ok := c.data[key]
+	if !ok {
+		return 0, false
+	}
+
+	if remain := elem.Value.(*cacheEntry).expireAt - c.clock.Now(); remain > 0 {
+		return remain, true
+	}
+
+	c.removeElement(elem)
+	return 0, false
+}
+
+// 调用方需要持有锁, 不移除时间轮中的定时任务
+func (c *Cache) removeElement(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.data, elem.Value.(*cacheEntry).key)
+}
@@ -0,0 +1,280 @@
+package collection
+
+import (
+	"fmt"
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/timex"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacheExpire(t *testing.T) {
+	c := NewCache(time.Millisecond * 30)
+	defer c.Close()
+	c.Set("a", 1)
+	c.SetWithExpire("b", 2, time.Second)
+
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+
+	time.Sleep(time.Millisecond * 50)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	v, ok = c.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+}
+
+func TestCacheWithClock(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	c := NewCache(time.Minute, WithCacheClock(clock))
+	defer c.Close()
+	c.Set("a", 1)
+
+	clock.Advance(time.Minute - time.Second)
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+
+	clock.Advance(time.Second)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestCacheLRU(t *testing.T) {
+	c := NewCache(time.Minute, WithLimit(3))
+	defer c.Close()
+	c.Set("a", 1)
+	c.Set("b", 2)
+	c.Set("c", 3)
+	// 访问a之后, b成为最久未访问的数据
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+	c.Set("d", 4)
+
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	for _, key := range []string{"a", "c", "d"} {
+		_, ok = c.Get(key)
+		assert.True(t, ok, key)
+	}
+
+	// 覆盖写入同样会刷新访问顺序
+	c.Set("a", 10)
+	c.Set("e", 5)
+	_, ok = c.Get("c")
+	assert.False(t, ok)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 10, v)
+}
+
+func TestCacheDel(t *testing.T) {
+	c := NewCache(time.Minute)
+	defer c.Close()
+	c.Set("a", 1)
+	c.Del("a")
+	c.Del("not-exist")
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	c := NewCache(time.Minute, WithLimit(100))
+	defer c.Close()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				key := fmt.Sprintf("key-%d", (i*1000+j)%200)
+				c.Set(key, j)
+				c.Get(key)
+				if j%10 == 0 {
+					c.Del(key)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, c.lru.Len(), 100)
+	assert.Equal(t, c.lru.Len(), len(c.data))
+}
+
+func cacheSize(c *Cache) int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return len(c.data)
+}
+
+func TestCacheActiveExpire(t *testing.T) {
+	c := NewCache(time.Millisecond * 20)
+	defer c.Close()
+	for i := 0; i < 10; i++ {
+		c.Set(fmt.Sprintf("key-%d", i), i)
+	}
+	c.SetWithExpire("long", 1, time.Minute)
+
+	// 不需要访问和写入, 时间轮到期后主动删除
+	assert.Eventually(t, func() bool {
+		return cacheSize(c) == 1
+	}, time.Second, time.Millisecond*5)
+	v, ok := c.Get("long")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+}
+
+func TestCacheActiveExpireWithClock(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	c := NewCache(time.Millisecond*10, WithCacheClock(clock))
+	defer c.Close()
+	c.Set("a", 1)
+
+	// 按缓存的时钟还没有过期, 时间轮到期时不删除
+	time.Sleep(time.Millisecond * 50)
+	assert.Equal(t, 1, cacheSize(c))
+
+	clock.Advance(time.Millisecond * 10)
+	assert.Eventually(t, func() bool {
+		return cacheSize(c) == 0
+	}, time.Second, time.Millisecond*5)
+}
+
+func TestCacheEvictionOrder(t *testing.T) {
+	c := NewCache(time.Minute, WithLimit(3))
+	defer c.Close()
+	for _, key := range []string{"a", "b", "c"} {
+		c.Set(key, key)
+	}
+	// 访问顺序从旧到新为 b c a
+	c.Get("a")
+
+	var evicted []string
+	for _, key := range []string{"d", "e", "f"} {
+		before := make(map[string]bool)
+		for k := range c.data {
+			before[k] = true
+		}
+		c.Set(key, key)
+		for k := range before {
+			if _, ok := c.data[k]; !ok {
+				evicted = append(evicted, k)
+			}
+		}
+	}
+	assert.Equal(t, []string{"b", "c", "a"}, evicted)
+	assert.Equal(t, 3, cacheSize(c))
+}
+
+func TestCacheTake(t *testing.T) {
+	c := NewCache(time.Minute)
+	defer c.Close()
+
+	const total = 100
+	var fetched int32
+	release := make(chan struct{})
+	var started, done sync.WaitGroup
+	values := make([]any, total)
+	for i := 0; i < total; i++ {
+		started.Add(1)
+		done.Add(1)
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			v, err := c.Take("user", func() (any, error) {
+				atomic.AddInt32(&fetched, 1)
+				<-release
+				return "alice", nil
+			})
+			assert.Nil(t, err)
+			values[i] = v
+		}(i)
+	}
+	started.Wait()
+	time.Sleep(time.Millisecond * 50)
+	close(release)
+	done.Wait()
+
+	// 并发的加载只执行一次, 结果共享并写入缓存
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetched))
+	for _, v := range values {
+		assert.Equal(t, "alice", v)
+	}
+	v, err := c.Take("user", func() (any, error) {
+		t.Fatal("should hit cache")
+		return nil, nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "alice", v)
+}
+
+func TestCacheTakeError(t *testing.T) {
+	c := NewCache(time.Minute)
+	defer c.Close()
+
+	errFetch := fmt.Errorf("db down")
+	_, err := c.Take("user", func() (any, error) {
+		return nil, errFetch
+	})
+	assert.Equal(t, errFetch, err)
+	// 加载失败不写入缓存
+	_, ok := c.Get("user")
+	assert.False(t, ok)
+}
+
+func TestCacheStats(t *testing.T) {
+	c := NewCache(time.Minute)
+	defer c.Close()
+
+	c.Set("a", 1)
+	c.Get("a")
+	c.Get("a")
+	c.Get("b")
+	_, _ = c.Take("c", func() (any, error) {
+		return 3, nil
+	})
+	_, _ = c.Take("c", func() (any, error) {
+		return 3, nil
+	})
+	assert.Equal(t, CacheStats{Hits: 3, Misses: 2}, c.Stats())
+}
+
+func TestCacheClose(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	c := NewCache(time.Minute, WithCacheClock(clock))
+	assert.Nil(t, c.Close())
+
+	// 时间轮停止后仍然可以读写, 过期数据在访问时删除
+	c.Set("a", 1)
+	v, ok := c.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	clock.Advance(time.Minute)
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+}
+
+func TestCacheDelThenSet(t *testing.T) {
+	c := NewCache(time.Millisecond * 20)
+	defer c.Close()
+
+	// 删除时不移除定时任务, 重新写入之后仍然会被主动删除
+	c.Set("a", 1)
+	c.Del("a")
+	c.Set("a", 2)
+	assert.Eventually(t, func() bool {
+		return cacheSize(c) == 0
+	}, time.Second, time.Millisecond*5)
+
+	// 被LRU淘汰的数据到期时直接忽略
+	limited := NewCache(time.Millisecond*20, WithLimit(1))
+	defer limited.Close()
+	limited.Set("a", 1)
+	limited.Set("b", 2)
+	assert.Eventually(t, func() bool {
+		return cacheSize(limited) == 0
+	}, time.Second, time.Millisecond*5)
+}
@@ -0,0 +1,21 @@
+package collection
+
+import "time"
+
+// 计数滑动窗口
+// 绝大多数场景只是用 rw.Add(1) 计数, 这里直接基于 Bucket.Count 统计, 无需经过 float64 的 Sum
+
+type CountingRollingWindow struct {
+	*RollingWindow
+}
+
+func NewCountingRollingWindow(size int, interval time.Duration, opts ...RollingWindowOption) *CountingRollingWindow {
+	return &CountingRollingWindow{
+		RollingWindow: NewRollingWindow(size, interval, opts...),
+	}
+}
+
+// Increment 计数加1
+func (cw *CountingRollingWindow) Increment() {
+	cw.Add(1)
+}
@@ -0,0 +1,67 @@
+package collection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/timex"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCountingRollingWindowIncrement(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	cw := NewCountingRollingWindow(3, time.Second, WithWindowClock(clock))
+	assert.Equal(t, int64(0), cw.Count())
+
+	for i := 0; i < 5; i++ {
+		cw.Increment()
+	}
+	clock.Advance(time.Second)
+	cw.Increment()
+	assert.Equal(t, int64(6), cw.Count())
+	// 每次计数都记为1
+	assert.Equal(t, 6.0, cw.Sum())
+}
+
+func TestCountingRollingWindowExpire(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	cw := NewCountingRollingWindow(3, time.Second, WithWindowClock(clock))
+	for i := 0; i < 3; i++ {
+		for j := 0; j <= i; j++ {
+			cw.Increment()
+		}
+		clock.Advance(time.Second)
+	}
+	// 三个桶分别为 1 2 3, 当前桶已经滑到第一个桶的位置并被清空
+	assert.Equal(t, int64(5), cw.Count())
+	clock.Advance(time.Second)
+	assert.Equal(t, int64(3), cw.Count())
+	clock.Advance(time.Second * 3)
+	assert.Equal(t, int64(0), cw.Count())
+}
+
+func TestCountingRollingWindowIgnoreCurrent(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	cw := NewCountingRollingWindow(3, time.Second, IgnoreCurrentBucket(), WithWindowClock(clock))
+	cw.Increment()
+	assert.Equal(t, int64(0), cw.Count())
+	clock.Advance(time.Second)
+	cw.Increment()
+	assert.Equal(t, int64(1), cw.Count())
+}
+
+func TestCountingRollingWindowConcurrent(t *testing.T) {
+	cw := NewCountingRollingWindow(10, time.Minute)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				cw.Increment()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int64(10000), cw.Count())
+}
@@ -0,0 +1,68 @@
+package collection
+
+import "sync"
+
+// DistinctQueue 去重的有界先进先出队列, 队列中已经存在的元素不会重复加入, 取出之后可以再次加入
+// 例如合并同一个熔断器短时间内的多次告警, 按首次出现的顺序批量发送
+type DistinctQueue[T comparable] struct {
+	lock sync.Mutex
+	// 环形缓冲区, head 为队首位置, count 为元素个数
+	buf   []T
+	head  int
+	count int
+	// 队列中已有的元素, 用于O(1)去重
+	present *Set[T]
+}
+
+// NewDistinctQueue 创建容量为capacity的去重队列
+func NewDistinctQueue[T comparable](capacity int) *DistinctQueue[T] {
+	if capacity <= 0 {
+		panic("capacity must be greater than 0")
+	}
+
+	return &DistinctQueue[T]{
+		buf:     make([]T, capacity),
+		present: NewSet[T](),
+	}
+}
+
+// Push 加入元素, 元素已经在队列中或者队列已满时返回false
+func (q *DistinctQueue[T]) Push(v T) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.count == len(q.buf) || q.present.Contains(v) {
+		return false
+	}
+
+	q.buf[(q.head+q.count)%len(q.buf)] = v
+	q.count++
+	q.present.Add(v)
+	return true
+}
+
+// Pop 取出队首元素, 队列为空时返回false
+func (q *DistinctQueue[T]) Pop() (T, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	var zero T
+	if q.count == 0 {
+		return zero, false
+	}
+
+	v := q.buf[q.head]
+	q.buf[q.head] = zero
+	q.head = (q.head + 1) % len(q.buf)
+	q.count--
+	q.present.Remove(v)
+	return v, true
+}
+
+// Len 返回队列中的元素个数
+func (q *DistinctQueue[T]) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.count
+}
@@ -0,0 +1,88 @@
+package collection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestDistinctQueueDedup(t *testing.T) {
+	q := NewDistinctQueue[string](4)
+	assert.True(t, q.Push("a"))
+	assert.True(t, q.Push("b"))
+	assert.False(t, q.Push("a"))
+	assert.False(t, q.Push("b"))
+	assert.Equal(t, 2, q.Len())
+
+	// 取出之后可以再次加入
+	v, ok := q.Pop()
+	assert.True(t, ok)
+	assert.Equal(t, "a", v)
+	assert.True(t, q.Push("a"))
+	assert.False(t, q.Push("a"))
+	assert.Equal(t, 2, q.Len())
+}
+
+func TestDistinctQueueCapacity(t *testing.T) {
+	q := NewDistinctQueue[int](2)
+	assert.True(t, q.Push(1))
+	assert.True(t, q.Push(2))
+	assert.False(t, q.Push(3))
+	assert.Equal(t, 2, q.Len())
+
+	// 队列满时被拒绝的元素没有记录, 有空位之后可以加入
+	_, _ = q.Pop()
+	assert.True(t, q.Push(3))
+	assert.False(t, q.Push(4))
+
+	assert.Panics(t, func() {
+		NewDistinctQueue[int](0)
+	})
+}
+
+func TestDistinctQueueFIFO(t *testing.T) {
+	q := NewDistinctQueue[int](3)
+	_, ok := q.Pop()
+	assert.False(t, ok)
+
+	// 多次绕过环形缓冲区的末尾
+	var popped []int
+	for i := 0; i < 10; i++ {
+		assert.True(t, q.Push(i))
+		assert.False(t, q.Push(i))
+		if q.Len() == 3 {
+			v, _ := q.Pop()
+			popped = append(popped, v)
+		}
+	}
+	for q.Len() > 0 {
+		v, _ := q.Pop()
+		popped = append(popped, v)
+	}
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, popped)
+}
+
+func TestDistinctQueueConcurrent(t *testing.T) {
+	q := NewDistinctQueue[int](100)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				q.Push(j)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// 每个元素只加入一次
+	assert.Equal(t, 100, q.Len())
+	seen := NewSet[int]()
+	for i := 0; i < 100; i++ {
+		v, ok := q.Pop()
+		assert.True(t, ok)
+		assert.False(t, seen.Contains(v))
+		seen.Add(v)
+	}
+}
@@ -0,0 +1,28 @@
+package collection
+
+import "go-zero-/core/mathx"
+
+// 指数加权移动平均, 比滑动窗口更轻量, 适用于平滑延迟等读数
+// value = alpha*v + (1-alpha)*value, alpha越大越偏向最新的数据
+
+// EWMA 基于 mathx.EMA 实现, 无锁并发安全
+type EWMA struct {
+	ema *mathx.EMA
+}
+
+// NewEWMA 创建移动平均, alpha取值范围 (0, 1]
+func NewEWMA(alpha float64) *EWMA {
+	return &EWMA{
+		ema: mathx.NewEMA(alpha),
+	}
+}
+
+// Add 加入一个新的数据, 第一次Add直接使用该值, 避免和0混合
+func (e *EWMA) Add(v float64) {
+	e.ema.Add(v)
+}
+
+// Value 返回当前的平均值, 没有数据时返回0
+func (e *EWMA) Value() float64 {
+	return e.ema.Value()
+}
@@ -0,0 +1,57 @@
+package collection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"sync"
+	"testing"
+)
+
+func TestEWMAInvalidAlpha(t *testing.T) {
+	assert.Panics(t, func() {
+		NewEWMA(0)
+	})
+	assert.Panics(t, func() {
+		NewEWMA(1.1)
+	})
+	assert.NotPanics(t, func() {
+		NewEWMA(1)
+	})
+}
+
+func TestEWMAFirstValue(t *testing.T) {
+	e := NewEWMA(0.1)
+	assert.Equal(t, 0.0, e.Value())
+	e.Add(100)
+	assert.Equal(t, 100.0, e.Value())
+}
+
+func TestEWMAStep(t *testing.T) {
+	const alpha = 0.2
+	e := NewEWMA(alpha)
+	e.Add(0)
+
+	// 阶跃输入, 第n次之后与目标值的差距为 (1-alpha)^n
+	for n := 1; n <= 20; n++ {
+		e.Add(100)
+		assert.InDelta(t, 100-100*math.Pow(1-alpha, float64(n)), e.Value(), 1e-9)
+	}
+	assert.InDelta(t, 100, e.Value(), 1.2)
+}
+
+func TestEWMAConcurrent(t *testing.T) {
+	e := NewEWMA(0.5)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				e.Add(10)
+				_ = e.Value()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 10.0, e.Value())
+}
@@ -0,0 +1,34 @@
+package collection
+
+import "sync"
+
+// Pool 带类型的 sync.Pool, 调用方不需要自己做类型断言
+// 与 sync.Pool 一样, 池中的对象随时可能被GC回收, T 建议使用指针类型, 否则 Put 时仍然会装箱分配内存
+type Pool[T any] struct {
+	pool sync.Pool
+}
+
+// NewPool 创建对象池, 池为空时使用create创建新的对象
+func NewPool[T any](create func() T) *Pool[T] {
+	if create == nil {
+		panic("create must not be nil")
+	}
+
+	return &Pool[T]{
+		pool: sync.Pool{
+			New: func() any {
+				return create()
+			},
+		},
+	}
+}
+
+// Get 从池中取出一个对象, 池为空时创建新的对象
+func (p *Pool[T]) Get() T {
+	return p.pool.Get().(T)
+}
+
+// Put 将对象放回池中, 调用方需要先重置对象的状态
+func (p *Pool[T]) Put(x T) {
+	p.pool.Put(x)
+}
@@ -0,0 +1,66 @@
+package collection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type pooledBuffer struct {
+	id  int
+	buf []byte
+}
+
+func TestPoolGetFromFactory(t *testing.T) {
+	var created int
+	p := NewPool(func() *pooledBuffer {
+		created++
+		return &pooledBuffer{
+			id: created,
+		}
+	})
+
+	first := p.Get()
+	second := p.Get()
+	assert.Equal(t, 1, first.id)
+	assert.Equal(t, 2, second.id)
+	assert.Equal(t, 2, created)
+}
+
+func TestPoolReuse(t *testing.T) {
+	p := NewPool(func() *pooledBuffer {
+		return &pooledBuffer{
+			buf: make([]byte, 0, 64),
+		}
+	})
+
+	// sync.Pool 不保证一定能取回放入的对象, 例如开启-race时会随机丢弃, 多试几次
+	var reused bool
+	for i := 0; i < 100 && !reused; i++ {
+		b := p.Get()
+		b.buf = append(b.buf[:0], "hello"...)
+		p.Put(b)
+		reused = p.Get() == b
+	}
+	assert.True(t, reused)
+}
+
+func TestPoolNilFactory(t *testing.T) {
+	assert.Panics(t, func() {
+		NewPool[*pooledBuffer](nil)
+	})
+}
+
+func BenchmarkPool(b *testing.B) {
+	p := NewPool(func() *pooledBuffer {
+		return &pooledBuffer{
+			buf: make([]byte, 0, 64),
+		}
+	})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf := p.Get()
+		buf.buf = append(buf.buf[:0], "hello"...)
+		p.Put(buf)
+	}
+}
@@ -0,0 +1,151 @@
+package collection
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// 有界阻塞队列
+// 基于环形缓冲区和 sync.Cond 实现, 队列满时 Put 阻塞, 队列空时 Take 阻塞
+
+var ErrQueueClosed = errors.New("queue is closed")
+
+// Queue 有界阻塞队列, 用于生产者消费者模型
+// Close 之后不再接收新的元素, 已经在队列中的元素仍然可以取出, 取完之后 Take 才报告已关闭
+type Queue[T any] struct {
+	lock     sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	// 环形缓冲区, head 为队首位置, count 为元素个数
+	buf    []T
+	head   int
+	count  int
+	closed bool
+}
+
+// NewQueue 创建容量为capacity的队列
+func NewQueue[T any](capacity int) *Queue[T] {
+	if capacity <= 0 {
+		panic("capacity must be greater than 0")
+	}
+
+	q := &Queue[T]{
+		buf: make([]T, capacity),
+	}
+	q.notEmpty = sync.NewCond(&q.lock)
+	q.notFull = sync.NewCond(&q.lock)
+	return q
+}
+
+// Put 放入元素, 队列满时阻塞直到有空位, 队列已关闭时丢弃v
+func (q *Queue[T]) Put(v T) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for q.count == len(q.buf) && !q.closed {
+		q.notFull.Wait()
+	}
+	if q.closed {
+		return
+	}
+
+	q.push(v)
+}
+
+// TryPut 尝试放入元素, 队列满或者已关闭时返回false
+func (q *Queue[T]) TryPut(v T) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed || q.count == len(q.buf) {
+		return false
+	}
+
+	q.push(v)
+	return true
+}
+
+// Take 取出队首元素, 队列空时阻塞, 队列已关闭并且已经取完时返回false
+func (q *Queue[T]) Take() (T, bool) {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for q.count == 0 && !q.closed {
+		q.notEmpty.Wait()
+	}
+	if q.count == 0 {
+		var zero T
+		return zero, false
+	}
+
+	return q.pop(), true
+}
+
+// TakeWithContext 同 Take, ctx结束时返回 ctx.Err(), 队列已关闭并且已经取完时返回 ErrQueueClosed
+func (q *Queue[T]) TakeWithContext(ctx context.Context) (T, error) {
+	// ctx结束时唤醒所有等待者, 由各自检查自己的ctx, 加锁保证不会在检查之后、Wait之前错过唤醒
+	stop := context.AfterFunc(ctx, func() {
+		q.lock.Lock()
+		q.notEmpty.Broadcast()
+		q.lock.Unlock()
+	})
+	defer stop()
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	var zero T
+	for q.count == 0 && !q.closed {
+		if err := ctx.Err(); err != nil {
+			return zero, err
+		}
+		q.notEmpty.Wait()
+	}
+	if q.count == 0 {
+		return zero, ErrQueueClosed
+	}
+
+	return q.pop(), nil
+}
+
+// Len 返回队列中的元素个数
+func (q *Queue[T]) Len() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	return q.count
+}
+
+// Close 关闭队列并唤醒所有等待者, 重复调用无副作用
+func (q *Queue[T]) Close() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	if q.closed {
+		return
+	}
+
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+}
+
+// 调用方需要持有锁并保证队列未满
+func (q *Queue[T]) push(v T) {
+	q.buf[(q.head+q.count)%len(q.buf)] = v
+	q.count++
+	q.notEmpty.Signal()
+}
+
+// 调用方需要持有锁并保证队列不为空
+func (q *Queue[T]) pop() T {
+	var zero T
+	v := q.buf[q.head]
+	// 释放引用, 避免元素迟迟不能被GC回收
+	q.buf[q.head] = zero
+	q.head = (q.head + 1) % len(q.buf)
+	q.count--
+	q.notFull.Signal()
+	return v
+}
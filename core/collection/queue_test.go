@@ -0,0 +1,224 @@
+package collection
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQueueFIFO(t *testing.T) {
+	q := NewQueue[int](3)
+	// 多次绕过环形缓冲区的末尾
+	for round := 0; round < 3; round++ {
+		for i := 0; i < 3; i++ {
+			assert.True(t, q.TryPut(round*10+i))
+		}
+		assert.False(t, q.TryPut(-1))
+		assert.Equal(t, 3, q.Len())
+		for i := 0; i < 3; i++ {
+			v, ok := q.Take()
+			assert.True(t, ok)
+			assert.Equal(t, round*10+i, v)
+		}
+		assert.Equal(t, 0, q.Len())
+	}
+}
+
+func TestQueuePutBlocksWhenFull(t *testing.T) {
+	q := NewQueue[int](1)
+	q.Put(1)
+
+	done := make(chan struct{})
+	go func() {
+		q.Put(2)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("Put should block when queue is full")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	v, ok := q.Take()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	<-done
+	v, ok = q.Take()
+	assert.True(t, ok)
+	assert.Equal(t, 2, v)
+}
+
+func TestQueueTakeBlocksWhenEmpty(t *testing.T) {
+	q := NewQueue[string](1)
+	result := make(chan string)
+	go func() {
+		v, _ := q.Take()
+		result <- v
+	}()
+
+	select {
+	case <-result:
+		t.Fatal("Take should block when queue is empty")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	q.Put("hello")
+	assert.Equal(t, "hello", <-result)
+}
+
+func TestQueueTakeWithContext(t *testing.T) {
+	q := NewQueue[int](2)
+	q.Put(1)
+	v, err := q.TakeWithContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, v)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	start := time.Now()
+	_, err = q.TakeWithContext(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < time.Second)
+
+	// 已经结束的ctx, 队列中有数据时仍然返回数据
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = q.TakeWithContext(canceled)
+	assert.Equal(t, context.Canceled, err)
+	q.Put(2)
+	v, err = q.TakeWithContext(canceled)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, v)
+}
+
+func TestQueueCloseWhileWaiting(t *testing.T) {
+	q := NewQueue[int](1)
+	var wg sync.WaitGroup
+	var takeClosed, ctxClosed int32
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			if _, ok := q.Take(); !ok {
+				atomic.AddInt32(&takeClosed, 1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			if _, err := q.TakeWithContext(context.Background()); err == ErrQueueClosed {
+				atomic.AddInt32(&ctxClosed, 1)
+			}
+		}()
+	}
+
+	time.Sleep(time.Millisecond * 50)
+	q.Close()
+	wg.Wait()
+	assert.Equal(t, int32(5), takeClosed)
+	assert.Equal(t, int32(5), ctxClosed)
+
+	// 所有等待中的 Put 也会被唤醒
+	full := NewQueue[int](1)
+	full.Put(1)
+	done := make(chan struct{})
+	go func() {
+		full.Put(2)
+		close(done)
+	}()
+	time.Sleep(time.Millisecond * 50)
+	full.Close()
+	<-done
+}
+
+func TestQueueCloseDrain(t *testing.T) {
+	q := NewQueue[int](3)
+	q.Put(1)
+	q.Put(2)
+	q.Close()
+	q.Close()
+
+	// 关闭之后不再接收新的元素
+	assert.False(t, q.TryPut(3))
+	q.Put(3)
+	assert.Equal(t, 2, q.Len())
+
+	v, ok := q.Take()
+	assert.True(t, ok)
+	assert.Equal(t, 1, v)
+	v, err := q.TakeWithContext(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, v)
+
+	_, ok = q.Take()
+	assert.False(t, ok)
+	_, err = q.TakeWithContext(context.Background())
+	assert.Equal(t, ErrQueueClosed, err)
+}
+
+func TestQueueConcurrent(t *testing.T) {
+	const (
+		producers = 8
+		consumers = 8
+		perWorker = 1000
+	)
+	q := NewQueue[int](16)
+
+	var producing sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		producing.Add(1)
+		go func(p int) {
+			defer producing.Done()
+			for i := 0; i < perWorker; i++ {
+				v := p*perWorker + i
+				if i%2 == 0 {
+					q.Put(v)
+				} else {
+					for !q.TryPut(v) {
+						time.Sleep(time.Microsecond)
+					}
+				}
+			}
+		}(p)
+	}
+
+	seen := make([]int32, producers*perWorker)
+	var consuming sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		consuming.Add(1)
+		go func(c int) {
+			defer consuming.Done()
+			for {
+				var v int
+				if c%2 == 0 {
+					var ok bool
+					if v, ok = q.Take(); !ok {
+						return
+					}
+				} else {
+					var err error
+					if v, err = q.TakeWithContext(context.Background()); err != nil {
+						return
+					}
+				}
+				atomic.AddInt32(&seen[v], 1)
+			}
+		}(c)
+	}
+
+	producing.Wait()
+	q.Close()
+	consuming.Wait()
+	for v, n := range seen {
+		assert.Equal(t, int32(1), n, "value %d", v)
+	}
+}
+
+func TestNewQueueInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		NewQueue[int](0)
+	})
+}
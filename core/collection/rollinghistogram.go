@@ -0,0 +1,176 @@
+package collection
+
+import (
+	"math"
+	"slices"
+	"time"
+)
+
+// 默认的分桶边界, 1ms到10s按指数分为64个区间
+const (
+	defaultHistogramMin  = time.Millisecond
+	defaultHistogramMax  = time.Second * 10
+	defaultHistogramBins = 64
+)
+
+type (
+	// RollingHistogram 滑动窗口内的耗时分布, 用于查询最近一段时间的p99等分位数
+	// 每个桶按固定的边界统计落在每个区间内的次数, 查询时合并有效的桶并在区间内线性插值
+	RollingHistogram struct {
+		win    *RollingWindowOf[*histogramBucket]
+		bounds []time.Duration
+	}
+
+	histogramBucket struct {
+		// 所有桶共享的区间上界, 第i个区间为 (bounds[i-1], bounds[i]], 最后一个区间没有上界
+		bounds []time.Duration
+		counts []int64
+		count  int64
+		// 用于最小和最大两个区间的插值
+		min, max time.Duration
+	}
+)
+
+// NewRollingHistogram 创建耗时分布, bounds为严格递增的区间上界, 为空时使用1ms到10s的指数区间
+func NewRollingHistogram(size int, interval time.Duration, bounds []time.Duration,
+	opts ...RollingWindowOption) *RollingHistogram {
+	if len(bounds) == 0 {
+		bounds = ExponentialBounds(defaultHistogramMin, defaultHistogramMax, defaultHistogramBins)
+	}
+	for i := 1; i < len(bounds); i++ {
+		if bounds[i] <= bounds[i-1] {
+			panic("bounds must be strictly increasing")
+		}
+	}
+	bounds = slices.Clone(bounds)
+
+	return &RollingHistogram{
+		win: NewRollingWindowOf(size, interval, func() *histogramBucket {
+			return &histogramBucket{
+				bounds: bounds,
+				counts: make([]int64, len(bounds)+1),
+			}
+		}, opts...),
+		bounds: bounds,
+	}
+}
+
+// ExponentialBounds 返回从min到max按指数增长的n个区间上界, 相邻边界的比例相同
+func ExponentialBounds(min, max time.Duration, n int) []time.Duration {
+	if min <= 0 || max <= min {
+		panic("bounds must satisfy 0 < min < max")
+	}
+	if n < 2 {
+		panic("n must be at least 2")
+	}
+
+	factor := math.Pow(float64(max)/float64(min), 1/float64(n-1))
+	bounds := make([]time.Duration, n)
+	for i := range bounds {
+		bounds[i] = time.Duration(math.Round(float64(min) * math.Pow(factor, float64(i))))
+	}
+	bounds[n-1] = max
+	return bounds
+}
+
+// Add 记录一次耗时, 小于0时按0记录
+func (h *RollingHistogram) Add(d time.Duration) {
+	if d < 0 {
+		d = 0
+	}
+	h.win.Add(float64(d))
+}
+
+// Count 返回窗口内记录的次数
+func (h *RollingHistogram) Count() int64 {
+	var count int64
+	h.win.Reduce(func(b *histogramBucket) {
+		count += b.count
+	})
+	return count
+}
+
+// Percentile 返回窗口内耗时的p分位数, p取值范围 [0, 1], 否则panic, 窗口内没有数据时返回0
+func (h *RollingHistogram) Percentile(p float64) time.Duration {
+	if !(p >= 0 && p <= 1) {
+		panic("p must be in [0, 1]")
+	}
+
+	merged := histogramBucket{
+		counts: make([]int64, len(h.bounds)+1),
+	}
+	h.win.Reduce(func(b *histogramBucket) {
+		merged.merge(b)
+	})
+	if merged.count == 0 {
+		return 0
+	}
+
+	rank := p * float64(merged.count)
+	var seen int64
+	for i, c := range merged.counts {
+		if c == 0 {
+			continue
+		}
+		if float64(seen+c) >= rank {
+			lower, upper := h.binRange(i, merged.min, merged.max)
+			frac := (rank - float64(seen)) / float64(c)
+			return lower + time.Duration(frac*float64(upper-lower))
+		}
+		seen += c
+	}
+	return merged.max
+}
+
+// 第i个区间的范围, 用实际的最小最大值收窄, 没有上界的最后一个区间也能插值
+func (h *RollingHistogram) binRange(i int, min, max time.Duration) (lower, upper time.Duration) {
+	lower = min
+	if i > 0 && h.bounds[i-1] > lower {
+		lower = h.bounds[i-1]
+	}
+	upper = max
+	if i < len(h.bounds) && h.bounds[i] < upper {
+		upper = h.bounds[i]
+	}
+	return lower, upper
+}
+
+func (b *histogramBucket) Add(v float64) {
+	d := time.Duration(v)
+	// 第一个不小于d的上界, 即d所在的区间
+	i, _ := slices.BinarySearch(b.bounds, d)
+	b.counts[i]++
+	if b.count == 0 || d < b.min {
+		b.min = d
+	}
+	if b.count == 0 || d > b.max {
+		b.max = d
+	}
+	b.count++
+}
+
+func (b *histogramBucket) Reset() {
+	for i := range b.counts {
+		b.counts[i] = 0
+	}
+	b.count = 0
+	b.min = 0
+	b.max = 0
+}
+
+func (b *histogramBucket) merge(other *histogramBucket) {
+	if other.count == 0 {
+		return
+	}
+
+	for i, c := range other.counts {
+		b.counts[i] += c
+	}
+	if b.count == 0 || other.min < b.min {
+		b.min = other.min
+	}
+	if b.count == 0 || other.max > b.max {
+		b.max = other.max
+	}
+	b.count += other.count
+}
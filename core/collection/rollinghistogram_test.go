@@ -0,0 +1,134 @@
+package collection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/mathx"
+	"go-zero-/core/timex"
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestRollingHistogramAccuracy(t *testing.T) {
+	// 对数正态分布的耗时, 中位数约20ms
+	r := rand.New(rand.NewSource(1))
+	samples := make([]float64, 10000)
+	h := NewRollingHistogram(10, time.Second, nil)
+	for i := range samples {
+		d := time.Duration(math.Exp(r.NormFloat64()+math.Log(20)) * float64(time.Millisecond))
+		samples[i] = float64(d)
+		h.Add(d)
+	}
+	sort.Float64s(samples)
+	assert.Equal(t, int64(len(samples)), h.Count())
+
+	// 相邻边界的比例约为1.158, 插值之后的误差不超过一个区间
+	for _, p := range []float64{0.5, 0.9, 0.95, 0.99, 0.999} {
+		expect := mathx.Percentile(samples, p)
+		actual := float64(h.Percentile(p))
+		assert.InEpsilon(t, expect, actual, 0.1, "p%v", p*100)
+	}
+	assert.Equal(t, time.Duration(samples[0]), h.Percentile(0))
+	assert.Equal(t, time.Duration(samples[len(samples)-1]), h.Percentile(1))
+}
+
+func TestRollingHistogramBounds(t *testing.T) {
+	bounds := []time.Duration{time.Millisecond * 10, time.Millisecond * 20, time.Millisecond * 40}
+	h := NewRollingHistogram(3, time.Second, bounds)
+	assert.Equal(t, time.Duration(0), h.Percentile(0.5))
+
+	// 每个区间各10个
+	for i := 0; i < 10; i++ {
+		h.Add(time.Millisecond * 5)
+		h.Add(time.Millisecond * 15)
+		h.Add(time.Millisecond * 30)
+		h.Add(time.Millisecond * 80)
+	}
+	assert.Equal(t, time.Millisecond*5, h.Percentile(0))
+	assert.Equal(t, time.Millisecond*10, h.Percentile(0.25))
+	assert.Equal(t, time.Millisecond*20, h.Percentile(0.5))
+	assert.Equal(t, time.Millisecond*30, h.Percentile(0.625))
+	assert.Equal(t, time.Millisecond*40, h.Percentile(0.75))
+	assert.Equal(t, time.Millisecond*80, h.Percentile(1))
+
+	// 修改传入的边界不影响已经创建的分布
+	bounds[0] = time.Hour
+	assert.Equal(t, time.Millisecond*10, h.Percentile(0.25))
+}
+
+func TestRollingHistogramExpire(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	h := NewRollingHistogram(3, time.Second, nil, WithWindowClock(clock))
+	for i := 0; i < 100; i++ {
+		h.Add(time.Second)
+	}
+	clock.Advance(time.Second)
+	for i := 0; i < 100; i++ {
+		h.Add(time.Millisecond)
+	}
+	assert.Equal(t, int64(200), h.Count())
+	assert.Equal(t, time.Millisecond, h.Percentile(0.4))
+	assert.Equal(t, time.Second, h.Percentile(1))
+
+	// 与 RollingWindow 一样, 过期的桶不参与统计
+	clock.Advance(time.Second * 2)
+	assert.Equal(t, int64(100), h.Count())
+	assert.Equal(t, time.Millisecond, h.Percentile(0.99))
+
+	clock.Advance(time.Second * 3)
+	assert.Equal(t, int64(0), h.Count())
+	assert.Equal(t, time.Duration(0), h.Percentile(0.99))
+}
+
+func TestRollingHistogramIgnoreCurrent(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	h := NewRollingHistogram(3, time.Second, nil, WithWindowClock(clock), IgnoreCurrentBucket())
+	h.Add(time.Millisecond * 100)
+	assert.Equal(t, int64(0), h.Count())
+	clock.Advance(time.Second)
+	h.Add(time.Second)
+	assert.Equal(t, int64(1), h.Count())
+	assert.Equal(t, time.Millisecond*100, h.Percentile(1))
+}
+
+func TestRollingHistogramInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		NewRollingHistogram(3, time.Second, []time.Duration{time.Second, time.Second})
+	})
+	assert.Panics(t, func() {
+		NewRollingHistogram(3, time.Second, nil).Percentile(1.1)
+	})
+	assert.Panics(t, func() {
+		ExponentialBounds(0, time.Second, 10)
+	})
+	assert.Panics(t, func() {
+		ExponentialBounds(time.Second, time.Second, 10)
+	})
+	assert.Panics(t, func() {
+		ExponentialBounds(time.Millisecond, time.Second, 1)
+	})
+}
+
+func TestExponentialBounds(t *testing.T) {
+	bounds := ExponentialBounds(time.Millisecond, time.Second, 4)
+	assert.Equal(t, []time.Duration{time.Millisecond, time.Millisecond * 10, time.Millisecond * 100, time.Second}, bounds)
+}
+
+func TestRollingHistogramAddNoAlloc(t *testing.T) {
+	h := NewRollingHistogram(10, time.Second, nil)
+	allocs := testing.AllocsPerRun(1000, func() {
+		h.Add(time.Millisecond * 20)
+	})
+	assert.Equal(t, 0.0, allocs)
+}
+
+func BenchmarkRollingHistogramAdd(b *testing.B) {
+	h := NewRollingHistogram(10, time.Second, nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.Add(time.Duration(i%10000) * time.Microsecond)
+	}
+}
@@ -0,0 +1,51 @@
+package collection
+
+import (
+	"sync"
+	"time"
+)
+
+// 基于滑动窗口的限流器
+// 窗口内所有存活桶的Sum之和即为当前窗口内已消耗的配额
+
+type RollingLimiter struct {
+	lock  sync.Mutex
+	limit int64
+	win   *RollingWindow
+}
+
+// NewRollingLimiter 创建限流器
+// limit - 窗口内允许通过的最大请求数(权重)
+// size, interval - 滑动窗口的桶数量以及每个桶的时间间隔, 窗口时长为 size*interval
+func NewRollingLimiter(limit int64, size int, interval time.Duration) *RollingLimiter {
+	if limit < 1 {
+		panic("limit must be greater than 0")
+	}
+	return &RollingLimiter{
+		limit: limit,
+		win:   NewRollingWindow(size, interval),
+	}
+}
+
+// Allow 消耗1个配额, 超出限制时返回false
+func (rl *RollingLimiter) Allow() bool {
+	return rl.Take(1)
+}
+
+// Take 消耗n个配额, 用于请求代价不同的场景, 超出限制时返回false且不消耗配额
+func (rl *RollingLimiter) Take(n int) bool {
+	if n <= 0 {
+		return true
+	}
+
+	// 统计与写入需要是原子的, 否则并发时可能超发
+	rl.lock.Lock()
+	defer rl.lock.Unlock()
+
+	if int64(rl.win.Sum())+int64(n) > rl.limit {
+		return false
+	}
+
+	rl.win.Add(float64(n))
+	return true
+}
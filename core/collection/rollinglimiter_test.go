@@ -0,0 +1,35 @@
+package collection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestRollingLimiterAllow(t *testing.T) {
+	const interval = time.Millisecond * 20
+	l := NewRollingLimiter(5, 3, interval)
+	for i := 0; i < 5; i++ {
+		assert.True(t, l.Allow())
+	}
+	assert.False(t, l.Allow())
+
+	// 整个窗口滑过之后配额恢复
+	time.Sleep(interval * 4)
+	assert.True(t, l.Allow())
+}
+
+func TestRollingLimiterTake(t *testing.T) {
+	l := NewRollingLimiter(10, 3, time.Second)
+	assert.True(t, l.Take(6))
+	assert.False(t, l.Take(5))
+	assert.True(t, l.Take(4))
+	assert.False(t, l.Allow())
+	assert.True(t, l.Take(0))
+}
+
+func TestRollingLimiterInvalidLimit(t *testing.T) {
+	assert.Panics(t, func() {
+		NewRollingLimiter(0, 3, time.Second)
+	})
+}
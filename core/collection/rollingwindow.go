@@ -2,68 +2,130 @@ package collection
 
 import (
 	"go-zero-/core/timex"
+	"math"
 	"sync"
 	"time"
 )
 
 // 滑动窗口
+// 默认的桶统计Sum和Count, 需要统计最大最小值、延迟分布等时, 可以通过 NewRollingWindowOf 使用自定义的桶
 
-type Bucket struct {
-	Sum   float64
-	Count int64
+type (
+	// BucketInterface 自定义的桶需要实现的接口
+	BucketInterface interface {
+		Add(v float64)
+		// 桶过期时清空数据, 之后会被复用
+		Reset()
+	}
+
+	Bucket struct {
+		Sum   float64
+		Count int64
+	}
+
+	// BucketSnapshot 桶在某一时刻的拷贝
+	BucketSnapshot struct {
+		Bucket
+		// 桶的开始时间, 与窗口时钟的 Now 一样是相对时间, 可以通过 Since 换算成距今多久
+		Start time.Duration
+		// 桶在当前窗口内是否写入过数据, 已经过期以及过期之后还没有写入过的桶为false, 数据为0
+		Active bool
+	}
+
+	// 统计Sum和Count的桶, 自定义的桶内嵌 Bucket 之后也可以使用 AddN、Sum、Count、Avg
+	countingBucket interface {
+		add(v float64, n int64)
+		sumAndCount() (float64, int64)
+	}
+)
+
+func (b *Bucket) Add(v float64) {
+	b.add(v, 1)
 }
 
-func (b *Bucket) add(v float64) {
+// v代表n个数据
+func (b *Bucket) add(v float64, n int64) {
 	b.Sum += v
-	b.Count++
+	b.Count += n
 }
 
-func (b *Bucket) reset() {
+func (b *Bucket) Reset() {
 	b.Sum = 0
 	b.Count = 0
 }
 
+func (b *Bucket) sumAndCount() (float64, int64) {
+	return b.Sum, b.Count
+}
+
 // 时间窗口
-type window struct {
-	buckets []*Bucket // 一个桶标识一个时间间隔
-	size    int       // 窗口大小
+type window[B BucketInterface] struct {
+	buckets []B // 一个桶标识一个时间间隔
+	// 桶清空之后是否写入过数据, 自定义的桶无法根据内容判断
+	written []bool
+	size    int // 窗口大小
 }
 
-func newWindow(size int) *window {
-	buckets := make([]*Bucket, size)
+func newWindow[B BucketInterface](size int, newBucket func() B) *window[B] {
+	buckets := make([]B, size)
 	for i := 0; i < size; i++ {
-		buckets[i] = new(Bucket)
+		buckets[i] = newBucket()
 	}
-	return &window{
+	return &window[B]{
 		buckets: buckets,
+		written: make([]bool, size),
 		size:    size,
 	}
 }
 
-func (w *window) add(offset int, v float64) {
-	w.buckets[offset%w.size].add(v)
+func (w *window[B]) add(offset int, v float64) {
+	w.buckets[offset%w.size].Add(v)
+	w.written[offset%w.size] = true
+}
+
+func (w *window[B]) addN(offset int, v float64, n int64) {
+	cb, ok := any(w.buckets[offset%w.size]).(countingBucket)
+	if !ok {
+		panic("AddN requires Bucket or a bucket embedding Bucket")
+	}
+	cb.add(v, n)
+	w.written[offset%w.size] = true
 }
 
 // 汇总数据
 // fn - 自定义的bucket统计函数
-func (w *window) reduce(start, count int, fn func(b *Bucket)) {
+func (w *window[B]) reduce(start, count int, fn func(b B)) {
 	for i := 0; i < count; i++ {
 		fn(w.buckets[(start+i)%w.size])
 	}
 }
 
+// 拷贝桶的数据, 没有内嵌 Bucket 的自定义桶只拷贝是否写入过
+func (w *window[B]) copyTo(snapshot *BucketSnapshot, offset int) {
+	snapshot.Active = w.written[offset]
+	if cb, ok := any(w.buckets[offset]).(countingBucket); ok {
+		snapshot.Sum, snapshot.Count = cb.sumAndCount()
+	}
+}
+
 // 清理特定bucket
-func (w *window) resetBucket(offset int) {
-	w.buckets[offset%w.size].reset()
+func (w *window[B]) resetBucket(offset int) {
+	w.buckets[offset%w.size].Reset()
+	w.written[offset%w.size] = false
 }
 
 type (
-	RollingWindow struct {
+	// RollingWindow 使用默认桶的滑动窗口
+	RollingWindow = RollingWindowOf[*Bucket]
+
+	// RollingWindowOf 使用自定义桶的滑动窗口
+	RollingWindowOf[B BucketInterface] struct {
+		// 读锁只用于不清理过期桶的只读操作, 例如 BucketAt
 		lock sync.RWMutex
 		// 滑动窗口数量
 		size int
 		// 窗口 数据容器
-		win *window
+		win *window[B]
 		// 滑动窗口单元时间间隔
 		interval time.Duration
 		// 游标，用于定位当前应该写入哪个bucket
@@ -73,36 +135,138 @@ type (
 		ignoreCurrent bool
 		// 最后写入桶的时间 用于计算下一次写入数据间隔最后一次写入数据的之间 经过了多少个时间间隔
 		lastTime time.Duration
+		// 第一次写入数据的时间, 用于计算还没有写满的窗口覆盖了多长时间
+		firstTime time.Duration
+		started   bool
+		// 时钟, 测试时可替换为 timex.MockClock
+		clock timex.Clock
+	}
+
+	// RollingWindowOption 滑动窗口的配置, 对任意类型的桶都适用
+	RollingWindowOption func(opts *rollingWindowOptions)
+
+	rollingWindowOptions struct {
+		ignoreCurrent bool
+		clock         timex.Clock
+		// 创建时预先写入的成功数和总数
+		prewarmAccepts int64
+		prewarmTotal   int64
+	}
+
+	// ReduceOption 单次汇总的配置, 覆盖创建窗口时的配置
+	ReduceOption func(opts *reduceOptions)
+
+	reduceOptions struct {
+		ignoreCurrent bool
 	}
-	RollingWindowOption func(rollingWindow *RollingWindow)
 )
 
 func NewRollingWindow(size int, interval time.Duration, opts ...RollingWindowOption) *RollingWindow {
+	return NewRollingWindowOf(size, interval, func() *Bucket {
+		return new(Bucket)
+	}, opts...)
+}
+
+// NewRollingWindowOf 创建使用自定义桶的滑动窗口, newBucket用于创建每一个桶
+func NewRollingWindowOf[B BucketInterface](size int, interval time.Duration, newBucket func() B,
+	opts ...RollingWindowOption) *RollingWindowOf[B] {
 	if size < 1 {
 		panic("size must be greater than 0")
 	}
-	w := &RollingWindow{
-		size:     size,
-		win:      newWindow(size),
-		interval: interval,
-		lastTime: timex.Now(),
+	if interval <= 0 {
+		panic("interval must be greater than 0")
+	}
+	// 窗口总时长 size*interval 不能溢出
+	if time.Duration(size) > math.MaxInt64/interval {
+		panic("size * interval overflows time.Duration")
+	}
+
+	options := rollingWindowOptions{
+		clock: timex.RealClock{},
 	}
 	for _, opt := range opts {
-		opt(w)
+		opt(&options)
+	}
+
+	rw := &RollingWindowOf[B]{
+		size:          size,
+		win:           newWindow(size, newBucket),
+		interval:      interval,
+		ignoreCurrent: options.ignoreCurrent,
+		lastTime:      options.clock.Now(),
+		clock:         options.clock,
+	}
+	if options.prewarmTotal > 0 {
+		rw.prewarm(options.prewarmAccepts, options.prewarmTotal)
+	}
+	return rw
+}
+
+// 把预热数据平均分到所有的桶, 除不尽的部分放在前面的桶, 每个桶的成功数都不会超过总数
+// 预热数据视为从最旧的桶开始写入, 之后随着窗口滑动自然过期
+func (rw *RollingWindowOf[B]) prewarm(accepts, total int64) {
+	size := int64(rw.size)
+	for i := int64(0); i < size; i++ {
+		n := total / size
+		if i < total%size {
+			n++
+		}
+		v := accepts / size
+		if i < accepts%size {
+			v++
+		}
+		rw.win.addN(int(i), float64(v), n)
 	}
-	return w
+
+	rw.started = true
+	rw.firstTime = rw.lastTime - time.Duration(rw.size-1)*rw.interval
 }
 
-func (rw *RollingWindow) Add(v float64) {
+func (rw *RollingWindowOf[B]) Add(v float64) {
 	rw.lock.Lock()
 	defer rw.lock.Unlock()
 	rw.updateOffset()
+	rw.markStarted()
 	rw.win.add(rw.offset, v)
 }
 
-func (rw *RollingWindow) span() int {
+// AddN 加入代表n个数据的v, 即Sum加v, Count加n, 用于批量统计, 例如一个采样代表50个请求
+// 桶需要是 Bucket 或者内嵌了 Bucket, 否则panic
+func (rw *RollingWindowOf[B]) AddN(v float64, n int64) {
+	if n < 0 {
+		panic("n must not be negative")
+	}
+
+	rw.lock.Lock()
+	defer rw.lock.Unlock()
+	rw.updateOffset()
+	rw.markStarted()
+	rw.win.addN(rw.offset, v, n)
+}
+
+func (rw *RollingWindowOf[B]) markStarted() {
+	if !rw.started {
+		rw.started = true
+		rw.firstTime = rw.clock.Now()
+	}
+}
+
+// Reset 清空所有的桶, 恢复到刚创建时的状态
+func (rw *RollingWindowOf[B]) Reset() {
+	rw.lock.Lock()
+	defer rw.lock.Unlock()
+
+	for i := 0; i < rw.size; i++ {
+		rw.win.resetBucket(i)
+	}
+	rw.offset = 0
+	rw.lastTime = rw.clock.Now()
+	rw.started = false
+}
+
+func (rw *RollingWindowOf[B]) span() int {
 	// 算出经过了多少个时间单元间隔，实际上就是指经过了多少个桶
-	offset := int(timex.Since(rw.lastTime) / rw.interval)
+	offset := int(rw.clock.Since(rw.lastTime) / rw.interval)
 	if 0 <= offset && offset < rw.size {
 		return offset
 	}
@@ -110,7 +274,7 @@ func (rw *RollingWindow) span() int {
 	return rw.size
 }
 
-func (rw *RollingWindow) updateOffset() {
+func (rw *RollingWindowOf[B]) updateOffset() {
 	span := rw.span()
 	if span <= 0 {
 		return
@@ -124,7 +288,7 @@ func (rw *RollingWindow) updateOffset() {
 	// 更新offset, 也就是指向当前的桶
 	rw.offset = (offset + span) % rw.size
 	// 更新现在的时间
-	now := timex.Now()
+	now := rw.clock.Now()
 	// 思考: 这里为什么不直接用 now - rw.lastTime
 	// 如果直接使用 now - rw.lastTime，得到的是当前时间和上次更新时间之间的时间差,而我们需要根据滚动窗口的间隔来调整这个时间差，以便将下一次更新时间对齐到间隔的边界上。
 	/*
@@ -149,29 +313,281 @@ func (rw *RollingWindow) updateOffset() {
 		```
 		通过这种方式，我们确保了每个桶都是完整且等长的，便于我们进行统计和分析。
 	*/
-	rw.lastTime = now - (now-rw.lastTime)%rw.interval
+	// 即 now - (now-rw.lastTime)%rw.interval, 保持与最初的lastTime对齐
+	rw.lastTime += timex.TruncateTo(now-rw.lastTime, rw.interval)
 }
 
-func (rw *RollingWindow) Reduce(fn func(b *Bucket)) {
+func (rw *RollingWindowOf[B]) Reduce(fn func(b B)) {
+	rw.reduce(fn, rw.ignoreCurrent)
+}
+
+// ReduceAll 汇总所有有效的桶, 不受 IgnoreCurrentBucket 影响, 总是包含当前正在写入的桶
+// 适用于需要实时读数的场景, 无需为此再创建一个不忽略当前桶的窗口
+func (rw *RollingWindowOf[B]) ReduceAll(fn func(b B)) {
+	rw.reduce(fn, false)
+}
+
+// SumAndCount 一次汇总同时返回窗口内的Sum和Count, 与 Reduce 一样受 IgnoreCurrentBucket 影响
+// 没有内嵌 Bucket 的自定义桶不参与统计
+func (rw *RollingWindowOf[B]) SumAndCount() (sum float64, count int64) {
+	rw.Reduce(func(b B) {
+		if cb, ok := any(b).(countingBucket); ok {
+			s, c := cb.sumAndCount()
+			sum += s
+			count += c
+		}
+	})
+	return sum, count
+}
+
+// Sum 返回窗口内所有数据的和
+func (rw *RollingWindowOf[B]) Sum() float64 {
+	sum, _ := rw.SumAndCount()
+	return sum
+}
+
+// Count 返回窗口内数据的个数
+func (rw *RollingWindowOf[B]) Count() int64 {
+	_, count := rw.SumAndCount()
+	return count
+}
+
+// MovingSum 返回最近lastN个桶内数据的和, 用于在较长的窗口中观察短期的变化, lastN必须在 [1, size] 之间
+// 与 Reduce 一样受 IgnoreCurrentBucket 影响, 忽略当前桶时从上一个桶开始往前数, 最多汇总 size-1 个桶
+func (rw *RollingWindowOf[B]) MovingSum(lastN int) float64 {
+	sum, _ := rw.movingSumAndCount(lastN)
+	return sum
+}
+
+// MovingCount 返回最近lastN个桶内数据的个数, 规则同 MovingSum
+func (rw *RollingWindowOf[B]) MovingCount(lastN int) int64 {
+	_, count := rw.movingSumAndCount(lastN)
+	return count
+}
+
+func (rw *RollingWindowOf[B]) movingSumAndCount(lastN int) (sum float64, count int64) {
+	if lastN <= 0 || lastN > rw.size {
+		panic("lastN must be in [1, size]")
+	}
+
+	rw.lock.Lock()
+	defer rw.lock.Unlock()
+
+	rw.updateOffset()
+	// 最近的桶为当前桶, 忽略当前桶时为上一个桶
+	end := rw.offset
+	if rw.ignoreCurrent {
+		end--
+		if lastN > rw.size-1 {
+			lastN = rw.size - 1
+		}
+	}
+	if lastN == 0 {
+		return 0, 0
+	}
+
+	// end最小为-1, 加上size保证起点不为负数
+	rw.win.reduce(end-lastN+1+rw.size, lastN, func(b B) {
+		if cb, ok := any(b).(countingBucket); ok {
+			s, c := cb.sumAndCount()
+			sum += s
+			count += c
+		}
+	})
+	return sum, count
+}
+
+// Avg 返回窗口内数据的平均值, 没有数据时返回0
+func (rw *RollingWindowOf[B]) Avg() float64 {
+	sum, count := rw.SumAndCount()
+	if count == 0 {
+		return 0
+	}
+	return sum / float64(count)
+}
+
+// Rate 返回窗口内平均每秒的数据个数, 即 Count 除以窗口实际覆盖的时长
+// 覆盖的时长为窗口时长和第一次写入数据至今的时长中较小的一个, 刚创建的窗口不会因为没有写满而偏低
+// 忽略当前桶时, 当前桶的时长也不计入, 只有当前桶有数据时返回0
+func (rw *RollingWindowOf[B]) Rate() float64 {
+	rw.lock.Lock()
+	defer rw.lock.Unlock()
+
+	rw.updateOffset()
+	if !rw.started {
+		return 0
+	}
+
+	var count int64
+	rw.reduceLocked(func(b B) {
+		if cb, ok := any(b).(countingBucket); ok {
+			_, c := cb.sumAndCount()
+			count += c
+		}
+	}, rw.ignoreCurrent)
+
+	// 窗口从最旧的桶开始, 到当前时间或者当前桶的开始时间结束
+	end := rw.clock.Now()
+	if rw.ignoreCurrent {
+		end = rw.lastTime
+	}
+	covered := end - (rw.lastTime - time.Duration(rw.size-1)*rw.interval)
+	if elapsed := end - rw.firstTime; elapsed < covered {
+		covered = elapsed
+	}
+	if count == 0 || covered <= 0 {
+		return 0
+	}
+
+	return float64(count) / covered.Seconds()
+}
+
+// Snapshot 返回窗口内每个桶的拷贝, 按时间从旧到新排列, 已经过期的桶为0
+// 与 Reduce 一样受 IgnoreCurrentBucket 影响, 没有内嵌 Bucket 的自定义桶只有开始时间
+func (rw *RollingWindowOf[B]) Snapshot() []BucketSnapshot {
+	rw.lock.Lock()
+	defer rw.lock.Unlock()
+
+	return rw.snapshotLocked(rw.ignoreCurrent)
+}
+
+// 调用方需要持有写锁, 先清理过期的桶再拷贝
+func (rw *RollingWindowOf[B]) snapshotLocked(ignoreCurrent bool) []BucketSnapshot {
+	rw.updateOffset()
+	count := rw.size
+	if ignoreCurrent {
+		count--
+	}
+
+	snapshot := make([]BucketSnapshot, 0, count)
+	// 最旧的桶比当前桶早 size-1 个时间间隔
+	start := rw.lastTime - time.Duration(rw.size-1)*rw.interval
+	for i := 0; i < count; i++ {
+		bucket := BucketSnapshot{
+			Start: start,
+		}
+		rw.win.copyTo(&bucket, (rw.offset+1+i)%rw.size)
+		snapshot = append(snapshot, bucket)
+		start += rw.interval
+	}
+	return snapshot
+}
+
+// BucketAt 返回第logicalOffset新的桶的拷贝, 0为当前桶, size-1为最旧的桶, 不受 IgnoreCurrentBucket 影响
+// 只持有读锁, 不会清理过期的桶, 而是按经过的时间推算, 结果与 Snapshot 中对应的桶一致
+func (rw *RollingWindowOf[B]) BucketAt(logicalOffset int) BucketSnapshot {
+	if logicalOffset < 0 || logicalOffset >= rw.size {
+		panic("logicalOffset must be in [0, size-1]")
+	}
+
 	rw.lock.RLock()
 	defer rw.lock.RUnlock()
 
-	var diff int
-
+	// 与 updateOffset 的计算方式一致, 只是不修改窗口的状态
 	span := rw.span()
-	if span == 0 && rw.ignoreCurrent {
-		diff = rw.size - 1
-	} else {
-		diff = rw.size - span
+	current := rw.offset
+	start := rw.lastTime
+	if span > 0 {
+		current = (rw.offset + span) % rw.size
+		start += timex.TruncateTo(rw.clock.Since(rw.lastTime), rw.interval)
 	}
-	if diff > 0 {
-		offset := (rw.offset + span + 1) % rw.size
-		rw.win.reduce(offset, diff, fn)
+
+	snapshot := BucketSnapshot{
+		Start: start - time.Duration(logicalOffset)*rw.interval,
+	}
+	// 经过span个时间间隔之后, 最新的span个桶里还是过期的数据, 等待下一次 updateOffset 清理
+	if logicalOffset < span {
+		return snapshot
+	}
+
+	rw.win.copyTo(&snapshot, (current-logicalOffset+rw.size)%rw.size)
+	return snapshot
+}
+
+// ReduceWithOptions 与 Reduce 相同, 但可以通过 IncludeCurrent、ExcludeCurrent 决定本次汇总是否包含当前桶
+// 默认与创建窗口时的 IgnoreCurrentBucket 一致, 便于同一个窗口供不同需求的使用方读取
+func (rw *RollingWindowOf[B]) ReduceWithOptions(fn func(b B), opts ...ReduceOption) {
+	options := reduceOptions{
+		ignoreCurrent: rw.ignoreCurrent,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	rw.reduce(fn, options.ignoreCurrent)
+}
+
+// 先在写锁内把过期的桶清理掉, 再汇总, 保证汇总时的 offset 和 lastTime 与桶的数据一致
+// 否则并发的 Add 会在汇总过程中移动 offset, 导致同一次汇总里混入已经过期的桶
+func (rw *RollingWindowOf[B]) reduce(fn func(b B), ignoreCurrent bool) {
+	rw.lock.Lock()
+	defer rw.lock.Unlock()
+
+	rw.updateOffset()
+	rw.reduceLocked(fn, ignoreCurrent)
+}
+
+// 调用方需要持有锁并且已经调用过 updateOffset
+func (rw *RollingWindowOf[B]) reduceLocked(fn func(b B), ignoreCurrent bool) {
+	// 已经对齐到当前桶, 从当前桶的下一个(也就是最旧的桶)开始, 到当前桶结束
+	count := rw.size
+	if ignoreCurrent {
+		count--
+	}
+	if count > 0 {
+		rw.win.reduce(rw.offset+1, count, fn)
 	}
 }
 
 func IgnoreCurrentBucket() RollingWindowOption {
-	return func(w *RollingWindow) {
-		w.ignoreCurrent = true
+	return func(opts *rollingWindowOptions) {
+		opts.ignoreCurrent = true
+	}
+}
+
+// IncludeCurrent 本次汇总包含当前正在写入的桶
+func IncludeCurrent() ReduceOption {
+	return func(opts *reduceOptions) {
+		opts.ignoreCurrent = false
+	}
+}
+
+// ExcludeCurrent 本次汇总忽略当前正在写入的桶
+func ExcludeCurrent() ReduceOption {
+	return func(opts *reduceOptions) {
+		opts.ignoreCurrent = true
+	}
+}
+
+// WithPrewarm 创建时预先写入accepts个成功和total-accepts个失败, 平均分布在所有的桶中
+// 成功记为1, 失败记为0, 与熔断器的统计方式一致, 避免刚启动时少量的失败就触发熔断
+// 预热数据在一个完整的窗口周期之后全部过期, Reset 之后不再保留, 桶需要是 Bucket 或者内嵌了 Bucket
+func WithPrewarm(accepts, total int64) RollingWindowOption {
+	if total < 0 {
+		panic("total must not be negative")
+	}
+	if accepts < 0 || accepts > total {
+		panic("accepts must be in [0, total]")
 	}
+
+	return func(opts *rollingWindowOptions) {
+		opts.prewarmAccepts = accepts
+		opts.prewarmTotal = total
+	}
+}
+
+// WithWindowClock 设置滑动窗口使用的时钟
+func WithWindowClock(clock timex.Clock) RollingWindowOption {
+	return func(opts *rollingWindowOptions) {
+		opts.clock = clock
+	}
+}
+
+// Aggregate 对窗口内有效的桶做折叠汇总, 返回最终的累加值, 无需在回调中修改外部变量
+func Aggregate[T any, B BucketInterface](rw *RollingWindowOf[B], seed T, fn func(acc T, b B) T) T {
+	acc := seed
+	rw.Reduce(func(b B) {
+		acc = fn(acc, b)
+	})
+	return acc
 }
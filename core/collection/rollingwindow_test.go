@@ -0,0 +1,796 @@
+package collection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/timex"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAggregate(t *testing.T) {
+	rw := NewRollingWindow(4, time.Second)
+	for _, v := range []float64{1, 2, 3, 0.5} {
+		rw.Add(v)
+	}
+
+	var sum float64
+	var count int64
+	rw.Reduce(func(b *Bucket) {
+		sum += b.Sum
+		count += b.Count
+	})
+
+	type result struct {
+		sum   float64
+		count int64
+	}
+	res := Aggregate(rw, result{}, func(acc result, b *Bucket) result {
+		acc.sum += b.Sum
+		acc.count += b.Count
+		return acc
+	})
+	assert.Equal(t, sum, res.sum)
+	assert.Equal(t, count, res.count)
+	assert.Equal(t, 6.5, res.sum)
+	assert.Equal(t, int64(4), res.count)
+
+	assert.Equal(t, 6.5, Aggregate(rw, 0.0, func(acc float64, b *Bucket) float64 {
+		return acc + b.Sum
+	}))
+}
+
+func TestReduceAll(t *testing.T) {
+	sum := func(reduce func(fn func(b *Bucket))) float64 {
+		var result float64
+		reduce(func(b *Bucket) {
+			result += b.Sum
+		})
+		return result
+	}
+
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(4, time.Second, IgnoreCurrentBucket(), WithWindowClock(clock))
+	rw.Add(1)
+	clock.Advance(time.Second)
+	rw.Add(2)
+
+	// 当前桶有新写入的数据时, Reduce 忽略当前桶, ReduceAll 包含当前桶
+	assert.Equal(t, 1.0, sum(rw.Reduce))
+	assert.Equal(t, 3.0, sum(rw.ReduceAll))
+
+	// 当前桶已经过去, 两者一致
+	clock.Advance(time.Second)
+	assert.Equal(t, 3.0, sum(rw.Reduce))
+	assert.Equal(t, 3.0, sum(rw.ReduceAll))
+
+	// 未忽略当前桶的窗口, 两者一致
+	rw = NewRollingWindow(4, time.Second, WithWindowClock(clock))
+	rw.Add(1)
+	assert.Equal(t, 1.0, sum(rw.Reduce))
+	assert.Equal(t, 1.0, sum(rw.ReduceAll))
+}
+
+func TestRollingWindowConcurrentAddReduce(t *testing.T) {
+	rw := NewRollingWindow(10, time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				rw.Add(1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				var sum float64
+				var count int64
+				rw.Reduce(func(b *Bucket) {
+					sum += b.Sum
+					count += b.Count
+				})
+				// 每次Add(1), Sum与Count始终一致
+				assert.Equal(t, float64(count), sum)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRollingWindowReduceAfterIdle(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(4, time.Second, WithWindowClock(clock))
+	for i := 0; i < 4; i++ {
+		rw.Add(float64(i + 1))
+		clock.Advance(time.Second)
+	}
+
+	sum := func() float64 {
+		var result float64
+		rw.Reduce(func(b *Bucket) {
+			result += b.Sum
+		})
+		return result
+	}
+
+	// 最早的桶已经过期
+	assert.Equal(t, 9.0, sum())
+	// 长时间没有写入, 所有的桶都已过期
+	clock.Advance(time.Hour)
+	assert.Equal(t, 0.0, sum())
+	// 过期的桶已经被清理, 之后写入的数据不会和旧数据混在一起
+	rw.Add(10)
+	clock.Advance(time.Second * 3)
+	assert.Equal(t, 10.0, sum())
+	clock.Advance(time.Second)
+	assert.Equal(t, 0.0, sum())
+}
+
+// 统计最大最小值的自定义桶
+type minMaxBucket struct {
+	min, max float64
+	count    int
+}
+
+func (b *minMaxBucket) Add(v float64) {
+	if b.count == 0 || v < b.min {
+		b.min = v
+	}
+	if b.count == 0 || v > b.max {
+		b.max = v
+	}
+	b.count++
+}
+
+func (b *minMaxBucket) Reset() {
+	*b = minMaxBucket{}
+}
+
+func TestRollingWindowOfCustomBucket(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindowOf(3, time.Second, func() *minMaxBucket {
+		return new(minMaxBucket)
+	}, WithWindowClock(clock))
+
+	minMax := func() (float64, float64, int) {
+		var result minMaxBucket
+		rw.Reduce(func(b *minMaxBucket) {
+			if b.count == 0 {
+				return
+			}
+			result.Add(b.min)
+			result.Add(b.max)
+			result.count += b.count - 2
+		})
+		return result.min, result.max, result.count
+	}
+
+	rw.Add(5)
+	rw.Add(100)
+	clock.Advance(time.Second)
+	rw.Add(3)
+	clock.Advance(time.Second)
+	rw.Add(7)
+	min, max, count := minMax()
+	assert.Equal(t, 3.0, min)
+	assert.Equal(t, 100.0, max)
+	assert.Equal(t, 4, count)
+
+	// 第一个桶过期后被重置, 其中的最大值不再参与统计
+	clock.Advance(time.Second)
+	rw.Add(50)
+	min, max, count = minMax()
+	assert.Equal(t, 3.0, min)
+	assert.Equal(t, 50.0, max)
+	assert.Equal(t, 3, count)
+
+	// 复用的桶从空状态开始
+	clock.Advance(time.Second * 2)
+	rw.Add(60)
+	min, max, count = minMax()
+	assert.Equal(t, 50.0, min)
+	assert.Equal(t, 60.0, max)
+	assert.Equal(t, 2, count)
+
+	assert.Equal(t, 2, Aggregate(rw, 0, func(acc int, b *minMaxBucket) int {
+		return acc + b.count
+	}))
+}
+
+func TestRollingWindowSumCountAvg(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(3, time.Second, WithWindowClock(clock))
+	assertStats := func(sum float64, count int64, avg float64) {
+		assert.Equal(t, sum, rw.Sum())
+		assert.Equal(t, count, rw.Count())
+		assert.Equal(t, avg, rw.Avg())
+	}
+
+	// 空窗口
+	assertStats(0, 0, 0)
+
+	// 部分写入
+	rw.Add(1)
+	rw.Add(3)
+	clock.Advance(time.Second)
+	rw.Add(5)
+	assertStats(9, 3, 3)
+
+	// 回绕之后最早的桶被覆盖
+	clock.Advance(time.Second)
+	rw.Add(7)
+	clock.Advance(time.Second)
+	rw.Add(9)
+	assertStats(21, 3, 7)
+
+	// 全部过期
+	clock.Advance(time.Second * 3)
+	assertStats(0, 0, 0)
+}
+
+func TestRollingWindowSumIgnoreCurrent(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(3, time.Second, IgnoreCurrentBucket(), WithWindowClock(clock))
+	rw.Add(2)
+	assert.Equal(t, 0.0, rw.Sum())
+	assert.Equal(t, int64(0), rw.Count())
+
+	clock.Advance(time.Second)
+	rw.Add(4)
+	sum, count := rw.SumAndCount()
+	assert.Equal(t, 2.0, sum)
+	assert.Equal(t, int64(1), count)
+	assert.Equal(t, 2.0, rw.Avg())
+}
+
+type taggedBucket struct {
+	Bucket
+	tags int
+}
+
+func (b *taggedBucket) Add(v float64) {
+	b.Bucket.Add(v)
+	b.tags++
+}
+
+func (b *taggedBucket) Reset() {
+	*b = taggedBucket{}
+}
+
+func TestRollingWindowOfSum(t *testing.T) {
+	// 内嵌 Bucket 的自定义桶参与统计
+	tagged := NewRollingWindowOf(3, time.Second, func() *taggedBucket {
+		return new(taggedBucket)
+	})
+	tagged.Add(1)
+	tagged.Add(2)
+	assert.Equal(t, 3.0, tagged.Sum())
+	assert.Equal(t, 1.5, tagged.Avg())
+
+	// 其它自定义桶不参与统计
+	minMax := NewRollingWindowOf(3, time.Second, func() *minMaxBucket {
+		return new(minMaxBucket)
+	})
+	minMax.Add(1)
+	assert.Equal(t, 0.0, minMax.Sum())
+	assert.Equal(t, int64(0), minMax.Count())
+}
+
+func TestNewRollingWindowInvalid(t *testing.T) {
+	assert.PanicsWithValue(t, "size must be greater than 0", func() {
+		NewRollingWindow(0, time.Second)
+	})
+	assert.PanicsWithValue(t, "interval must be greater than 0", func() {
+		NewRollingWindow(10, 0)
+	})
+	assert.PanicsWithValue(t, "interval must be greater than 0", func() {
+		NewRollingWindow(10, -time.Second)
+	})
+	assert.PanicsWithValue(t, "size * interval overflows time.Duration", func() {
+		NewRollingWindow(math.MaxInt32, time.Hour*24*365)
+	})
+	assert.NotPanics(t, func() {
+		NewRollingWindow(1, time.Duration(math.MaxInt64))
+	})
+}
+
+func TestRollingWindowReset(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(3, time.Second, WithWindowClock(clock))
+	for i := 0; i < 5; i++ {
+		rw.Add(float64(i))
+		clock.Advance(time.Second)
+	}
+	clock.Advance(time.Millisecond * 500)
+	assert.NotZero(t, rw.Count())
+
+	rw.Reset()
+	assert.Equal(t, 0.0, rw.Sum())
+	assert.Equal(t, int64(0), rw.Count())
+	assert.Equal(t, 0, rw.offset)
+	assert.Equal(t, clock.Now(), rw.lastTime)
+
+	// 重置之后从第0个桶开始写入, 桶的边界与重置的时间对齐
+	rw.Add(1)
+	clock.Advance(time.Millisecond * 999)
+	rw.Add(2)
+	assert.Equal(t, 0, rw.offset)
+	assert.Equal(t, int64(2), rw.win.buckets[0].Count)
+	clock.Advance(time.Millisecond)
+	rw.Add(3)
+	assert.Equal(t, 1, rw.offset)
+	assert.Equal(t, 6.0, rw.Sum())
+}
+
+func TestRollingWindowConcurrentReset(t *testing.T) {
+	rw := NewRollingWindow(10, time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				rw.Add(1)
+				_ = rw.Count()
+				if j%100 == 0 {
+					rw.Reset()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	rw.Reset()
+	assert.Equal(t, int64(0), rw.Count())
+}
+
+func TestRollingWindowSnapshot(t *testing.T) {
+	clock := timex.NewMockClock(time.Second * 100)
+	rw := NewRollingWindow(3, time.Second, WithWindowClock(clock))
+	assert.Equal(t, []BucketSnapshot{
+		{Start: time.Second * 98},
+		{Start: time.Second * 99},
+		{Start: time.Second * 100},
+	}, rw.Snapshot())
+
+	// 写满并回绕, 中间有一个空桶
+	for _, v := range []float64{1, 2, 3} {
+		rw.Add(v)
+		clock.Advance(time.Second)
+	}
+	clock.Advance(time.Second)
+	rw.Add(5)
+	clock.Advance(time.Millisecond * 500)
+
+	snapshot := rw.Snapshot()
+	assert.Equal(t, []BucketSnapshot{
+		{Bucket: Bucket{Sum: 3, Count: 1}, Start: time.Second * 102, Active: true},
+		{Start: time.Second * 103},
+		{Bucket: Bucket{Sum: 5, Count: 1}, Start: time.Second * 104, Active: true},
+	}, snapshot)
+
+	// 返回的是拷贝
+	snapshot[0].Sum = 100
+	snapshot[0].Count = 100
+	assert.Equal(t, 8.0, rw.Sum())
+	assert.Equal(t, 3.0, rw.Snapshot()[0].Sum)
+}
+
+func TestRollingWindowSnapshotIgnoreCurrent(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(3, time.Second, IgnoreCurrentBucket(), WithWindowClock(clock))
+	rw.Add(1)
+	clock.Advance(time.Second)
+	rw.Add(2)
+
+	assert.Equal(t, []BucketSnapshot{
+		{Start: -time.Second},
+		{Bucket: Bucket{Sum: 1, Count: 1}, Start: 0, Active: true},
+	}, rw.Snapshot())
+}
+
+func TestRollingWindowAddN(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(3, time.Second, WithWindowClock(clock))
+	rw.AddN(50, 10)
+	rw.AddN(0, 0)
+	rw.Add(1)
+
+	var sum float64
+	var count int64
+	rw.Reduce(func(b *Bucket) {
+		sum += b.Sum
+		count += b.Count
+	})
+	assert.Equal(t, 51.0, sum)
+	assert.Equal(t, int64(11), count)
+
+	// 两种写入方式移动窗口的方式相同
+	added := NewRollingWindow(3, time.Second, WithWindowClock(clock))
+	addedN := NewRollingWindow(3, time.Second, WithWindowClock(clock))
+	for _, d := range []time.Duration{0, time.Millisecond * 1500, time.Second, time.Second * 5, time.Millisecond * 700} {
+		clock.Advance(d)
+		added.Add(2)
+		addedN.AddN(2, 1)
+		assert.Equal(t, added.offset, addedN.offset)
+		assert.Equal(t, added.lastTime, addedN.lastTime)
+		assert.Equal(t, added.Snapshot(), addedN.Snapshot())
+	}
+}
+
+func TestRollingWindowAddNInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		NewRollingWindow(3, time.Second).AddN(1, -1)
+	})
+
+	// 没有内嵌 Bucket 的自定义桶不支持AddN
+	rw := NewRollingWindowOf(3, time.Second, func() *minMaxBucket {
+		return new(minMaxBucket)
+	})
+	assert.Panics(t, func() {
+		rw.AddN(1, 2)
+	})
+
+	tagged := NewRollingWindowOf(3, time.Second, func() *taggedBucket {
+		return new(taggedBucket)
+	})
+	tagged.AddN(6, 3)
+	assert.Equal(t, 2.0, tagged.Avg())
+}
+
+func TestRollingWindowReduceWithOptions(t *testing.T) {
+	sum := func(rw *RollingWindow, opts ...ReduceOption) float64 {
+		var result float64
+		rw.ReduceWithOptions(func(b *Bucket) {
+			result += b.Sum
+		}, opts...)
+		return result
+	}
+
+	for _, ignoreCurrent := range []bool{false, true} {
+		clock := timex.NewMockClock(0)
+		opts := []RollingWindowOption{WithWindowClock(clock)}
+		if ignoreCurrent {
+			opts = append(opts, IgnoreCurrentBucket())
+		}
+		rw := NewRollingWindow(3, time.Second, opts...)
+
+		// 第i个桶写入1<<i, 窗口内最多保留最近的3个桶
+		var values []float64
+		for i := 0; i < 7; i++ {
+			v := float64(int(1) << i)
+			rw.Add(v)
+			values = append(values, v)
+			if len(values) > 3 {
+				values = values[1:]
+			}
+
+			var all float64
+			for _, v := range values {
+				all += v
+			}
+			previous := all - v
+			assert.Equal(t, all, sum(rw, IncludeCurrent()), "fill %d", i+1)
+			assert.Equal(t, previous, sum(rw, ExcludeCurrent()), "fill %d", i+1)
+			if ignoreCurrent {
+				assert.Equal(t, previous, sum(rw), "fill %d", i+1)
+			} else {
+				assert.Equal(t, all, sum(rw), "fill %d", i+1)
+			}
+			// 最后一个配置生效
+			assert.Equal(t, all, sum(rw, ExcludeCurrent(), IncludeCurrent()), "fill %d", i+1)
+
+			clock.Advance(time.Second)
+		}
+	}
+}
+
+func TestRollingWindowReduceWithOptionsSingleBucket(t *testing.T) {
+	rw := NewRollingWindow(1, time.Second)
+	rw.Add(1)
+	var count int
+	rw.ReduceWithOptions(func(b *Bucket) {
+		count++
+	}, ExcludeCurrent())
+	assert.Equal(t, 0, count)
+	rw.ReduceWithOptions(func(b *Bucket) {
+		count++
+	}, IncludeCurrent())
+	assert.Equal(t, 1, count)
+}
+
+func TestRollingWindowRate(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(10, time.Second, WithWindowClock(clock))
+	assert.Equal(t, 0.0, rw.Rate())
+
+	// 每秒100次, 窗口还没写满时按已经覆盖的时长计算
+	rates := make(map[time.Duration]float64)
+	for i := 1; i <= 1500; i++ {
+		rw.Add(1)
+		clock.Advance(time.Millisecond * 10)
+		if now := clock.Now(); now%(time.Millisecond*500) == 0 {
+			rates[now] = rw.Rate()
+		}
+	}
+	for _, at := range []time.Duration{time.Millisecond * 500, time.Second, time.Millisecond * 1500,
+		time.Second * 2, time.Second * 10, time.Second * 15} {
+		assert.InDelta(t, 100, rates[at], 5, "at %s", at)
+	}
+
+	// 没有新数据时逐步过期
+	clock.Advance(time.Second * 5)
+	// 窗口覆盖 [11s, 20s], 其中只有 [11s, 15s) 有数据
+	assert.InDelta(t, 400.0/9, rw.Rate(), 1e-9)
+	clock.Advance(time.Second * 10)
+	assert.Equal(t, 0.0, rw.Rate())
+
+	rw.Reset()
+	assert.Equal(t, 0.0, rw.Rate())
+	clock.Advance(time.Millisecond * 200)
+	rw.AddN(1, 10)
+	clock.Advance(time.Millisecond * 100)
+	assert.InDelta(t, 100, rw.Rate(), 1e-9)
+}
+
+func TestRollingWindowRateIgnoreCurrent(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(10, time.Second, WithWindowClock(clock), IgnoreCurrentBucket())
+
+	for i := 0; i < 50; i++ {
+		rw.Add(1)
+		clock.Advance(time.Millisecond * 10)
+	}
+	// 只有当前桶有数据, 不计入当前桶时没有可用的数据
+	assert.Equal(t, 0.0, rw.Rate())
+
+	for i := 0; i < 100; i++ {
+		rw.Add(1)
+		clock.Advance(time.Millisecond * 10)
+	}
+	// 第一个桶写满后按已结束桶的时长计算
+	assert.InDelta(t, 100, rw.Rate(), 5)
+
+	for i := 0; i < 1500; i++ {
+		rw.Add(1)
+		clock.Advance(time.Millisecond * 10)
+	}
+	assert.InDelta(t, 100, rw.Rate(), 5)
+}
+
+func TestCountingRollingWindowRate(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	cw := NewCountingRollingWindow(10, time.Second, WithWindowClock(clock))
+	for i := 0; i < 20; i++ {
+		cw.Increment()
+		clock.Advance(time.Millisecond * 100)
+	}
+	assert.InDelta(t, 10, cw.Rate(), 1e-9)
+}
+
+func TestRollingWindowMoving(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(4, time.Second, WithWindowClock(clock))
+	for i := 1; i <= 4; i++ {
+		rw.AddN(float64(i), int64(i))
+		clock.Advance(time.Second)
+	}
+	// 最后一次 Advance 之后当前桶为空
+	rw.Add(10)
+
+	assert.Equal(t, 10.0, rw.MovingSum(1))
+	assert.Equal(t, int64(1), rw.MovingCount(1))
+	assert.Equal(t, 14.0, rw.MovingSum(2))
+	assert.Equal(t, int64(5), rw.MovingCount(2))
+	// 整个窗口与 Sum/Count 一致, 最早的1已经滑出窗口
+	assert.Equal(t, rw.Sum(), rw.MovingSum(4))
+	assert.Equal(t, 19.0, rw.MovingSum(4))
+	assert.Equal(t, int64(10), rw.MovingCount(4))
+
+	// 部分桶过期之后只剩最近的数据
+	clock.Advance(time.Second * 2)
+	assert.Equal(t, 0.0, rw.MovingSum(2))
+	assert.Equal(t, int64(0), rw.MovingCount(2))
+	assert.Equal(t, 14.0, rw.MovingSum(4))
+	assert.Equal(t, int64(5), rw.MovingCount(4))
+
+	// 全部过期
+	clock.Advance(time.Second * 4)
+	assert.Equal(t, 0.0, rw.MovingSum(4))
+	assert.Equal(t, int64(0), rw.MovingCount(4))
+}
+
+func TestRollingWindowMovingIgnoreCurrent(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(4, time.Second, IgnoreCurrentBucket(), WithWindowClock(clock))
+	for i := 1; i <= 4; i++ {
+		rw.Add(float64(i))
+		clock.Advance(time.Second)
+	}
+	rw.Add(10)
+
+	// 从上一个桶开始往前数
+	assert.Equal(t, 4.0, rw.MovingSum(1))
+	assert.Equal(t, int64(1), rw.MovingCount(1))
+	assert.Equal(t, 7.0, rw.MovingSum(2))
+	// lastN == size 时与 Sum 一致, 只有 size-1 个桶
+	assert.Equal(t, rw.Sum(), rw.MovingSum(4))
+	assert.Equal(t, 9.0, rw.MovingSum(4))
+	assert.Equal(t, int64(3), rw.MovingCount(4))
+
+	clock.Advance(time.Second)
+	assert.Equal(t, 10.0, rw.MovingSum(1))
+	assert.Equal(t, 17.0, rw.MovingSum(3))
+
+	single := NewRollingWindow(1, time.Second, IgnoreCurrentBucket())
+	single.Add(1)
+	assert.Equal(t, 0.0, single.MovingSum(1))
+	assert.Equal(t, int64(0), single.MovingCount(1))
+}
+
+func TestRollingWindowMovingInvalid(t *testing.T) {
+	rw := NewRollingWindow(4, time.Second)
+	assert.Panics(t, func() {
+		rw.MovingSum(0)
+	})
+	assert.Panics(t, func() {
+		rw.MovingCount(-1)
+	})
+	assert.Panics(t, func() {
+		rw.MovingSum(5)
+	})
+	assert.NotPanics(t, func() {
+		rw.MovingCount(4)
+	})
+}
+
+func TestRollingWindowBucketAt(t *testing.T) {
+	clock := timex.NewMockClock(time.Second * 100)
+	rw := NewRollingWindow(3, time.Second, WithWindowClock(clock))
+	assert.Equal(t, BucketSnapshot{Start: time.Second * 98}, rw.BucketAt(2))
+
+	// 写入5个桶, offset回绕一次, 窗口内为3、4、5
+	for i := 1; i <= 5; i++ {
+		rw.AddN(float64(i), int64(i))
+		if i < 5 {
+			clock.Advance(time.Second)
+		}
+	}
+	assert.Equal(t, BucketSnapshot{Bucket: Bucket{Sum: 5, Count: 5}, Start: time.Second * 104, Active: true},
+		rw.BucketAt(0))
+	assert.Equal(t, BucketSnapshot{Bucket: Bucket{Sum: 4, Count: 4}, Start: time.Second * 103, Active: true},
+		rw.BucketAt(1))
+	assert.Equal(t, BucketSnapshot{Bucket: Bucket{Sum: 3, Count: 3}, Start: time.Second * 102, Active: true},
+		rw.BucketAt(2))
+
+	// 与 Snapshot 的顺序相反
+	snapshot := rw.Snapshot()
+	for i := range snapshot {
+		assert.Equal(t, snapshot[len(snapshot)-1-i], rw.BucketAt(i))
+	}
+
+	// 经过两个时间间隔, 最新的两个桶已经过期但还没有被清理
+	clock.Advance(time.Second*2 + time.Millisecond*500)
+	assert.Equal(t, BucketSnapshot{Start: time.Second * 106}, rw.BucketAt(0))
+	assert.Equal(t, BucketSnapshot{Start: time.Second * 105}, rw.BucketAt(1))
+	assert.Equal(t, BucketSnapshot{Bucket: Bucket{Sum: 5, Count: 5}, Start: time.Second * 104, Active: true},
+		rw.BucketAt(2))
+	// 清理过期的桶前后结果一致, 与 Snapshot 的顺序相反
+	before := []BucketSnapshot{rw.BucketAt(0), rw.BucketAt(1), rw.BucketAt(2)}
+	snapshot = rw.Snapshot()
+	for i := range snapshot {
+		assert.Equal(t, snapshot[len(snapshot)-1-i], before[i])
+		assert.Equal(t, before[i], rw.BucketAt(i))
+	}
+	assert.Equal(t, 5.0, rw.Sum())
+
+	// 清理之后新写入的数据在当前桶
+	rw.Add(6)
+	assert.Equal(t, BucketSnapshot{Bucket: Bucket{Sum: 6, Count: 1}, Start: time.Second * 106, Active: true},
+		rw.BucketAt(0))
+	assert.Equal(t, BucketSnapshot{Start: time.Second * 105}, rw.BucketAt(1))
+
+	// 整个窗口都过期
+	clock.Advance(time.Second * 10)
+	for i := 0; i < 3; i++ {
+		b := rw.BucketAt(i)
+		assert.False(t, b.Active)
+		assert.Equal(t, int64(0), b.Count)
+		assert.Equal(t, time.Second*time.Duration(116-i), b.Start)
+	}
+}
+
+func TestRollingWindowBucketAtInvalid(t *testing.T) {
+	rw := NewRollingWindow(3, time.Second)
+	assert.Panics(t, func() {
+		rw.BucketAt(-1)
+	})
+	assert.Panics(t, func() {
+		rw.BucketAt(3)
+	})
+	assert.NotPanics(t, func() {
+		rw.BucketAt(2)
+	})
+}
+
+func TestRollingWindowConcurrentBucketAt(t *testing.T) {
+	rw := NewRollingWindow(10, time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				rw.Add(1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				_ = rw.BucketAt(j % 10)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestRollingWindowPrewarm(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(4, time.Second, WithWindowClock(clock), WithPrewarm(7, 10))
+	assert.Equal(t, 7.0, rw.Sum())
+	assert.Equal(t, int64(10), rw.Count())
+	// 除不尽时每个桶的成功数也不超过总数
+	for _, b := range rw.Snapshot() {
+		assert.LessOrEqual(t, b.Sum, float64(b.Count))
+	}
+
+	// 预热数据随窗口滑动逐步过期, 不影响新写入的数据
+	clock.Advance(time.Second)
+	rw.Add(1)
+	assert.Less(t, rw.Count(), int64(11))
+	assert.Greater(t, rw.Count(), int64(1))
+
+	// 一个完整的窗口周期之后只剩新写入的数据
+	clock.Advance(time.Second * 3)
+	assert.Equal(t, 1.0, rw.Sum())
+	assert.Equal(t, int64(1), rw.Count())
+	clock.Advance(time.Second)
+	assert.Equal(t, 0.0, rw.Sum())
+	assert.Equal(t, int64(0), rw.Count())
+}
+
+func TestRollingWindowPrewarmFullCycle(t *testing.T) {
+	clock := timex.NewMockClock(0)
+	rw := NewRollingWindow(10, time.Second, WithWindowClock(clock), WithPrewarm(100, 100))
+	assert.Equal(t, 100.0, rw.Sum())
+	assert.Equal(t, int64(100), rw.Count())
+	// 预热数据视为从最旧的桶开始写入, 当前桶刚开始, 覆盖了9秒
+	assert.InDelta(t, 100.0/9, rw.Rate(), 1e-9)
+
+	clock.Advance(time.Second * 10)
+	assert.Equal(t, 0.0, rw.Sum())
+	assert.Equal(t, int64(0), rw.Count())
+
+	// 没有预热数据时与不设置一样
+	empty := NewRollingWindow(10, time.Second, WithPrewarm(0, 0))
+	assert.Equal(t, int64(0), empty.Count())
+	assert.Equal(t, 0.0, empty.Rate())
+}
+
+func TestWithPrewarmInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		WithPrewarm(0, -1)
+	})
+	assert.Panics(t, func() {
+		WithPrewarm(2, 1)
+	})
+	assert.Panics(t, func() {
+		WithPrewarm(-1, 1)
+	})
+	assert.NotPanics(t, func() {
+		WithPrewarm(1, 1)
+	})
+}
@@ -0,0 +1,59 @@
+package collection
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// 滑动窗口的调试输出, 例如排查熔断器为什么打开时打印窗口内的原始数据
+// 与 Snapshot 共用同一份拷贝逻辑, 过期的桶输出为0
+
+// 序列化时每个桶的内容, 按时间从旧到新排列
+type bucketDump struct {
+	Index int `json:"index"`
+	// 距离桶的开始时间过了多久
+	AgeMs   int64   `json:"ageMs"`
+	Sum     float64 `json:"sum"`
+	Count   int64   `json:"count"`
+	Current bool    `json:"current"`
+}
+
+// MarshalJSON 输出窗口内所有的桶, 包括当前桶, 不受 IgnoreCurrentBucket 影响
+func (rw *RollingWindowOf[B]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(rw.dump())
+}
+
+// String 每行输出一个桶, 内容与 MarshalJSON 一致
+func (rw *RollingWindowOf[B]) String() string {
+	buckets := rw.dump()
+	lines := make([]string, 0, len(buckets))
+	for _, b := range buckets {
+		line := fmt.Sprintf("#%d age=%dms sum=%g count=%d", b.Index, b.AgeMs, b.Sum, b.Count)
+		if b.Current {
+			line += " current"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (rw *RollingWindowOf[B]) dump() []bucketDump {
+	rw.lock.Lock()
+	defer rw.lock.Unlock()
+
+	snapshot := rw.snapshotLocked(false)
+	// 在锁内取时间, 保证age与清理过期桶时的时间一致
+	now := rw.clock.Now()
+	buckets := make([]bucketDump, 0, len(snapshot))
+	for i, b := range snapshot {
+		buckets = append(buckets, bucketDump{
+			Index:   i,
+			AgeMs:   (now - b.Start).Milliseconds(),
+			Sum:     b.Sum,
+			Count:   b.Count,
+			Current: i == len(snapshot)-1,
+		})
+	}
+	return buckets
+}
@@ -0,0 +1,87 @@
+package collection
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/timex"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newDumpTestWindow(opts ...RollingWindowOption) (*RollingWindow, *timex.MockClock) {
+	clock := timex.NewMockClock(time.Second * 100)
+	rw := NewRollingWindow(3, time.Second, append(opts, WithWindowClock(clock))...)
+	// 写满并回绕, 中间有一个空桶, 最早写入的1、2已经过期
+	for _, v := range []float64{1, 2, 3} {
+		rw.Add(v)
+		clock.Advance(time.Second)
+	}
+	clock.Advance(time.Second)
+	rw.AddN(5, 2)
+	clock.Advance(time.Millisecond * 500)
+	return rw, clock
+}
+
+func TestRollingWindowMarshalJSON(t *testing.T) {
+	const golden = `[` +
+		`{"index":0,"ageMs":2500,"sum":3,"count":1,"current":false},` +
+		`{"index":1,"ageMs":1500,"sum":0,"count":0,"current":false},` +
+		`{"index":2,"ageMs":500,"sum":5,"count":2,"current":true}` +
+		`]`
+
+	rw, _ := newDumpTestWindow()
+	data, err := json.Marshal(rw)
+	assert.Nil(t, err)
+	assert.Equal(t, golden, string(data))
+
+	// 忽略当前桶时同样输出当前桶
+	ignored, _ := newDumpTestWindow(IgnoreCurrentBucket())
+	data, err = json.Marshal(ignored)
+	assert.Nil(t, err)
+	assert.Equal(t, golden, string(data))
+}
+
+func TestRollingWindowMarshalJSONExpired(t *testing.T) {
+	rw, clock := newDumpTestWindow()
+	clock.Advance(time.Second * 10)
+	data, err := json.Marshal(rw)
+	assert.Nil(t, err)
+	assert.Equal(t, `[`+
+		`{"index":0,"ageMs":2500,"sum":0,"count":0,"current":false},`+
+		`{"index":1,"ageMs":1500,"sum":0,"count":0,"current":false},`+
+		`{"index":2,"ageMs":500,"sum":0,"count":0,"current":true}`+
+		`]`, string(data))
+}
+
+func TestRollingWindowString(t *testing.T) {
+	rw, _ := newDumpTestWindow()
+	assert.Equal(t, "#0 age=2500ms sum=3 count=1\n"+
+		"#1 age=1500ms sum=0 count=0\n"+
+		"#2 age=500ms sum=5 count=2 current", rw.String())
+}
+
+func TestRollingWindowConcurrentMarshalJSON(t *testing.T) {
+	rw := NewRollingWindow(10, time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				rw.Add(1)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				data, err := json.Marshal(rw)
+				assert.Nil(t, err)
+				var buckets []bucketDump
+				assert.Nil(t, json.Unmarshal(data, &buckets))
+				assert.Len(t, buckets, 10)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,95 @@
+package collection
+
+import "sync"
+
+// 删除的key超过该数量, 并且超过峰值容量的一半时才迁移, 避免小map频繁迁移
+const safeMapCopyThreshold = 10000
+
+// SafeMap 并发安全的map, 删除的key足够多时迁移到新的map
+// Go的map只会扩容不会缩容, 长期存在且频繁增删的map即使清空之后也会一直占用峰值时的内存
+// Range 期间持有读锁, 在 Range 的回调中调用 Set 或 Del 会死锁
+type SafeMap[K comparable, V any] struct {
+	lock sync.RWMutex
+	data map[K]V
+	// 上次迁移之后的最大元素个数, 近似map已经分配的容量
+	peak int
+	// 上次迁移之后删除的key的个数
+	deleted int
+	// 迁移的次数, 用于测试
+	migrations int
+}
+
+// NewSafeMap 创建空的 SafeMap
+func NewSafeMap[K comparable, V any]() *SafeMap[K, V] {
+	return &SafeMap[K, V]{
+		data: make(map[K]V),
+	}
+}
+
+// Set 写入key对应的值
+func (m *SafeMap[K, V]) Set(key K, value V) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.data[key] = value
+	if len(m.data) > m.peak {
+		m.peak = len(m.data)
+	}
+}
+
+// Get 读取key对应的值
+func (m *SafeMap[K, V]) Get(key K) (V, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	value, ok := m.data[key]
+	return value, ok
+}
+
+// Del 删除key, key不存在时什么都不做
+func (m *SafeMap[K, V]) Del(key K) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if _, ok := m.data[key]; !ok {
+		return
+	}
+
+	delete(m.data, key)
+	m.deleted++
+	if m.deleted > safeMapCopyThreshold && m.deleted > m.peak/2 {
+		m.migrate()
+	}
+}
+
+// Size 返回元素个数
+func (m *SafeMap[K, V]) Size() int {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+	return len(m.data)
+}
+
+// Range 遍历所有元素, fn返回false时停止遍历
+// 遍历期间持有读锁, 与迁移互斥, fn中调用 Set 或 Del 会死锁
+func (m *SafeMap[K, V]) Range(fn func(key K, value V) bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	for key, value := range m.data {
+		if !fn(key, value) {
+			return
+		}
+	}
+}
+
+// 调用方需要持有写锁, 把剩余的元素复制到新的map, 旧的map整个交给GC回收
+func (m *SafeMap[K, V]) migrate() {
+	data := make(map[K]V, len(m.data))
+	for key, value := range m.data {
+		data[key] = value
+	}
+	m.data = data
+	m.peak = len(data)
+	m.deleted = 0
+	m.migrations++
+}
@@ -0,0 +1,145 @@
+package collection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestSafeMap(t *testing.T) {
+	m := NewSafeMap[string, int]()
+	m.Set("a", 1)
+	m.Set("b", 2)
+	m.Set("a", 3)
+	assert.Equal(t, 2, m.Size())
+
+	v, ok := m.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, 3, v)
+	_, ok = m.Get("c")
+	assert.False(t, ok)
+
+	m.Del("a")
+	m.Del("not-exist")
+	assert.Equal(t, 1, m.Size())
+	assert.Equal(t, 1, m.deleted)
+	_, ok = m.Get("a")
+	assert.False(t, ok)
+}
+
+func TestSafeMapRange(t *testing.T) {
+	m := NewSafeMap[int, int]()
+	for i := 0; i < 10; i++ {
+		m.Set(i, i*i)
+	}
+
+	seen := make(map[int]int)
+	m.Range(func(key, value int) bool {
+		seen[key] = value
+		return true
+	})
+	assert.Len(t, seen, 10)
+	assert.Equal(t, 81, seen[9])
+
+	var visited int
+	m.Range(func(key, value int) bool {
+		visited++
+		return visited < 3
+	})
+	assert.Equal(t, 3, visited)
+}
+
+func TestSafeMapMigrate(t *testing.T) {
+	const total = 1000000
+	m := NewSafeMap[int, []byte]()
+	for i := 0; i < total; i++ {
+		m.Set(i, nil)
+	}
+	// 少量删除不会迁移
+	for i := 0; i < safeMapCopyThreshold; i++ {
+		m.Del(i)
+	}
+	assert.Equal(t, 0, m.migrations)
+
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	for i := safeMapCopyThreshold; i < total; i++ {
+		m.Del(i)
+	}
+	assert.Equal(t, 0, m.Size())
+	assert.Greater(t, m.migrations, 0)
+	assert.LessOrEqual(t, m.peak, safeMapCopyThreshold*2)
+
+	// 旧的map已经被替换, 内存可以被回收
+	runtime.GC()
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	assert.Less(t, after.HeapInuse, before.HeapInuse)
+
+	m.Set(1, []byte("ok"))
+	v, ok := m.Get(1)
+	assert.True(t, ok)
+	assert.Equal(t, []byte("ok"), v)
+}
+
+func TestSafeMapMigrateKeepsEntries(t *testing.T) {
+	m := NewSafeMap[int, int]()
+	for i := 0; i < safeMapCopyThreshold*4; i++ {
+		m.Set(i, i)
+	}
+	// 删除偶数key, 超过一半时迁移, 剩下的奇数key不受影响
+	for i := 0; i < safeMapCopyThreshold*4; i += 2 {
+		m.Del(i)
+	}
+	for i := 1; i < safeMapCopyThreshold; i += 2 {
+		m.Del(i)
+	}
+	assert.Greater(t, m.migrations, 0)
+	assert.Equal(t, safeMapCopyThreshold*2-safeMapCopyThreshold/2, m.Size())
+	for i := safeMapCopyThreshold + 1; i < safeMapCopyThreshold*4; i += 2 {
+		v, ok := m.Get(i)
+		assert.True(t, ok)
+		assert.Equal(t, i, v)
+	}
+}
+
+func TestSafeMapConcurrent(t *testing.T) {
+	m := NewSafeMap[int, int]()
+	const (
+		writers = 8
+		keys    = safeMapCopyThreshold * 2
+	)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < keys; j++ {
+				key := i*keys + j
+				m.Set(key, key)
+				if v, ok := m.Get(key); assert.True(t, ok) {
+					assert.Equal(t, key, v)
+				}
+				m.Del(key)
+			}
+		}(i)
+	}
+	// 迁移的同时遍历
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			m.Range(func(key, value int) bool {
+				assert.Equal(t, key, value)
+				return true
+			})
+		}
+	}()
+	wg.Wait()
+
+	assert.Equal(t, 0, m.Size())
+	assert.Greater(t, m.migrations, 0)
+}
@@ -0,0 +1,161 @@
+package collection
+
+import "sync"
+
+// 集合, Set 非并发安全, 需要并发访问时使用 SyncSet
+
+type (
+	Set[T comparable] struct {
+		data map[T]struct{}
+	}
+
+	SyncSet[T comparable] struct {
+		lock sync.RWMutex
+		set  *Set[T]
+	}
+)
+
+// NewSet 创建集合, 并加入items
+func NewSet[T comparable](items ...T) *Set[T] {
+	s := &Set[T]{
+		data: make(map[T]struct{}, len(items)),
+	}
+	s.Add(items...)
+	return s
+}
+
+func (s *Set[T]) Add(items ...T) {
+	for _, item := range items {
+		s.data[item] = struct{}{}
+	}
+}
+
+func (s *Set[T]) Remove(items ...T) {
+	for _, item := range items {
+		delete(s.data, item)
+	}
+}
+
+func (s *Set[T]) Contains(item T) bool {
+	_, ok := s.data[item]
+	return ok
+}
+
+// Keys 返回所有元素, 顺序不固定
+func (s *Set[T]) Keys() []T {
+	keys := make([]T, 0, len(s.data))
+	for key := range s.data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (s *Set[T]) Count() int {
+	return len(s.data)
+}
+
+// Union 返回并集, 不修改s和other
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	result := NewSet[T]()
+	for key := range s.data {
+		result.data[key] = struct{}{}
+	}
+	for key := range other.data {
+		result.data[key] = struct{}{}
+	}
+	return result
+}
+
+// Intersect 返回交集, 不修改s和other
+func (s *Set[T]) Intersect(other *Set[T]) *Set[T] {
+	small, large := s, other
+	if small.Count() > large.Count() {
+		small, large = large, small
+	}
+
+	result := NewSet[T]()
+	for key := range small.data {
+		if large.Contains(key) {
+			result.data[key] = struct{}{}
+		}
+	}
+	return result
+}
+
+// Diff 返回在s中但不在other中的元素, 不修改s和other
+func (s *Set[T]) Diff(other *Set[T]) *Set[T] {
+	result := NewSet[T]()
+	for key := range s.data {
+		if !other.Contains(key) {
+			result.data[key] = struct{}{}
+		}
+	}
+	return result
+}
+
+func (s *Set[T]) clone() *Set[T] {
+	return NewSet[T]().Union(s)
+}
+
+// NewSyncSet 创建并发安全的集合, 并加入items
+func NewSyncSet[T comparable](items ...T) *SyncSet[T] {
+	return &SyncSet[T]{
+		set: NewSet(items...),
+	}
+}
+
+func (s *SyncSet[T]) Add(items ...T) {
+	s.lock.Lock()
+	s.set.Add(items...)
+	s.lock.Unlock()
+}
+
+func (s *SyncSet[T]) Remove(items ...T) {
+	s.lock.Lock()
+	s.set.Remove(items...)
+	s.lock.Unlock()
+}
+
+func (s *SyncSet[T]) Contains(item T) bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.set.Contains(item)
+}
+
+func (s *SyncSet[T]) Keys() []T {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.set.Keys()
+}
+
+func (s *SyncSet[T]) Count() int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.set.Count()
+}
+
+func (s *SyncSet[T]) Union(other *SyncSet[T]) *SyncSet[T] {
+	a, b := s.snapshot(other)
+	return &SyncSet[T]{set: a.Union(b)}
+}
+
+func (s *SyncSet[T]) Intersect(other *SyncSet[T]) *SyncSet[T] {
+	a, b := s.snapshot(other)
+	return &SyncSet[T]{set: a.Intersect(b)}
+}
+
+func (s *SyncSet[T]) Diff(other *SyncSet[T]) *SyncSet[T] {
+	a, b := s.snapshot(other)
+	return &SyncSet[T]{set: a.Diff(b)}
+}
+
+// 分别拷贝两个集合, 避免同时持有两把锁, 两个集合互相运算时死锁
+func (s *SyncSet[T]) snapshot(other *SyncSet[T]) (*Set[T], *Set[T]) {
+	return s.copy(), other.copy()
+}
+
+func (s *SyncSet[T]) copy() *Set[T] {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.set.clone()
+}
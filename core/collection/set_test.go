@@ -0,0 +1,76 @@
+package collection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func TestSet(t *testing.T) {
+	s := NewSet("user-rpc", "order-rpc")
+	s.Add("user-rpc", "pay-rpc")
+	assert.Equal(t, 3, s.Count())
+	assert.True(t, s.Contains("pay-rpc"))
+	assert.ElementsMatch(t, []string{"user-rpc", "order-rpc", "pay-rpc"}, s.Keys())
+
+	s.Remove("order-rpc", "not-exist")
+	assert.False(t, s.Contains("order-rpc"))
+	assert.Equal(t, 2, s.Count())
+}
+
+func TestSetAlgebra(t *testing.T) {
+	a := NewSet(1, 2, 3, 4)
+	b := NewSet(3, 4, 5)
+
+	assert.ElementsMatch(t, []int{1, 2, 3, 4, 5}, a.Union(b).Keys())
+	assert.ElementsMatch(t, []int{3, 4}, a.Intersect(b).Keys())
+	assert.ElementsMatch(t, []int{3, 4}, b.Intersect(a).Keys())
+	assert.ElementsMatch(t, []int{1, 2}, a.Diff(b).Keys())
+	assert.ElementsMatch(t, []int{5}, b.Diff(a).Keys())
+
+	empty := NewSet[int]()
+	assert.Equal(t, 4, a.Union(empty).Count())
+	assert.Equal(t, 0, a.Intersect(empty).Count())
+	assert.Equal(t, 4, a.Diff(empty).Count())
+
+	// 运算不修改原集合
+	assert.ElementsMatch(t, []int{1, 2, 3, 4}, a.Keys())
+	assert.ElementsMatch(t, []int{3, 4, 5}, b.Keys())
+}
+
+func TestSyncSetAlgebra(t *testing.T) {
+	a := NewSyncSet("a", "b", "c")
+	b := NewSyncSet("b", "c", "d")
+
+	assert.ElementsMatch(t, []string{"a", "b", "c", "d"}, a.Union(b).Keys())
+	assert.ElementsMatch(t, []string{"b", "c"}, a.Intersect(b).Keys())
+	assert.ElementsMatch(t, []string{"a"}, a.Diff(b).Keys())
+	assert.Equal(t, 3, a.Union(a).Count())
+}
+
+func TestSyncSetConcurrent(t *testing.T) {
+	a := NewSyncSet[int]()
+	b := NewSyncSet[int]()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				a.Add(i*100 + j)
+				b.Add(j)
+				_ = a.Contains(j)
+				_ = a.Union(b)
+				_ = b.Intersect(a)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1000, a.Count())
+	assert.Equal(t, 100, b.Count())
+	assert.Equal(t, 100, a.Intersect(b).Count())
+
+	a.Remove(a.Keys()...)
+	assert.Equal(t, 0, a.Count())
+}
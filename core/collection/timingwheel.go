@@ -0,0 +1,251 @@
+package collection
+
+import (
+	"container/list"
+	"errors"
+	"go-zero-/core/timex"
+	"sync"
+	"time"
+)
+
+// 时间轮
+// 所有定时任务共享一个ticker和一个goroutine, 避免每个任务都分配一个time.Timer
+// 时间轮的状态只在run goroutine中读写, 外部操作都通过channel投递, 因此无需加锁
+
+var (
+	ErrClosed   = errors.New("TimingWheel is closed already")
+	ErrArgument = errors.New("incorrect task argument")
+)
+
+type (
+	// Execute 任务到期时执行的函数
+	Execute func(key, value any)
+
+	TimingWheel struct {
+		// 每个槽位代表的时间间隔
+		interval time.Duration
+		ticker   timex.Ticker
+		// 槽位, 每个槽位是一个任务链表
+		slots []*list.List
+		// key -> 任务在时间轮中的位置, 用于移动和删除
+		timers map[any]*timerPosition
+		// 最近一次处理过的槽位
+		tickedPos int
+		numSlots  int
+		execute   Execute
+
+		setChannel    chan timingEntry
+		moveChannel   chan baseEntry
+		removeChannel chan any
+		stopChannel   chan struct{}
+		// run goroutine退出时关闭
+		doneChannel chan struct{}
+		stopOnce    sync.Once
+		// 正在执行的到期任务
+		executing sync.WaitGroup
+	}
+
+	baseEntry struct {
+		delay time.Duration
+		key   any
+	}
+
+	timingEntry struct {
+		baseEntry
+		value any
+		// 还需要转多少圈才到期, 用于支持超过一圈的延迟
+		circle int
+	}
+
+	timerPosition struct {
+		pos  int
+		item *list.Element
+	}
+)
+
+// NewTimingWheel 创建时间轮, 一圈的时长为 interval*numSlots
+func NewTimingWheel(interval time.Duration, numSlots int, execute Execute) (*TimingWheel, error) {
+	if interval <= 0 || numSlots <= 0 || execute == nil {
+		return nil, ErrArgument
+	}
+
+	return newTimingWheelWithTicker(interval, numSlots, execute, timex.NewTicker(interval)), nil
+}
+
+// 测试时传入 timex.FakeTicker 手动驱动时间轮
+func newTimingWheelWithTicker(interval time.Duration, numSlots int, execute Execute,
+	ticker timex.Ticker) *TimingWheel {
+	tw := &TimingWheel{
+		interval:      interval,
+		ticker:        ticker,
+		slots:         make([]*list.List, numSlots),
+		timers:        make(map[any]*timerPosition),
+		tickedPos:     numSlots - 1, // 第一次tick处理的是0号槽位
+		numSlots:      numSlots,
+		execute:       execute,
+		setChannel:    make(chan timingEntry),
+		moveChannel:   make(chan baseEntry),
+		removeChannel: make(chan any),
+		stopChannel:   make(chan struct{}),
+		doneChannel:   make(chan struct{}),
+	}
+	for i := 0; i < numSlots; i++ {
+		tw.slots[i] = list.New()
+	}
+
+	go tw.run()
+
+	return tw
+}
+
+// SetTimer 添加定时任务, key已存在时覆盖原任务
+func (tw *TimingWheel) SetTimer(key, value any, delay time.Duration) error {
+	if delay < 0 {
+		return ErrArgument
+	}
+
+	select {
+	case tw.setChannel <- timingEntry{
+		baseEntry: baseEntry{
+			delay: delay,
+			key:   key,
+		},
+		value: value,
+	}:
+		return nil
+	case <-tw.stopChannel:
+		return ErrClosed
+	}
+}
+
+// MoveTimer 将key对应的任务改为从现在开始delay后执行, key不存在时忽略
+func (tw *TimingWheel) MoveTimer(key any, delay time.Duration) error {
+	if delay < 0 {
+		return ErrArgument
+	}
+
+	select {
+	case tw.moveChannel <- baseEntry{
+		delay: delay,
+		key:   key,
+	}:
+		return nil
+	case <-tw.stopChannel:
+		return ErrClosed
+	}
+}
+
+// RemoveTimer 删除key对应的任务
+func (tw *TimingWheel) RemoveTimer(key any) error {
+	select {
+	case tw.removeChannel <- key:
+		return nil
+	case <-tw.stopChannel:
+		return ErrClosed
+	}
+}
+
+// Stop 停止时间轮, 未到期的任务不再执行, 可以重复调用
+// 返回时已经到期的任务都执行完毕, 之后不会再调用execute, 因此不能在execute中调用
+func (tw *TimingWheel) Stop() {
+	tw.stopOnce.Do(func() {
+		close(tw.stopChannel)
+	})
+	<-tw.doneChannel
+	tw.executing.Wait()
+}
+
+func (tw *TimingWheel) run() {
+	defer close(tw.doneChannel)
+
+	for {
+		select {
+		case <-tw.ticker.Chan():
+			tw.onTick()
+		case task := <-tw.setChannel:
+			tw.setTask(task)
+		case task := <-tw.moveChannel:
+			tw.moveTask(task)
+		case key := <-tw.removeChannel:
+			tw.removeTask(key)
+		case <-tw.stopChannel:
+			tw.ticker.Stop()
+			return
+		}
+	}
+}
+
+func (tw *TimingWheel) onTick() {
+	tw.tickedPos = (tw.tickedPos + 1) % tw.numSlots
+	l := tw.slots[tw.tickedPos]
+
+	var expired []timingEntry
+	for e := l.Front(); e != nil; {
+		next := e.Next()
+		task := e.Value.(*timingEntry)
+		if task.circle > 0 {
+			task.circle--
+		} else {
+			expired = append(expired, *task)
+			l.Remove(e)
+			delete(tw.timers, task.key)
+		}
+		e = next
+	}
+
+	if len(expired) > 0 {
+		// 同一个tick到期的任务放在一个goroutine中按加入的顺序执行, 不阻塞时间轮
+		tw.executing.Add(1)
+		go func() {
+			defer tw.executing.Done()
+			for _, task := range expired {
+				tw.execute(task.key, task.value)
+			}
+		}()
+	}
+}
+
+func (tw *TimingWheel) setTask(task timingEntry) {
+	tw.removeTask(task.key)
+
+	pos, circle := tw.position(task.delay)
+	task.circle = circle
+	tw.timers[task.key] = &timerPosition{
+		pos:  pos,
+		item: tw.slots[pos].PushBack(&task),
+	}
+}
+
+func (tw *TimingWheel) moveTask(task baseEntry) {
+	position, ok := tw.timers[task.key]
+	if !ok {
+		return
+	}
+
+	entry := position.item.Value.(*timingEntry)
+	entry.delay = task.delay
+	tw.setTask(*entry)
+}
+
+func (tw *TimingWheel) removeTask(key any) {
+	position, ok := tw.timers[key]
+	if !ok {
+		return
+	}
+
+	tw.slots[position.pos].Remove(position.item)
+	delete(tw.timers, key)
+}
+
+// 计算任务应放入的槽位以及需要转的圈数
+func (tw *TimingWheel) position(delay time.Duration) (pos, circle int) {
+	steps := int(delay / tw.interval)
+	// 至少等待一个tick, 否则会被放到已经处理过的槽位上, 要等一整圈才执行
+	if steps < 1 {
+		steps = 1
+	}
+
+	pos = (tw.tickedPos + steps) % tw.numSlots
+	circle = (steps - 1) / tw.numSlots
+	return
+}
@@ -0,0 +1,261 @@
+package collection
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/timex"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testTick = time.Millisecond * 10
+
+type firedTasks struct {
+	lock  sync.Mutex
+	fired map[any]time.Time
+}
+
+func newFiredTasks() *firedTasks {
+	return &firedTasks{
+		fired: make(map[any]time.Time),
+	}
+}
+
+func (f *firedTasks) execute(key, _ any) {
+	f.lock.Lock()
+	f.fired[key] = time.Now()
+	f.lock.Unlock()
+}
+
+func (f *firedTasks) get(key any) (time.Time, bool) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	t, ok := f.fired[key]
+	return t, ok
+}
+
+func TestNewTimingWheelInvalidArgs(t *testing.T) {
+	_, err := NewTimingWheel(0, 10, func(key, value any) {})
+	assert.Equal(t, ErrArgument, err)
+	_, err = NewTimingWheel(testTick, 0, func(key, value any) {})
+	assert.Equal(t, ErrArgument, err)
+	_, err = NewTimingWheel(testTick, 10, nil)
+	assert.Equal(t, ErrArgument, err)
+}
+
+func TestTimingWheelSetTimer(t *testing.T) {
+	fired := newFiredTasks()
+	tw, err := NewTimingWheel(testTick, 10, fired.execute)
+	assert.Nil(t, err)
+	defer tw.Stop()
+
+	start := time.Now()
+	// 其中一个延迟超过一圈
+	assert.Nil(t, tw.SetTimer("a", 1, testTick*5))
+	assert.Nil(t, tw.SetTimer("b", 2, testTick*15))
+
+	time.Sleep(testTick * 25)
+	at, ok := fired.get("a")
+	assert.True(t, ok)
+	assert.InDelta(t, float64(testTick*5), float64(at.Sub(start)), float64(testTick*3))
+	bt, ok := fired.get("b")
+	assert.True(t, ok)
+	assert.InDelta(t, float64(testTick*15), float64(bt.Sub(start)), float64(testTick*3))
+}
+
+func TestTimingWheelMoveTimer(t *testing.T) {
+	fired := newFiredTasks()
+	tw, err := NewTimingWheel(testTick, 10, fired.execute)
+	assert.Nil(t, err)
+	defer tw.Stop()
+
+	start := time.Now()
+	assert.Nil(t, tw.SetTimer("a", 1, testTick*3))
+	assert.Nil(t, tw.MoveTimer("a", testTick*12))
+	// 不存在的key忽略
+	assert.Nil(t, tw.MoveTimer("b", testTick))
+
+	time.Sleep(testTick * 6)
+	_, ok := fired.get("a")
+	assert.False(t, ok)
+
+	time.Sleep(testTick * 12)
+	at, ok := fired.get("a")
+	assert.True(t, ok)
+	assert.InDelta(t, float64(testTick*12), float64(at.Sub(start)), float64(testTick*3))
+}
+
+func TestTimingWheelRemoveTimer(t *testing.T) {
+	fired := newFiredTasks()
+	tw, err := NewTimingWheel(testTick, 10, fired.execute)
+	assert.Nil(t, err)
+	defer tw.Stop()
+
+	assert.Nil(t, tw.SetTimer("a", 1, testTick*3))
+	assert.Nil(t, tw.RemoveTimer("a"))
+
+	time.Sleep(testTick * 6)
+	_, ok := fired.get("a")
+	assert.False(t, ok)
+}
+
+func TestTimingWheelStop(t *testing.T) {
+	tw, err := NewTimingWheel(testTick, 10, func(key, value any) {})
+	assert.Nil(t, err)
+	tw.Stop()
+
+	assert.Equal(t, ErrClosed, tw.SetTimer("a", 1, testTick))
+	assert.Equal(t, ErrClosed, tw.MoveTimer("a", testTick))
+	assert.Equal(t, ErrClosed, tw.RemoveTimer("a"))
+}
+
+// 使用 timex.FakeTicker 驱动的时间轮, 到期的key依次写入返回的channel
+func newFakeTimingWheel(t *testing.T, numSlots int) (*TimingWheel, *timex.FakeTicker, chan any) {
+	ticker := timex.NewFakeTicker()
+	fired := make(chan any, 100)
+	tw := newTimingWheelWithTicker(testTick, numSlots, func(key, _ any) {
+		fired <- key
+	}, ticker)
+	t.Cleanup(tw.Stop)
+	return tw, ticker, fired
+}
+
+// 读取接下来到期的key, 没有按时到期时失败
+func nextFired(t *testing.T, fired chan any) any {
+	select {
+	case key := <-fired:
+		return key
+	case <-time.After(time.Second):
+		t.Fatal("timer not fired")
+		return nil
+	}
+}
+
+func assertNoneFired(t *testing.T, fired chan any) {
+	select {
+	case key := <-fired:
+		t.Fatalf("unexpected timer %v fired", key)
+	case <-time.After(testTick):
+	}
+}
+
+func TestTimingWheelFiringOrder(t *testing.T) {
+	tw, ticker, fired := newFakeTimingWheel(t, 4)
+
+	// 延迟以tick为单位, 4个槽位, 超过一圈的延迟需要多转几圈
+	delays := map[string]int{
+		"zero":   0,
+		"one":    1,
+		"three":  3,
+		"four":   4,
+		"five":   5,
+		"nine":   9,
+		"twelve": 12,
+		"extra":  13,
+	}
+	// 同一个tick到期的任务按加入的顺序执行
+	assert.Nil(t, tw.SetTimer("one-b", nil, testTick))
+	for key, delay := range delays {
+		assert.Nil(t, tw.SetTimer(key, nil, testTick*time.Duration(delay)))
+	}
+	// 不足一个tick的部分被舍去
+	assert.Nil(t, tw.SetTimer("nine-b", nil, testTick*9+testTick/2))
+
+	expected := map[int][]string{
+		1:  {"one-b"},
+		3:  {"three"},
+		4:  {"four"},
+		5:  {"five"},
+		9:  {"nine", "nine-b"},
+		12: {"twelve"},
+		13: {"extra"},
+	}
+	// 延迟为0和1的任务都在第一个tick执行, 两者之间的顺序取决于map的遍历顺序
+	assert.True(t, ticker.Tick())
+	first := []any{nextFired(t, fired), nextFired(t, fired), nextFired(t, fired)}
+	assert.ElementsMatch(t, []any{"zero", "one", "one-b"}, first)
+	for tick := 2; tick <= 16; tick++ {
+		assert.True(t, ticker.Tick())
+		if len(expected[tick]) == 0 {
+			assertNoneFired(t, fired)
+			continue
+		}
+		for _, key := range expected[tick] {
+			assert.Equal(t, key, nextFired(t, fired), "tick %d", tick)
+		}
+	}
+}
+
+func TestTimingWheelMoveTimerBeforeExpiry(t *testing.T) {
+	tw, ticker, fired := newFakeTimingWheel(t, 4)
+	assert.Nil(t, tw.SetTimer("a", 1, testTick*2))
+	assert.Nil(t, tw.SetTimer("b", 2, testTick*10))
+	// 提前和推迟都从现在开始计算
+	assert.Nil(t, tw.MoveTimer("b", testTick))
+	assert.Nil(t, tw.MoveTimer("a", testTick*6))
+
+	assert.True(t, ticker.Tick())
+	assert.Equal(t, "b", nextFired(t, fired))
+	for i := 0; i < 4; i++ {
+		assert.True(t, ticker.Tick())
+	}
+	assertNoneFired(t, fired)
+	assert.True(t, ticker.Tick())
+	assert.Equal(t, "a", nextFired(t, fired))
+}
+
+func TestTimingWheelRemoveTimerBeforeExpiry(t *testing.T) {
+	tw, ticker, fired := newFakeTimingWheel(t, 4)
+	assert.Nil(t, tw.SetTimer("a", 1, testTick*2))
+	assert.Nil(t, tw.SetTimer("long", 2, testTick*9))
+	assert.Nil(t, tw.RemoveTimer("a"))
+	assert.Nil(t, tw.RemoveTimer("long"))
+	// 覆盖已有的任务
+	assert.Nil(t, tw.SetTimer("c", 3, testTick*3))
+	assert.Nil(t, tw.SetTimer("c", 3, testTick*5))
+
+	for i := 0; i < 10; i++ {
+		assert.True(t, ticker.Tick())
+	}
+	assert.Equal(t, "c", nextFired(t, fired))
+	assertNoneFired(t, fired)
+}
+
+func TestTimingWheelStopDrain(t *testing.T) {
+	ticker := timex.NewFakeTicker()
+	release := make(chan struct{})
+	var executed []any
+	var lock sync.Mutex
+	tw := newTimingWheelWithTicker(testTick, 4, func(key, _ any) {
+		<-release
+		lock.Lock()
+		executed = append(executed, key)
+		lock.Unlock()
+	}, ticker)
+
+	assert.Nil(t, tw.SetTimer("a", 1, testTick))
+	assert.Nil(t, tw.SetTimer("b", 2, testTick*3))
+	assert.True(t, ticker.Tick())
+
+	stopped := make(chan struct{})
+	go func() {
+		tw.Stop()
+		close(stopped)
+	}()
+	// 已经到期的任务执行完之前Stop不会返回
+	select {
+	case <-stopped:
+		t.Fatal("stop returned before expired timers finished")
+	case <-time.After(testTick):
+	}
+	close(release)
+	<-stopped
+
+	assert.True(t, ticker.Stopped())
+	// 未到期的任务不再执行, 重复Stop直接返回
+	tw.Stop()
+	lock.Lock()
+	assert.Equal(t, []any{"a"}, executed)
+	lock.Unlock()
+	assert.Equal(t, ErrClosed, tw.SetTimer("c", 3, testTick))
+}
@@ -0,0 +1,43 @@
+package mathx
+
+// Clamp 把v限制在 [min, max] 范围内, min大于max时panic
+func Clamp(v, min, max float64) float64 {
+	if min > max {
+		panic("min must not be greater than max")
+	}
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// ClampInt 同 Clamp, 用于int
+func ClampInt(v, min, max int) int {
+	if min > max {
+		panic("min must not be greater than max")
+	}
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
+
+// ClampInt64 同 Clamp, 用于int64
+func ClampInt64(v, min, max int64) int64 {
+	if min > max {
+		panic("min must not be greater than max")
+	}
+	if v < min {
+		return min
+	}
+	if v > max {
+		return max
+	}
+	return v
+}
@@ -0,0 +1,55 @@
+package mathx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestClamp(t *testing.T) {
+	tests := []struct {
+		v, min, max, want float64
+	}{
+		{-1, 0, 1, 0},
+		{0, 0, 1, 0},
+		{0.5, 0, 1, 0.5},
+		{1, 0, 1, 1},
+		{2, 0, 1, 1},
+		{3, 3, 3, 3},
+		{math.Inf(1), 0, 1, 1},
+		{math.Inf(-1), 0, 1, 0},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, Clamp(test.v, test.min, test.max), "Clamp(%v, %v, %v)", test.v, test.min, test.max)
+	}
+}
+
+func TestClampInt(t *testing.T) {
+	tests := []struct {
+		v, min, max, want int
+	}{
+		{-1, 0, 10, 0},
+		{0, 0, 10, 0},
+		{5, 0, 10, 5},
+		{10, 0, 10, 10},
+		{11, 0, 10, 10},
+		{3, 3, 3, 3},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, ClampInt(test.v, test.min, test.max))
+		assert.Equal(t, int64(test.want), ClampInt64(int64(test.v), int64(test.min), int64(test.max)))
+	}
+	assert.Equal(t, int64(math.MaxInt64), ClampInt64(math.MaxInt64, math.MinInt64, math.MaxInt64))
+}
+
+func TestClampMinGreaterThanMax(t *testing.T) {
+	assert.Panics(t, func() {
+		Clamp(0, 1, 0)
+	})
+	assert.Panics(t, func() {
+		ClampInt(0, 1, 0)
+	})
+	assert.Panics(t, func() {
+		ClampInt64(0, 1, 0)
+	})
+}
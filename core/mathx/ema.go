@@ -0,0 +1,65 @@
+package mathx
+
+import (
+	"math"
+	"sync/atomic"
+	"time"
+)
+
+// 没有数据时的位模式, 不会由浮点运算产生的NaN
+const emptyEMA uint64 = 0x7ff8000000000001
+
+// EMA 指数移动平均, value = alpha*v + (1-alpha)*value, 通过CAS更新, 不需要加锁
+type EMA struct {
+	alpha float64
+	// math.Float64bits 之后的值, 原子读写
+	bits uint64
+}
+
+// NewEMA 创建指数移动平均, alpha取值范围 (0, 1], alpha越大越偏向最新的数据
+func NewEMA(alpha float64) *EMA {
+	if !(alpha > 0 && alpha <= 1) {
+		panic("alpha must be in (0, 1]")
+	}
+	return &EMA{
+		alpha: alpha,
+		bits:  emptyEMA,
+	}
+}
+
+// NewEMAWithHalfLife 按时间跨度创建指数移动平均, 每隔interval加入一个数据, 即 alpha = 1 - exp(-interval/halfLife)
+// 经过halfLife之后旧数据的权重衰减为 1/e, 权重减半需要 halfLife*ln2
+func NewEMAWithHalfLife(halfLife, interval time.Duration) *EMA {
+	if halfLife <= 0 || interval <= 0 {
+		panic("halfLife and interval must be greater than 0")
+	}
+	return NewEMA(-math.Expm1(-float64(interval) / float64(halfLife)))
+}
+
+// Add 加入一个新的数据, 第一个数据直接作为平均值
+func (e *EMA) Add(v float64) {
+	for {
+		old := atomic.LoadUint64(&e.bits)
+		val := v
+		if old != emptyEMA {
+			val = e.alpha*v + (1-e.alpha)*math.Float64frombits(old)
+		}
+		if atomic.CompareAndSwapUint64(&e.bits, old, math.Float64bits(val)) {
+			return
+		}
+	}
+}
+
+// Value 返回当前的平均值, 没有数据时返回0
+func (e *EMA) Value() float64 {
+	bits := atomic.LoadUint64(&e.bits)
+	if bits == emptyEMA {
+		return 0
+	}
+	return math.Float64frombits(bits)
+}
+
+// Reset 清空数据, 下一次Add重新作为平均值
+func (e *EMA) Reset() {
+	atomic.StoreUint64(&e.bits, emptyEMA)
+}
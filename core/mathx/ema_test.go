@@ -0,0 +1,101 @@
+package mathx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEMAInvalidAlpha(t *testing.T) {
+	for _, alpha := range []float64{0, -0.1, 1.1, math.NaN()} {
+		assert.Panics(t, func() {
+			NewEMA(alpha)
+		}, alpha)
+	}
+	assert.NotPanics(t, func() {
+		NewEMA(1)
+	})
+}
+
+func TestEMAFirstValue(t *testing.T) {
+	e := NewEMA(0.1)
+	assert.Equal(t, 0.0, e.Value())
+	e.Add(100)
+	assert.Equal(t, 100.0, e.Value())
+}
+
+func TestEMAConverge(t *testing.T) {
+	const alpha = 0.2
+	e := NewEMA(alpha)
+	e.Add(0)
+
+	// 阶跃输入, 第n次之后与目标值的差距为 (1-alpha)^n
+	for n := 1; n <= 50; n++ {
+		e.Add(100)
+		assert.InDelta(t, 100-100*math.Pow(1-alpha, float64(n)), e.Value(), 1e-9)
+	}
+	assert.InDelta(t, 100, e.Value(), 1e-2)
+}
+
+func TestEMAReset(t *testing.T) {
+	e := NewEMA(0.5)
+	e.Add(10)
+	e.Add(20)
+	e.Reset()
+	assert.Equal(t, 0.0, e.Value())
+	e.Add(30)
+	assert.Equal(t, 30.0, e.Value())
+}
+
+func TestEMAWithHalfLife(t *testing.T) {
+	e := NewEMAWithHalfLife(time.Second*10, time.Second)
+	assert.InDelta(t, 1-math.Exp(-0.1), e.alpha, 1e-12)
+
+	// 经过halfLife, 与目标值的差距衰减为 1/e
+	e.Add(0)
+	for i := 0; i < 10; i++ {
+		e.Add(100)
+	}
+	assert.InDelta(t, 100-100/math.E, e.Value(), 1e-9)
+
+	assert.Panics(t, func() {
+		NewEMAWithHalfLife(0, time.Second)
+	})
+	assert.Panics(t, func() {
+		NewEMAWithHalfLife(time.Second, 0)
+	})
+}
+
+func TestEMAConcurrent(t *testing.T) {
+	e := NewEMA(1)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				e.Add(10)
+				_ = e.Value()
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 10.0, e.Value())
+
+	// 所有的更新都不会丢失, alpha为1/2时加入n个1, 结果为1-(1/2)^n
+	e = NewEMA(0.5)
+	e.Add(0)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 5; j++ {
+				e.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.InDelta(t, 1-math.Pow(0.5, 20), e.Value(), 1e-12)
+}
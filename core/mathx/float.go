@@ -0,0 +1,16 @@
+package mathx
+
+import "math"
+
+// SafeDiv 返回 a/b, b为0、NaN或者无穷大时返回0, 避免NaN和无穷大继续参与计算
+func SafeDiv(a, b float64) float64 {
+	if b == 0 || !IsFinite(b) {
+		return 0
+	}
+	return a / b
+}
+
+// IsFinite 返回v是否是有限的数, 即不是NaN也不是无穷大
+func IsFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
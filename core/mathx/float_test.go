@@ -0,0 +1,35 @@
+package mathx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestSafeDiv(t *testing.T) {
+	tests := []struct {
+		a, b, want float64
+	}{
+		{6, 3, 2},
+		{-1, 4, -0.25},
+		{0, 5, 0},
+		{1, 0, 0},
+		{0, 0, 0},
+		{1, math.Copysign(0, -1), 0},
+		{1, math.NaN(), 0},
+		{1, math.Inf(1), 0},
+		{1, math.Inf(-1), 0},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, SafeDiv(test.a, test.b), "SafeDiv(%v, %v)", test.a, test.b)
+	}
+}
+
+func TestIsFinite(t *testing.T) {
+	assert.True(t, IsFinite(0))
+	assert.True(t, IsFinite(-1.5))
+	assert.True(t, IsFinite(math.MaxFloat64))
+	assert.False(t, IsFinite(math.NaN()))
+	assert.False(t, IsFinite(math.Inf(1)))
+	assert.False(t, IsFinite(math.Inf(-1)))
+}
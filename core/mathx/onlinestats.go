@@ -0,0 +1,73 @@
+package mathx
+
+import (
+	"math"
+	"sync"
+)
+
+// OnlineStats 使用Welford算法在线计算均值和方差, 不需要保存所有的数据, 也不会因为平方和过大而丢失精度
+type OnlineStats struct {
+	lock  sync.Mutex
+	count int64
+	mean  float64
+	// 与均值之差的平方和
+	m2 float64
+}
+
+func NewOnlineStats() *OnlineStats {
+	return new(OnlineStats)
+}
+
+// Add 加入一个新的数据
+func (s *OnlineStats) Add(v float64) {
+	s.lock.Lock()
+	s.count++
+	delta := v - s.mean
+	s.mean += delta / float64(s.count)
+	s.m2 += delta * (v - s.mean)
+	s.lock.Unlock()
+}
+
+// Count 返回数据的个数
+func (s *OnlineStats) Count() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.count
+}
+
+// Mean 返回均值, 没有数据时返回0
+func (s *OnlineStats) Mean() float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.mean
+}
+
+// Variance 返回总体方差, 少于2个数据时返回0
+func (s *OnlineStats) Variance() float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.variance()
+}
+
+// StdDev 返回总体标准差
+func (s *OnlineStats) StdDev() float64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return math.Sqrt(s.variance())
+}
+
+// Reset 清空所有数据
+func (s *OnlineStats) Reset() {
+	s.lock.Lock()
+	s.count = 0
+	s.mean = 0
+	s.m2 = 0
+	s.lock.Unlock()
+}
+
+func (s *OnlineStats) variance() float64 {
+	if s.count < 2 {
+		return 0
+	}
+	return s.m2 / float64(s.count)
+}
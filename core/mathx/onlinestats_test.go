@@ -0,0 +1,107 @@
+package mathx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"sync"
+	"testing"
+)
+
+// 先求和再求平方和的参考实现
+func referenceStats(values []float64) (mean, variance float64) {
+	var sum, sumSquares float64
+	for _, v := range values {
+		sum += v
+		sumSquares += v * v
+	}
+	n := float64(len(values))
+	mean = sum / n
+	return mean, sumSquares/n - mean*mean
+}
+
+func TestOnlineStats(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	random := make([]float64, 1000)
+	for i := range random {
+		random[i] = r.NormFloat64()*20 + 100
+	}
+
+	tests := [][]float64{
+		{2, 4, 4, 4, 5, 5, 7, 9},
+		{1, 2, 3, 4, 5, 6, 7, 8, 9, 10},
+		{-3.5, 0, 3.5},
+		{42, 42, 42, 42},
+		random,
+	}
+	for _, values := range tests {
+		s := NewOnlineStats()
+		for _, v := range values {
+			s.Add(v)
+		}
+
+		mean, variance := referenceStats(values)
+		assert.Equal(t, int64(len(values)), s.Count())
+		assert.InDelta(t, mean, s.Mean(), 1e-9)
+		assert.InDelta(t, variance, s.Variance(), 1e-6)
+		assert.InDelta(t, math.Sqrt(variance), s.StdDev(), 1e-6)
+	}
+
+	// 均值为5, 方差为4
+	s := NewOnlineStats()
+	for _, v := range tests[0] {
+		s.Add(v)
+	}
+	assert.Equal(t, 5.0, s.Mean())
+	assert.Equal(t, 4.0, s.Variance())
+	assert.Equal(t, 2.0, s.StdDev())
+}
+
+// 数据带有很大的偏移时, 平方和的方式会严重丢失精度, Welford算法不受影响
+func TestOnlineStatsLargeOffset(t *testing.T) {
+	s := NewOnlineStats()
+	for _, v := range []float64{4, 7, 13, 16} {
+		s.Add(1e9 + v)
+	}
+	assert.InDelta(t, 1e9+10, s.Mean(), 1e-6)
+	assert.InDelta(t, 22.5, s.Variance(), 1e-6)
+}
+
+func TestOnlineStatsEmpty(t *testing.T) {
+	s := NewOnlineStats()
+	assert.Equal(t, int64(0), s.Count())
+	assert.Equal(t, 0.0, s.Mean())
+	assert.Equal(t, 0.0, s.Variance())
+	assert.Equal(t, 0.0, s.StdDev())
+
+	s.Add(3)
+	assert.Equal(t, 3.0, s.Mean())
+	assert.Equal(t, 0.0, s.Variance())
+
+	s.Add(5)
+	s.Reset()
+	assert.Equal(t, int64(0), s.Count())
+	assert.Equal(t, 0.0, s.Mean())
+	assert.Equal(t, 0.0, s.Variance())
+}
+
+func TestOnlineStatsConcurrent(t *testing.T) {
+	s := NewOnlineStats()
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				s.Add(float64(i))
+				_ = s.StdDev()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// 0到9各100个, 均值4.5, 方差8.25
+	assert.Equal(t, int64(1000), s.Count())
+	assert.InDelta(t, 4.5, s.Mean(), 1e-9)
+	assert.InDelta(t, 8.25, s.Variance(), 1e-9)
+}
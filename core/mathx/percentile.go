@@ -0,0 +1,46 @@
+package mathx
+
+import "math"
+
+// Percentile 返回已经升序排列的sorted的p分位数, p取值范围 [0, 1], 否则panic
+// 在相邻的两个数据之间线性插值, 即 Hyndman & Fan 的第7种方法, 与 numpy、Excel PERCENTILE.INC 的默认行为一致
+// sorted为空时返回NaN
+func Percentile(sorted []float64, p float64) float64 {
+	checkPercentile(p)
+	return percentile(sorted, p)
+}
+
+// MultiPercentile 一次计算多个分位数, 结果与ps一一对应
+func MultiPercentile(sorted []float64, ps []float64) []float64 {
+	for _, p := range ps {
+		checkPercentile(p)
+	}
+
+	result := make([]float64, len(ps))
+	for i, p := range ps {
+		result[i] = percentile(sorted, p)
+	}
+	return result
+}
+
+func checkPercentile(p float64) {
+	if !(p >= 0 && p <= 1) {
+		panic("p must be in [0, 1]")
+	}
+}
+
+func percentile(sorted []float64, p float64) float64 {
+	n := len(sorted)
+	if n == 0 {
+		return math.NaN()
+	}
+
+	rank := p * float64(n-1)
+	lower := int(rank)
+	if lower >= n-1 {
+		return sorted[n-1]
+	}
+
+	frac := rank - float64(lower)
+	return sorted[lower] + frac*(sorted[lower+1]-sorted[lower])
+}
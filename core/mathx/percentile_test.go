@@ -0,0 +1,69 @@
+package mathx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+)
+
+func TestPercentile(t *testing.T) {
+	// 1 ~ 100
+	hundred := make([]float64, 100)
+	for i := range hundred {
+		hundred[i] = float64(i + 1)
+	}
+
+	tests := []struct {
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{hundred, 0, 1},
+		{hundred, 0.5, 50.5},
+		{hundred, 0.95, 95.05},
+		{hundred, 0.99, 99.01},
+		{hundred, 1, 100},
+		{[]float64{1, 2, 3, 4, 5}, 0.5, 3},
+		{[]float64{1, 2, 3, 4, 5}, 0.25, 2},
+		{[]float64{1, 2, 3, 4, 5}, 0.1, 1.4},
+		{[]float64{7}, 0, 7},
+		{[]float64{7}, 0.5, 7},
+		{[]float64{7}, 1, 7},
+		{[]float64{10, 20}, 0, 10},
+		{[]float64{10, 20}, 0.5, 15},
+		{[]float64{10, 20}, 0.95, 19.5},
+		{[]float64{10, 20}, 1, 20},
+	}
+	for _, test := range tests {
+		assert.InDelta(t, test.want, Percentile(test.sorted, test.p), 1e-9, "p=%v, n=%d", test.p, len(test.sorted))
+	}
+}
+
+func TestPercentileEmpty(t *testing.T) {
+	assert.True(t, math.IsNaN(Percentile(nil, 0.5)))
+	for _, v := range MultiPercentile(nil, []float64{0, 1}) {
+		assert.True(t, math.IsNaN(v))
+	}
+}
+
+func TestPercentileInvalid(t *testing.T) {
+	for _, p := range []float64{-0.01, 1.01, math.NaN()} {
+		assert.Panics(t, func() {
+			Percentile([]float64{1}, p)
+		}, p)
+		assert.Panics(t, func() {
+			MultiPercentile([]float64{1}, []float64{0.5, p})
+		}, p)
+	}
+}
+
+func TestMultiPercentile(t *testing.T) {
+	sorted := []float64{1, 3, 5, 7, 9}
+	ps := []float64{0, 0.5, 0.95, 0.99, 1}
+	result := MultiPercentile(sorted, ps)
+	assert.Len(t, result, len(ps))
+	for i, p := range ps {
+		assert.Equal(t, Percentile(sorted, p), result[i])
+	}
+	assert.Empty(t, MultiPercentile(sorted, nil))
+}
@@ -11,12 +11,25 @@ type Proba struct {
 	lock sync.Mutex
 }
 
+// NewProba 创建以当前时间为种子的概率生成器
 func NewProba() *Proba {
+	return NewProbaWithSeed(time.Now().UnixNano())
+}
+
+// NewProbaWithSeed 创建以seed为种子的概率生成器, 相同的种子产生相同的结果序列, 便于编写确定性的测试
+func NewProbaWithSeed(seed int64) *Proba {
 	return &Proba{
-		r: rand.New(rand.NewSource(time.Now().UnixNano())),
+		r: rand.New(rand.NewSource(seed)),
 	}
 }
 
+// SetSeed 重新设置种子, 可以与 TrueOnProba 并发调用
+func (p *Proba) SetSeed(seed int64) {
+	p.lock.Lock()
+	p.r.Seed(seed)
+	p.lock.Unlock()
+}
+
 func (p *Proba) TrueOnProba(proba float64) (truth bool) {
 	p.lock.Lock()
 	truth = p.r.Float64() < proba
@@ -0,0 +1,52 @@
+package mathx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+)
+
+func sampleProba(p *Proba, n int) []bool {
+	result := make([]bool, n)
+	for i := range result {
+		result[i] = p.TrueOnProba(0.5)
+	}
+	return result
+}
+
+func TestProbaWithSeed(t *testing.T) {
+	// 相同的种子产生相同的结果
+	expect := sampleProba(NewProbaWithSeed(1), 100)
+	assert.Equal(t, expect, sampleProba(NewProbaWithSeed(1), 100))
+	assert.NotEqual(t, expect, sampleProba(NewProbaWithSeed(2), 100))
+
+	// 重新设置种子之后从头开始
+	p := NewProbaWithSeed(2)
+	sampleProba(p, 10)
+	p.SetSeed(1)
+	assert.Equal(t, expect, sampleProba(p, 100))
+}
+
+func TestProbaBoundary(t *testing.T) {
+	p := NewProba()
+	for i := 0; i < 100; i++ {
+		assert.False(t, p.TrueOnProba(0))
+		assert.True(t, p.TrueOnProba(1))
+	}
+}
+
+func TestProbaSetSeedConcurrent(t *testing.T) {
+	p := NewProba()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				p.SetSeed(int64(i))
+				p.TrueOnProba(0.5)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,45 @@
+package mathx
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Unstable 在给定值的基础上做随机抖动, 用于避免大量任务在同一时刻触发
+type Unstable struct {
+	deviation float64
+	r         *rand.Rand
+	lock      *sync.Mutex
+}
+
+// NewUnstable 创建抖动生成器, deviation 为抖动比例, 取值范围 [0, 1]
+func NewUnstable(deviation float64) Unstable {
+	if deviation < 0 {
+		deviation = 0
+	}
+	if deviation > 1 {
+		deviation = 1
+	}
+	return Unstable{
+		deviation: deviation,
+		r:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		lock:      new(sync.Mutex),
+	}
+}
+
+// AroundDuration 返回 [base*(1-deviation), base*(1+deviation)] 范围内的随机时长
+func (u Unstable) AroundDuration(base time.Duration) time.Duration {
+	u.lock.Lock()
+	val := time.Duration((1 + u.deviation - 2*u.deviation*u.r.Float64()) * float64(base))
+	u.lock.Unlock()
+	return val
+}
+
+// AroundInt 返回 [base*(1-deviation), base*(1+deviation)] 范围内的随机整数
+func (u Unstable) AroundInt(base int64) int64 {
+	u.lock.Lock()
+	val := int64((1 + u.deviation - 2*u.deviation*u.r.Float64()) * float64(base))
+	u.lock.Unlock()
+	return val
+}
@@ -0,0 +1,67 @@
+package mathx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestUnstableAroundDuration(t *testing.T) {
+	const samples = 10000
+	u := NewUnstable(0.1)
+	var sum time.Duration
+	for i := 0; i < samples; i++ {
+		d := u.AroundDuration(time.Second)
+		assert.True(t, d >= time.Millisecond*900, d)
+		assert.True(t, d <= time.Millisecond*1100, d)
+		sum += d
+	}
+	// 均匀分布的均值为base, 样本均值的标准差约为0.6ms
+	assert.InDelta(t, float64(time.Second), float64(sum/samples), float64(time.Millisecond*10))
+}
+
+func TestUnstableAroundInt(t *testing.T) {
+	const samples = 10000
+	u := NewUnstable(0.2)
+	var sum int64
+	for i := 0; i < samples; i++ {
+		v := u.AroundInt(1000)
+		assert.True(t, v >= 800, v)
+		assert.True(t, v <= 1200, v)
+		sum += v
+	}
+	assert.InDelta(t, 1000, float64(sum)/samples, 10)
+}
+
+func TestUnstableDeviationClamp(t *testing.T) {
+	// 抖动比例为0时总是返回base
+	u := NewUnstable(-1)
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, time.Second, u.AroundDuration(time.Second))
+		assert.Equal(t, int64(100), u.AroundInt(100))
+	}
+
+	// 抖动比例最大为1
+	u = NewUnstable(2)
+	for i := 0; i < 100; i++ {
+		d := u.AroundDuration(time.Second)
+		assert.True(t, d >= 0 && d <= time.Second*2, d)
+	}
+}
+
+func TestUnstableConcurrent(t *testing.T) {
+	u := NewUnstable(0.5)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 1000; j++ {
+				u.AroundDuration(time.Second)
+				u.AroundInt(100)
+			}
+		}()
+	}
+	wg.Wait()
+}
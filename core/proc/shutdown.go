@@ -0,0 +1,149 @@
+package proc
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// 进程退出分为两个阶段:
+// 1. 收到第一个终止信号时执行 wrap-up 回调, 通知停止接收新的请求, 但继续处理进行中的请求
+// 2. 等待 gracePeriod 之后(期间再次收到信号则立即进入该阶段)执行 shutdown 回调, 做最终的清理
+// 所有 wrap-up 回调执行完成(或超时)之后才会开始等待 gracePeriod, shutdown 回调一定在 wrap-up 回调之后执行
+// 同一阶段内的回调按注册的倒序依次执行
+
+const (
+	// 默认等待一个阶段的回调执行完成的最长时间, 避免某个回调卡住导致进程无法退出
+	defaultShutdownTimeout = time.Millisecond * 5500
+	// 默认 wrap-up 与 shutdown 之间的间隔, 用于处理完进行中的请求
+	defaultGracePeriod = time.Second
+)
+
+var (
+	wrapUpListeners   = new(listenerManager)
+	shutdownListeners = new(listenerManager)
+	shutdownTimeout   = defaultShutdownTimeout
+	gracePeriod       = defaultGracePeriod
+	timeoutLock       sync.RWMutex
+)
+
+type (
+	listenerManager struct {
+		lock      sync.Mutex
+		listeners []*listener
+	}
+
+	listener struct {
+		fn func()
+	}
+)
+
+// AddWrapUpListener 注册收到第一个终止信号时执行的回调, 返回的函数用于取消注册
+// 用于停止接收新的请求, 例如熔断器注册表切换为排空模式
+func AddWrapUpListener(fn func()) (remove func()) {
+	return wrapUpListeners.add(fn)
+}
+
+// AddShutdownListener 注册进程关闭时执行的回调, 返回的函数用于取消注册
+func AddShutdownListener(fn func()) (remove func()) {
+	return shutdownListeners.add(fn)
+}
+
+// SetGracePeriod 设置 wrap-up 与 shutdown 两个阶段之间的间隔
+func SetGracePeriod(d time.Duration) {
+	timeoutLock.Lock()
+	gracePeriod = d
+	timeoutLock.Unlock()
+}
+
+// SetShutdownTimeout 设置等待一个阶段的回调执行完成的最长时间
+func SetShutdownTimeout(d time.Duration) {
+	timeoutLock.Lock()
+	shutdownTimeout = d
+	timeoutLock.Unlock()
+}
+
+// Shutdown 按注册的倒序(后注册先执行)依次执行关闭回调, 收到SIGTERM/SIGINT时自动调用
+// 超过超时时间仍未执行完成时直接返回, 已执行过的回调会被移除, 不会重复执行
+func Shutdown() {
+	shutdownListeners.notify(getShutdownTimeout())
+}
+
+// WrapUp 按注册的倒序依次执行 wrap-up 回调, 收到第一个终止信号时自动调用
+func WrapUp() {
+	wrapUpListeners.notify(getShutdownTimeout())
+}
+
+func getShutdownTimeout() time.Duration {
+	timeoutLock.RLock()
+	defer timeoutLock.RUnlock()
+	return shutdownTimeout
+}
+
+func getGracePeriod() time.Duration {
+	timeoutLock.RLock()
+	defer timeoutLock.RUnlock()
+	return gracePeriod
+}
+
+// 两阶段的信号处理, 所有回调执行完成之后调用exit退出进程
+func handleSignals(signals <-chan os.Signal, exit func(sig os.Signal)) {
+	sig := <-signals
+	WrapUp()
+
+	timer := time.NewTimer(getGracePeriod())
+	select {
+	case <-timer.C:
+	case sig = <-signals:
+		// 再次收到信号, 不再等待
+		timer.Stop()
+	}
+
+	Shutdown()
+	exit(sig)
+}
+
+func (lm *listenerManager) add(fn func()) (remove func()) {
+	l := &listener{fn: fn}
+	lm.lock.Lock()
+	lm.listeners = append(lm.listeners, l)
+	lm.lock.Unlock()
+
+	return func() {
+		lm.lock.Lock()
+		defer lm.lock.Unlock()
+
+		for i, ll := range lm.listeners {
+			if ll == l {
+				lm.listeners = append(lm.listeners[:i], lm.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+func (lm *listenerManager) notify(timeout time.Duration) {
+	lm.lock.Lock()
+	listeners := lm.listeners
+	lm.listeners = nil
+	lm.lock.Unlock()
+
+	if len(listeners) == 0 {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := len(listeners) - 1; i >= 0; i-- {
+			listeners[i].fn()
+		}
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,177 @@
+package proc
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShutdownLIFO(t *testing.T) {
+	var lock sync.Mutex
+	var order []int
+	for i := 0; i < 5; i++ {
+		i := i
+		AddShutdownListener(func() {
+			lock.Lock()
+			order = append(order, i)
+			lock.Unlock()
+		})
+	}
+
+	Shutdown()
+	assert.Equal(t, []int{4, 3, 2, 1, 0}, order)
+
+	// 已执行过的回调不会重复执行
+	Shutdown()
+	assert.Len(t, order, 5)
+}
+
+func TestShutdownRemove(t *testing.T) {
+	var called []string
+	AddShutdownListener(func() {
+		called = append(called, "a")
+	})
+	remove := AddShutdownListener(func() {
+		called = append(called, "b")
+	})
+	AddShutdownListener(func() {
+		called = append(called, "c")
+	})
+
+	remove()
+	// 重复取消不影响其它回调
+	remove()
+	Shutdown()
+	assert.Equal(t, []string{"c", "a"}, called)
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	SetShutdownTimeout(time.Millisecond * 50)
+	defer SetShutdownTimeout(defaultShutdownTimeout)
+
+	block := make(chan struct{})
+	defer close(block)
+	AddShutdownListener(func() {
+		<-block
+	})
+
+	start := time.Now()
+	Shutdown()
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestAddShutdownListenerConcurrent(t *testing.T) {
+	var lock sync.Mutex
+	var count int
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			AddShutdownListener(func() {
+				lock.Lock()
+				count++
+				lock.Unlock()
+			})
+		}()
+	}
+	wg.Wait()
+
+	Shutdown()
+	assert.Equal(t, 100, count)
+}
+
+func TestWrapUpLIFO(t *testing.T) {
+	var order []int
+	for i := 0; i < 3; i++ {
+		i := i
+		AddWrapUpListener(func() {
+			order = append(order, i)
+		})
+	}
+	WrapUp()
+	assert.Equal(t, []int{2, 1, 0}, order)
+}
+
+type phaseRecorder struct {
+	lock   sync.Mutex
+	phases []string
+}
+
+func (r *phaseRecorder) add(phase string) {
+	r.lock.Lock()
+	r.phases = append(r.phases, phase)
+	r.lock.Unlock()
+}
+
+func (r *phaseRecorder) get() []string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]string(nil), r.phases...)
+}
+
+func TestHandleSignalsTwoPhases(t *testing.T) {
+	SetGracePeriod(time.Millisecond * 100)
+	defer SetGracePeriod(defaultGracePeriod)
+
+	var rec phaseRecorder
+	AddWrapUpListener(func() {
+		rec.add("wrap-up")
+	})
+	AddShutdownListener(func() {
+		rec.add("shutdown")
+	})
+
+	signals := make(chan os.Signal, 1)
+	exited := make(chan os.Signal, 1)
+	go handleSignals(signals, func(sig os.Signal) {
+		rec.add("exit")
+		exited <- sig
+	})
+
+	signals <- syscall.SIGTERM
+	time.Sleep(time.Millisecond * 50)
+	// 宽限期内只执行了 wrap-up
+	assert.Equal(t, []string{"wrap-up"}, rec.get())
+
+	select {
+	case sig := <-exited:
+		assert.Equal(t, syscall.SIGTERM, sig)
+	case <-time.After(time.Second):
+		t.Fatal("process should exit after grace period")
+	}
+	assert.Equal(t, []string{"wrap-up", "shutdown", "exit"}, rec.get())
+}
+
+func TestHandleSignalsSecondSignal(t *testing.T) {
+	SetGracePeriod(time.Hour)
+	defer SetGracePeriod(defaultGracePeriod)
+
+	var rec phaseRecorder
+	AddWrapUpListener(func() {
+		rec.add("wrap-up")
+	})
+	AddShutdownListener(func() {
+		rec.add("shutdown")
+	})
+
+	signals := make(chan os.Signal, 1)
+	exited := make(chan os.Signal, 1)
+	go handleSignals(signals, func(sig os.Signal) {
+		exited <- sig
+	})
+
+	signals <- syscall.SIGTERM
+	// 第二个信号跳过宽限期, 立即执行 shutdown
+	signals <- syscall.SIGINT
+	select {
+	case sig := <-exited:
+		assert.Equal(t, syscall.SIGINT, sig)
+	case <-time.After(time.Second):
+		t.Fatal("second signal should skip grace period")
+	}
+	assert.Equal(t, []string{"wrap-up", "shutdown"}, rec.get())
+}
@@ -0,0 +1,21 @@
+//go:build linux || darwin
+
+package proc
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func init() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+
+	go handleSignals(signals, func(sig os.Signal) {
+		// 恢复默认的信号处理并重新发送信号, 让进程按原本的方式退出
+		signal.Stop(signals)
+		signal.Reset(sig)
+		_ = syscall.Kill(os.Getpid(), sig.(syscall.Signal))
+	})
+}
@@ -0,0 +1,64 @@
+package stat
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	// LevelInfo 普通信息
+	LevelInfo Level = iota
+	// LevelWarn 需要关注的异常
+	LevelWarn
+	// LevelError 需要告警的错误, Report 默认使用该级别
+	LevelError
+)
+
+var (
+	reporterLock sync.RWMutex
+	// 默认不处理, 由应用通过 SetReporter 转发到自己的日志或告警系统
+	reporter func(level Level, msg string)
+)
+
+// Level 上报级别
+type Level int
+
+// Report reports given message.
+func Report(msg string) {
+	report(LevelError, msg)
+}
+
+// ReportLevel reports message with given level, message is formatted like fmt.Sprintf.
+func ReportLevel(level Level, format string, args ...any) {
+	report(level, fmt.Sprintf(format, args...))
+}
+
+// SetReporter sets the given reporter.
+func SetReporter(fn func(level Level, msg string)) {
+	reporterLock.Lock()
+	reporter = fn
+	reporterLock.Unlock()
+}
+
+func (l Level) String() string {
+	switch l {
+	case LevelInfo:
+		return "info"
+	case LevelWarn:
+		return "warn"
+	case LevelError:
+		return "error"
+	default:
+		return fmt.Sprintf("level(%d)", int(l))
+	}
+}
+
+func report(level Level, msg string) {
+	reporterLock.RLock()
+	fn := reporter
+	reporterLock.RUnlock()
+
+	if fn != nil {
+		fn(level, msg)
+	}
+}
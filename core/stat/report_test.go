@@ -0,0 +1,53 @@
+package stat
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+type reported struct {
+	level Level
+	msg   string
+}
+
+func captureReports(t *testing.T) *[]reported {
+	var reports []reported
+	SetReporter(func(level Level, msg string) {
+		reports = append(reports, reported{level: level, msg: msg})
+	})
+	t.Cleanup(func() {
+		SetReporter(nil)
+	})
+	return &reports
+}
+
+func TestReport(t *testing.T) {
+	reports := captureReports(t)
+	Report("breaker is open, 100%")
+	assert.Equal(t, []reported{{level: LevelError, msg: "breaker is open, 100%"}}, *reports)
+}
+
+func TestReportLevel(t *testing.T) {
+	reports := captureReports(t)
+	ReportLevel(LevelWarn, "callee: %s, drops: %d", "user-rpc", 3)
+	ReportLevel(LevelInfo, "plain")
+	assert.Equal(t, []reported{
+		{level: LevelWarn, msg: "callee: user-rpc, drops: 3"},
+		{level: LevelInfo, msg: "plain"},
+	}, *reports)
+}
+
+func TestReportWithoutReporter(t *testing.T) {
+	SetReporter(nil)
+	assert.NotPanics(t, func() {
+		Report("nobody listens")
+		ReportLevel(LevelInfo, "nobody listens")
+	})
+}
+
+func TestLevelString(t *testing.T) {
+	assert.Equal(t, "info", LevelInfo.String())
+	assert.Equal(t, "warn", LevelWarn.String())
+	assert.Equal(t, "error", LevelError.String())
+	assert.Equal(t, "level(9)", Level(9).String())
+}
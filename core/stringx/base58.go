@@ -0,0 +1,92 @@
+package stringx
+
+import "errors"
+
+// Bitcoin 使用的 Base58 字符集, 去掉了容易混淆的 0 O I l
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	ErrInvalidBase58 = errors.New("invalid base58 string")
+
+	base58Index = buildAlphabetIndex(base58Alphabet)
+)
+
+// Base58Encode 使用Bitcoin字符集编码, 开头的每个0字节编码为一个'1'
+func Base58Encode(b []byte) string {
+	zeros := 0
+	for zeros < len(b) && b[zeros] == 0 {
+		zeros++
+	}
+
+	// log(256) / log(58) ≈ 1.37, 预留足够的空间
+	digits := make([]byte, 0, (len(b)-zeros)*138/100+1)
+	for _, c := range b[zeros:] {
+		// digits 是低位在前的58进制数, 乘以256再加上c
+		carry := int(c)
+		for i := range digits {
+			carry += int(digits[i]) << 8
+			digits[i] = byte(carry % 58)
+			carry /= 58
+		}
+		for carry > 0 {
+			digits = append(digits, byte(carry%58))
+			carry /= 58
+		}
+	}
+
+	result := make([]byte, zeros+len(digits))
+	for i := 0; i < zeros; i++ {
+		result[i] = base58Alphabet[0]
+	}
+	for i, d := range digits {
+		result[len(result)-1-i] = base58Alphabet[d]
+	}
+	return string(result)
+}
+
+// Base58Decode 解码 Base58Encode 编码的字符串, 包含字符集以外的字符时返回 ErrInvalidBase58
+func Base58Decode(s string) ([]byte, error) {
+	zeros := 0
+	for zeros < len(s) && s[zeros] == base58Alphabet[0] {
+		zeros++
+	}
+
+	// log(58) / log(256) ≈ 0.733
+	bytes := make([]byte, 0, (len(s)-zeros)*733/1000+1)
+	for i := zeros; i < len(s); i++ {
+		idx := base58Index[s[i]]
+		if idx < 0 {
+			return nil, ErrInvalidBase58
+		}
+
+		// bytes 是低位在前的256进制数, 乘以58再加上idx
+		carry := int(idx)
+		for j := range bytes {
+			carry += int(bytes[j]) * 58
+			bytes[j] = byte(carry)
+			carry >>= 8
+		}
+		for carry > 0 {
+			bytes = append(bytes, byte(carry))
+			carry >>= 8
+		}
+	}
+
+	result := make([]byte, zeros+len(bytes))
+	for i, c := range bytes {
+		result[len(result)-1-i] = c
+	}
+	return result, nil
+}
+
+// 字符到下标的映射, 字符集以外的字符为-1
+func buildAlphabetIndex(alphabet string) [256]int8 {
+	var index [256]int8
+	for i := range index {
+		index[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		index[alphabet[i]] = int8(i)
+	}
+	return index
+}
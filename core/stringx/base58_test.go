@@ -0,0 +1,64 @@
+package stringx
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestBase58(t *testing.T) {
+	// Bitcoin 的测试数据
+	tests := []struct {
+		hex     string
+		encoded string
+	}{
+		{"", ""},
+		{"61", "2g"},
+		{"626262", "a3gV"},
+		{"636363", "aPEr"},
+		{"73696d706c792061206c6f6e6720737472696e67", "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+		{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+		{"516b6fcd0f", "ABnLTmg"},
+		{"bf4f89001e670274dd", "3SEo3LWLoPntC"},
+		{"572e4794", "3EFU7m"},
+		{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+		{"10c8511e", "Rt5zm"},
+		{"00000000000000000000", "1111111111"},
+		{"00", "1"},
+		{"0000ff", "115Q"},
+	}
+
+	for _, test := range tests {
+		raw, err := hex.DecodeString(test.hex)
+		assert.Nil(t, err)
+		assert.Equal(t, test.encoded, Base58Encode(raw), test.hex)
+
+		decoded, err := Base58Decode(test.encoded)
+		assert.Nil(t, err)
+		assert.Equal(t, raw, decoded, test.encoded)
+	}
+}
+
+func TestBase58RoundTrip(t *testing.T) {
+	for n := 0; n < 64; n++ {
+		b := make([]byte, n)
+		_, err := rand.Read(b)
+		assert.Nil(t, err)
+		// 开头的0字节
+		for i := 0; i < n/4; i++ {
+			b[i] = 0
+		}
+
+		decoded, err := Base58Decode(Base58Encode(b))
+		assert.Nil(t, err)
+		assert.Equal(t, b, decoded)
+	}
+}
+
+func TestBase58DecodeInvalid(t *testing.T) {
+	for _, s := range []string{"0", "O", "I", "l", "abc+", "1 1", "中文"} {
+		_, err := Base58Decode(s)
+		assert.Equal(t, ErrInvalidBase58, err, s)
+	}
+}
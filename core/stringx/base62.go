@@ -0,0 +1,55 @@
+package stringx
+
+import (
+	"errors"
+	"math"
+)
+
+// Base62 字符集按ASCII顺序排列, 长度相同的编码按字符串排序与数值排序一致
+const base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+var (
+	ErrInvalidBase62  = errors.New("invalid base62 string")
+	ErrBase62Overflow = errors.New("base62 value overflows uint64")
+
+	base62Index = buildAlphabetIndex(base62Alphabet)
+)
+
+// Base62Encode 将n编码为Base62字符串, 常用于短链接, 0编码为"0"
+func Base62Encode(n uint64) string {
+	// math.MaxUint64 编码后为11位
+	var buf [11]byte
+	i := len(buf)
+	for {
+		i--
+		buf[i] = base62Alphabet[n%62]
+		n /= 62
+		if n == 0 {
+			break
+		}
+	}
+	return string(buf[i:])
+}
+
+// Base62DecodeChecked 解码 Base62Encode 编码的字符串, 用于解码不可信的输入
+// 为空或者包含字符集以外的字符时返回 ErrInvalidBase62, 超过 math.MaxUint64 时返回 ErrBase62Overflow, 而不是溢出回绕
+func Base62DecodeChecked(s string) (uint64, error) {
+	if len(s) == 0 {
+		return 0, ErrInvalidBase62
+	}
+
+	var n uint64
+	for i := 0; i < len(s); i++ {
+		idx := base62Index[s[i]]
+		if idx < 0 {
+			return 0, ErrInvalidBase62
+		}
+
+		d := uint64(idx)
+		if n > (math.MaxUint64-d)/62 {
+			return 0, ErrBase62Overflow
+		}
+		n = n*62 + d
+	}
+	return n, nil
+}
@@ -0,0 +1,79 @@
+package stringx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestBase62(t *testing.T) {
+	tests := []struct {
+		n    uint64
+		want string
+	}{
+		{0, "0"},
+		{9, "9"},
+		{10, "A"},
+		{61, "z"},
+		{62, "10"},
+		{3843, "zz"},
+		{math.MaxUint64, "LygHa16AHYF"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, Base62Encode(test.n))
+		n, err := Base62DecodeChecked(test.want)
+		assert.Nil(t, err)
+		assert.Equal(t, test.n, n)
+	}
+}
+
+func TestBase62RoundTrip(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		n := r.Uint64()
+		decoded, err := Base62DecodeChecked(Base62Encode(n))
+		assert.Nil(t, err)
+		assert.Equal(t, n, decoded)
+	}
+}
+
+func TestBase62DecodeChecked(t *testing.T) {
+	// 开头的0不影响结果
+	n, err := Base62DecodeChecked("00010")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(62), n)
+	// 最长的不溢出的输入
+	n, err = Base62DecodeChecked("000LygHa16AHYF")
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), n)
+
+	for _, s := range []string{"LygHa16AHYG", "LygHa16AHZ0", "zzzzzzzzzzz", "100000000000", strings.Repeat("z", 100)} {
+		_, err = Base62DecodeChecked(s)
+		assert.Equal(t, ErrBase62Overflow, err, s)
+	}
+	for _, s := range []string{"", "abc-", "a b", "é", "+/="} {
+		_, err = Base62DecodeChecked(s)
+		assert.Equal(t, ErrInvalidBase62, err, s)
+	}
+}
+
+func FuzzBase62DecodeChecked(f *testing.F) {
+	for _, s := range []string{"0", "LygHa16AHYF", "LygHa16AHYG", "zz-"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		n, err := Base62DecodeChecked(s)
+		if err != nil {
+			return
+		}
+		// 去掉开头的0之后与重新编码的结果一致
+		trimmed := strings.TrimLeft(s, "0")
+		if trimmed == "" {
+			trimmed = "0"
+		}
+		assert.Equal(t, trimmed, Base62Encode(n))
+	})
+}
@@ -0,0 +1,44 @@
+package stringx
+
+import (
+	"errors"
+	"strings"
+)
+
+const defaultIdSeparator = "_"
+
+var ErrInvalidPrefixedId = errors.New("invalid prefixed id")
+
+// RandIdWithPrefix 生成带命名空间前缀的ID, 例如 usr_a1b2c3d4e5f60708
+func RandIdWithPrefix(prefix string) string {
+	return RandIdWithSeparator(prefix, defaultIdSeparator)
+}
+
+// RandIdWithSeparator 同 RandIdWithPrefix, 但可以自定义前缀与ID之间的分隔符
+func RandIdWithSeparator(prefix, sep string) string {
+	if len(prefix) == 0 {
+		panic("prefix must not be empty")
+	}
+	return prefix + sep + RandId()
+}
+
+// SplitPrefixedId 按第一个 _ 拆分出前缀和ID, 并校验ID是否为 RandId 生成的格式
+func SplitPrefixedId(id string) (prefix, suffix string, err error) {
+	prefix, suffix, ok := strings.Cut(id, defaultIdSeparator)
+	if !ok || len(prefix) == 0 || !isRandId(suffix) {
+		return "", "", ErrInvalidPrefixedId
+	}
+	return prefix, suffix, nil
+}
+
+func isRandId(s string) bool {
+	if len(s) != 2*idLen {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !isHex(s[i]) {
+			return false
+		}
+	}
+	return true
+}
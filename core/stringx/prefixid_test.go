@@ -0,0 +1,47 @@
+package stringx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func TestRandIdWithPrefix(t *testing.T) {
+	for _, prefix := range []string{"usr", "ord", "a"} {
+		id := RandIdWithPrefix(prefix)
+		assert.True(t, strings.HasPrefix(id, prefix+"_"))
+
+		p, suffix, err := SplitPrefixedId(id)
+		assert.Nil(t, err)
+		assert.Equal(t, prefix, p)
+		assert.Equal(t, id, p+"_"+suffix)
+	}
+
+	assert.Panics(t, func() {
+		RandIdWithPrefix("")
+	})
+}
+
+func TestRandIdWithSeparator(t *testing.T) {
+	id := RandIdWithSeparator("usr", ":")
+	assert.True(t, strings.HasPrefix(id, "usr:"))
+	assert.Len(t, id, len("usr:")+2*idLen)
+	assert.Panics(t, func() {
+		RandIdWithSeparator("", ":")
+	})
+}
+
+func TestSplitPrefixedIdInvalid(t *testing.T) {
+	for _, id := range []string{
+		"",
+		"usr",
+		"_a1b2c3d4e5f60708",
+		"usr_",
+		"usr_a1b2c3d4",
+		"usr_a1b2c3d4e5f6070g",
+		"usr_a1b2c3d4e5f60708_x",
+	} {
+		_, _, err := SplitPrefixedId(id)
+		assert.Equal(t, ErrInvalidPrefixedId, err, id)
+	}
+}
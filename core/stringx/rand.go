@@ -0,0 +1,28 @@
+package stringx
+
+// Random 拥有独立随机源的随机字符串生成器, 不与其它实例或全局函数共享状态, 并发安全
+// 由于包内已有全局函数 Rand, 类型命名为 Random
+type Random struct {
+	src *lockSource
+}
+
+func NewRand(seed int64) *Random {
+	return &Random{
+		src: newLockedSource(seed),
+	}
+}
+
+// Randn 生成长度为n的随机字符串
+func (r *Random) Randn(n int) string {
+	return r.src.randn(n)
+}
+
+// Rand 生成默认长度的随机字符串
+func (r *Random) Rand() string {
+	return r.src.randn(defaultRandLen)
+}
+
+// RandId 生成随机ID, crypto/rand 读取失败时退化为使用该实例的随机源
+func (r *Random) RandId() string {
+	return randIdN(idLen, r.src)
+}
@@ -0,0 +1,35 @@
+package stringx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestRandSameSeed(t *testing.T) {
+	r1 := NewRand(42)
+	r2 := NewRand(42)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, r1.Randn(16), r2.Randn(16))
+		assert.Equal(t, r1.Rand(), r2.Rand())
+	}
+}
+
+func TestRandIndependent(t *testing.T) {
+	r1 := NewRand(42)
+	s1 := r1.Randn(16)
+
+	r2 := NewRand(42)
+	// 全局随机源以及其它实例不影响当前实例的输出
+	Seed(1)
+	_ = Randn(16)
+	_ = NewRand(42).Randn(16)
+	assert.Equal(t, s1, r2.Randn(16))
+}
+
+func TestRandRandId(t *testing.T) {
+	r := NewRand(1)
+	id := r.RandId()
+	assert.Len(t, id, 2*idLen)
+	assert.NotEqual(t, id, r.RandId())
+	assert.Len(t, r.Rand(), defaultRandLen)
+}
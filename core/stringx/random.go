@@ -2,24 +2,35 @@ package stringx
 
 import (
 	crand "crypto/rand"
-	"fmt"
+	"encoding/binary"
+	"encoding/hex"
+	"go-zero-/core/mathx"
+	"io"
+	"math/bits"
 	"math/rand"
 	"sync"
 	"time"
 )
 
 const (
-	letterBytes   = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789" // 62个字符
-	letterIdxBits = 6                                                                // 6位 意味着可以表示0-63的整数, 对应letterBytes的字符索引
+	letterBytes = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789" // 62个字符
 
 	idLen          = 8 // 这个常量定义了生成的ID字符串的长度，设置为8个字节
 	defaultRandLen = 8 // 默认随机字符串长度
+)
 
-	letterIdxMask = 1<<letterIdxBits - 1 // 掩码 用于提取Int63()方法 生成的63位随机数中的最低6位。由于letterIdxBits是6，1<<letterIdxBits - 1计算得到的是0x3F（即二进制的0011 1111），它可以与随机数进行按位与操作（&），以获取一个0到63范围内的索引
-	letterIdxMax  = 63 / letterIdxBits   // 63位随机数可以表示多少个字符索引
+const (
+	numericBytes = "0123456789"
+	alphaBytes   = "abcdefghijklmnopqrstuvwxyz"
 )
 
-var src = newLockedSource(time.Now().UnixNano())
+var (
+	src = newLockedSource(time.Now().UnixNano())
+	// 根据letterBytes的长度计算掩码, 修改letterBytes不需要再手动调整掩码位数
+	letterMask = newAlphabetMask(len(letterBytes))
+	// RandBytes 使用的随机源, 便于测试时模拟读取失败
+	randReader io.Reader = crand.Reader
+)
 
 // 关于为什么要加锁 https://aptxx.com/posts/golang-rand-concurrency-safe/
 type lockSource struct {
@@ -45,36 +56,169 @@ func (ls *lockSource) Seed(seed int64) {
 	ls.source.Seed(seed)
 }
 
+// Randn 使用全局随机源生成长度为n的随机字符串
+//
+// Deprecated: 全局随机源会被 Seed 修改, 并行测试时互相影响, 新代码请使用 NewRand 创建独立的实例
 func Randn(n int) string {
+	return src.randn(n)
+}
+
+func (ls *lockSource) randn(n int) string {
+	return randString(n, letterBytes, letterMask, ls.Int63)
+}
+
+// RandWithAlphabet 从给定的字符集中随机生成长度为n的字符串
+func RandWithAlphabet(n int, alphabet string) string {
+	return randWithAlphabet(n, alphabet, src.Int63)
+}
+
+// SecureRandWithAlphabet 同 RandWithAlphabet, 但使用 crypto/rand 作为随机源, 用于安全敏感的场景
+func SecureRandWithAlphabet(n int, alphabet string) string {
+	return randWithAlphabet(n, alphabet, secureInt63)
+}
+
+// RandNumeric 生成长度为n的纯数字字符串, 使用 crypto/rand, 适用于验证码等场景
+func RandNumeric(n int) string {
+	return SecureRandWithAlphabet(n, numericBytes)
+}
+
+// RandDigits 使用lockSource生成长度为n的纯数字字符串, 允许以0开头, 适用于短信验证码等场景
+// 通过掩码丢弃超出范围的索引, 不存在取模带来的偏差
+func RandDigits(n int) string {
+	return RandWithAlphabet(n, numericBytes)
+}
+
+// RandAlpha 生成长度为n的小写字母字符串, 使用 crypto/rand
+func RandAlpha(n int) string {
+	return SecureRandWithAlphabet(n, alphaBytes)
+}
+
+// 按掩码从63位随机数中取字符索引
+type alphabetMask struct {
+	// 每个索引占用的位数
+	bits uint
+	// 用于提取最低bits位
+	mask int64
+	// 一个63位随机数可以提供多少个索引
+	max int
+}
+
+// 能表示 0 ~ n-1 的最少位数, 至少1位
+func newAlphabetMask(n int) alphabetMask {
+	idxBits := uint(mathx.MaxInt(bits.Len(uint(n-1)), 1))
+	return alphabetMask{
+		bits: idxBits,
+		mask: int64(1)<<idxBits - 1,
+		max:  63 / int(idxBits),
+	}
+}
+
+func randWithAlphabet(n int, alphabet string, int63 func() int64) string {
+	if n <= 0 {
+		panic("n must be greater than 0")
+	}
+	if len(alphabet) == 0 {
+		panic("alphabet must not be empty")
+	}
+
+	return randString(n, alphabet, newAlphabetMask(len(alphabet)), int63)
+}
+
+// 超出字符集长度的索引直接丢弃, 不存在取模带来的偏差, 保证均匀分布
+func randString(n int, alphabet string, m alphabetMask, int63 func() int64) string {
 	b := make([]byte, n)
-	for i, cache, remain := n-1, src.Int63(), letterIdxMask; i >= 0; {
+	for i, cache, remain := n-1, int63(), m.max; i >= 0; {
 		if remain == 0 {
-			cache, remain = src.Int63(), letterIdxMax
+			cache, remain = int63(), m.max
 		}
-		if idx := int(cache & letterIdxMask); idx < len(letterBytes) {
-			b[i] = letterBytes[idx]
+		if idx := int(cache & m.mask); idx < len(alphabet) {
+			b[i] = alphabet[idx]
 			i--
 		}
-		cache >>= letterIdxBits
+		cache >>= m.bits
 		remain--
 	}
 	return string(b)
 }
 
+func secureInt63() int64 {
+	var b [8]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return int64(binary.BigEndian.Uint64(b[:]) & (1<<63 - 1))
+}
+
+// RandHex 读取n个字节的 crypto/rand 随机数, 返回2n个字符的小写十六进制字符串
+// 适用于 session token、csrf token、nonce 等场景, 读取失败时直接panic, 不会退化为 math/rand
+func RandHex(n int) string {
+	if n <= 0 {
+		panic("n must be greater than 0")
+	}
+
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// RandHexN 同 RandHex, 但参数为随机数的位数, 不足一个字节的向上取整
+func RandHexN(bits int) string {
+	if bits <= 0 {
+		panic("bits must be greater than 0")
+	}
+	return RandHex((bits + 7) / 8)
+}
+
+// RandBytes 读取n个字节的 crypto/rand 随机数, 直接返回[]byte, 避免 string 到 []byte 的转换
+// 适用于 HMAC、AES 密钥等需要原始二进制数据的场景, 读取失败时返回错误, 不会退化为 math/rand
+func RandBytes(n int) ([]byte, error) {
+	if n <= 0 {
+		panic("n must be greater than 0")
+	}
+
+	b := make([]byte, n)
+	if _, err := io.ReadFull(randReader, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
 func RandId() string {
-	b := make([]byte, idLen)
+	return RandIdN(idLen)
+}
+
+// RandIdN 生成byteLen个字节的随机ID, 返回2*byteLen个字符的十六进制字符串
+// crypto/rand 读取失败时退化为 Randn
+func RandIdN(byteLen int) string {
+	if byteLen < 1 {
+		panic("byteLen must be greater than 0")
+	}
+
+	return randIdN(byteLen, src)
+}
+
+func randIdN(byteLen int, ls *lockSource) string {
+	b := make([]byte, byteLen)
 	_, err := crand.Read(b)
 	if err != nil {
-		return Randn(idLen)
+		return ls.randn(2 * byteLen)
 	}
 
-	return fmt.Sprintf("%x%x%x%x", b[0:2], b[2:4], b[4:6], b[6:8])
+	return hex.EncodeToString(b)
 }
 
+// Rand 使用全局随机源生成默认长度的随机字符串
+//
+// Deprecated: 新代码请使用 NewRand 创建独立的实例
 func Rand() string {
 	return Randn(defaultRandLen)
 }
 
+// Seed 重置全局随机源的种子
+//
+// Deprecated: 会影响所有使用全局随机源的调用方, 新代码请使用 NewRand 创建独立的实例
 func Seed(seed int64) {
 	src.Seed(seed)
 }
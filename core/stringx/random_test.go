@@ -0,0 +1,266 @@
+package stringx
+
+import (
+	"bytes"
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+var (
+	// 自由度为 len(alphabet)-1, 显著性水平0.001的卡方临界值, 用于固定种子的随机源, 结果是确定的
+	chiSquaredCritical = map[int]float64{
+		1:  10.83,
+		2:  13.82,
+		9:  27.88,
+		15: 37.70,
+		30: 59.70,
+		61: 100.89,
+		99: 148.23,
+	}
+	// 显著性水平1e-6的卡方临界值, 用于无法指定种子的 crypto/rand, 误报的概率可以忽略
+	chiSquaredCriticalSecure = map[int]float64{
+		2:  27.63,
+		9:  44.81,
+		30: 82.04,
+	}
+)
+
+func TestRandWithAlphabet(t *testing.T) {
+	for _, alphabet := range []string{"0123456789", "abc", "ABCDEFGHJKMNPQRSTVWXYZ123456789", "x"} {
+		s := RandWithAlphabet(100, alphabet)
+		assert.Len(t, s, 100)
+		for _, c := range s {
+			assert.True(t, strings.ContainsRune(alphabet, c))
+		}
+
+		s = SecureRandWithAlphabet(100, alphabet)
+		assert.Len(t, s, 100)
+		for _, c := range s {
+			assert.True(t, strings.ContainsRune(alphabet, c))
+		}
+	}
+}
+
+func TestRandWithAlphabetDistribution(t *testing.T) {
+	const samples = 100000
+	tests := []struct {
+		name     string
+		fn       func(int, string) string
+		critical map[int]float64
+	}{
+		{
+			name: "locked",
+			fn: func(n int, alphabet string) string {
+				return randWithAlphabet(n, alphabet, NewRand(1).src.Int63)
+			},
+			critical: chiSquaredCritical,
+		},
+		{
+			name:     "secure",
+			fn:       SecureRandWithAlphabet,
+			critical: chiSquaredCriticalSecure,
+		},
+	}
+
+	for _, test := range tests {
+		for _, alphabet := range []string{"0123456789", "abc", "ABCDEFGHJKMNPQRSTVWXYZ123456789"} {
+			s := test.fn(samples, alphabet)
+			counts := make(map[rune]int)
+			for _, c := range s {
+				counts[c]++
+			}
+			assert.Len(t, counts, len(alphabet))
+
+			expected := float64(samples) / float64(len(alphabet))
+			var chi2 float64
+			for _, c := range alphabet {
+				diff := float64(counts[c]) - expected
+				chi2 += diff * diff / expected
+			}
+			assert.Less(t, chi2, test.critical[len(alphabet)-1], test.name, alphabet)
+		}
+	}
+}
+
+func TestAlphabetMask(t *testing.T) {
+	for n, expect := range map[int]alphabetMask{
+		1:   {bits: 1, mask: 1, max: 63},
+		2:   {bits: 1, mask: 1, max: 63},
+		16:  {bits: 4, mask: 0xf, max: 15},
+		17:  {bits: 5, mask: 0x1f, max: 12},
+		62:  {bits: 6, mask: 0x3f, max: 10},
+		100: {bits: 7, mask: 0x7f, max: 9},
+	} {
+		assert.Equal(t, expect, newAlphabetMask(n), n)
+	}
+	assert.Equal(t, newAlphabetMask(len(letterBytes)), letterMask)
+}
+
+func TestRandAlphabetSizesCoverage(t *testing.T) {
+	const samples = 100000
+	alphabet100 := make([]byte, 100)
+	for i := range alphabet100 {
+		alphabet100[i] = byte(i)
+	}
+
+	for _, alphabet := range []string{"01", "0123456789abcdef", letterBytes, string(alphabet100)} {
+		counts := make(map[byte]int)
+		s := randWithAlphabet(samples, alphabet, NewRand(1).src.Int63)
+		for i := 0; i < len(s); i++ {
+			counts[s[i]]++
+		}
+		assert.Len(t, counts, len(alphabet))
+
+		expected := float64(samples) / float64(len(alphabet))
+		var chi2 float64
+		for i := 0; i < len(alphabet); i++ {
+			diff := float64(counts[alphabet[i]]) - expected
+			chi2 += diff * diff / expected
+		}
+		assert.Less(t, chi2, chiSquaredCritical[len(alphabet)-1], len(alphabet))
+	}
+}
+
+func TestRandnCoverage(t *testing.T) {
+	const samples = 100000
+	counts := make(map[byte]int)
+	s := NewRand(1).Randn(samples)
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+	assert.Len(t, counts, len(letterBytes))
+
+	expected := float64(samples) / float64(len(letterBytes))
+	var chi2 float64
+	for i := 0; i < len(letterBytes); i++ {
+		diff := float64(counts[letterBytes[i]]) - expected
+		chi2 += diff * diff / expected
+	}
+	assert.Less(t, chi2, chiSquaredCritical[len(letterBytes)-1])
+}
+
+func TestRandWithAlphabetInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		RandWithAlphabet(0, "abc")
+	})
+	assert.Panics(t, func() {
+		RandWithAlphabet(1, "")
+	})
+	assert.Panics(t, func() {
+		SecureRandWithAlphabet(-1, "abc")
+	})
+	assert.Panics(t, func() {
+		SecureRandWithAlphabet(1, "")
+	})
+}
+
+func TestRandHex(t *testing.T) {
+	for _, n := range []int{1, 8, 16, 32} {
+		s := RandHex(n)
+		assert.Len(t, s, 2*n)
+		assert.Equal(t, "", strings.Trim(s, "0123456789abcdef"))
+	}
+	assert.NotEqual(t, RandHex(16), RandHex(16))
+	assert.Panics(t, func() {
+		RandHex(0)
+	})
+}
+
+func TestRandHexN(t *testing.T) {
+	assert.Len(t, RandHexN(1), 2)
+	assert.Len(t, RandHexN(8), 2)
+	assert.Len(t, RandHexN(9), 4)
+	assert.Len(t, RandHexN(128), 32)
+	assert.Panics(t, func() {
+		RandHexN(0)
+	})
+}
+
+func TestRandNumericAndAlpha(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		s := RandNumeric(20)
+		assert.Len(t, s, 20)
+		assert.Equal(t, "", strings.Trim(s, numericBytes))
+
+		s = RandAlpha(20)
+		assert.Len(t, s, 20)
+		assert.Equal(t, "", strings.Trim(s, alphaBytes))
+	}
+	assert.Panics(t, func() {
+		RandNumeric(0)
+	})
+	assert.Panics(t, func() {
+		RandAlpha(0)
+	})
+}
+
+func TestRandDigits(t *testing.T) {
+	var leadingZero bool
+	for i := 0; i < 1000; i++ {
+		s := RandDigits(6)
+		assert.Len(t, s, 6)
+		for _, c := range s {
+			assert.True(t, '0' <= c && c <= '9', s)
+		}
+		if s[0] == '0' {
+			leadingZero = true
+		}
+	}
+	assert.True(t, leadingZero)
+	assert.Panics(t, func() {
+		RandDigits(0)
+	})
+}
+
+func TestRandIdN(t *testing.T) {
+	for _, n := range []int{1, 8, 16, 32} {
+		s := RandIdN(n)
+		assert.Len(t, s, 2*n)
+		assert.Equal(t, "", strings.Trim(s, "0123456789abcdef"))
+	}
+	assert.Len(t, RandId(), 2*idLen)
+	assert.Panics(t, func() {
+		RandIdN(0)
+	})
+}
+
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func TestRandBytes(t *testing.T) {
+	for _, n := range []int{1, 16, 32, 64} {
+		b, err := RandBytes(n)
+		assert.Nil(t, err)
+		assert.Len(t, b, n)
+	}
+
+	b1, err := RandBytes(32)
+	assert.Nil(t, err)
+	b2, err := RandBytes(32)
+	assert.Nil(t, err)
+	assert.False(t, bytes.Equal(b1, b2))
+
+	assert.Panics(t, func() {
+		_, _ = RandBytes(0)
+	})
+}
+
+func TestRandBytesError(t *testing.T) {
+	errEntropy := errors.New("entropy exhausted")
+	old := randReader
+	randReader = errReader{err: errEntropy}
+	defer func() {
+		randReader = old
+	}()
+
+	b, err := RandBytes(16)
+	assert.Nil(t, b)
+	assert.Equal(t, errEntropy, err)
+}
@@ -0,0 +1,50 @@
+package stringx
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ContainsAny 判断s中是否包含chars中的任意一个字符, 按rune比较, 支持多字节字符
+func ContainsAny(s, chars string) bool {
+	return strings.ContainsAny(s, chars)
+}
+
+// RemoveChars 删除s中所有出现在chars中的字符, 按rune处理, 支持多字节字符
+// 保留的部分原样拷贝, 不合法的UTF-8字节不会被替换为 U+FFFD
+func RemoveChars(s, chars string) string {
+	if len(s) == 0 || len(chars) == 0 {
+		return s
+	}
+
+	set := runeSet(chars)
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if _, ok := set[r]; !ok {
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+func runeSet(chars string) map[rune]struct{} {
+	set := make(map[rune]struct{}, len(chars))
+	for _, r := range chars {
+		set[r] = struct{}{}
+	}
+	return set
+}
+
+// TrimSpaceAndLower 去掉首尾的空白字符并转为小写
+func TrimSpaceAndLower(s string) string {
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// Normalize 规范化名字, 去掉首尾的空白字符, 转为小写, 并把中间连续的空白字符合并为一个空格
+// 空白字符按 unicode.IsSpace 判断, 包括制表符, 换行以及全角空格等unicode空白字符
+func Normalize(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
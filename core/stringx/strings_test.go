@@ -0,0 +1,90 @@
+package stringx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestContainsAny(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		chars string
+		want  bool
+	}{
+		{"empty s", "", "abc", false},
+		{"empty chars", "abc", "", false},
+		{"both empty", "", "", false},
+		{"ascii hit", "hello", "xyo", true},
+		{"ascii miss", "hello", "xyz", false},
+		{"accented hit", "café", "é", true},
+		{"accented miss", "cafe", "é", false},
+		{"emoji hit", "id-😀-01", "😀", true},
+		{"emoji miss", "id-😀-01", "😁", false},
+		// 同一个多字节字符的部分字节不应该被误判
+		{"partial utf8 bytes", "é", "\xa9", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, ContainsAny(test.s, test.chars))
+		})
+	}
+}
+
+func TestRemoveChars(t *testing.T) {
+	tests := []struct {
+		name  string
+		s     string
+		chars string
+		want  string
+	}{
+		{"empty s", "", "abc", ""},
+		{"empty chars", "abc", "", "abc"},
+		{"ascii", "a-b_c-d", "-_", "abcd"},
+		{"nothing removed", "hello", "xyz", "hello"},
+		{"all removed", "aaa", "a", ""},
+		{"accented", "crème brûlée", "èûé", "crme brle"},
+		{"keep accented", "naïve-id", "-", "naïveid"},
+		{"emoji", "😀a😁b😀", "😀", "a😁b"},
+		{"invalid utf8 kept", "a\xffb", "x", "a\xffb"},
+		{"invalid utf8 with removal", "a\xff-b\xc3", "-", "a\xffb\xc3"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, RemoveChars(test.s, test.chars))
+		})
+	}
+}
+
+func TestTrimSpaceAndLower(t *testing.T) {
+	assert.Equal(t, "", TrimSpaceAndLower(" \t\n"))
+	assert.Equal(t, "user-rpc", TrimSpaceAndLower(" User-RPC\t"))
+	assert.Equal(t, "svc  a", TrimSpaceAndLower("　Svc  A "))
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want string
+	}{
+		{"empty", "", ""},
+		{"only spaces", " \t\r\n ", ""},
+		{"lower", "SvcA", "svca"},
+		{"trim", "  svc a ", "svc a"},
+		{"multiple spaces", "svc    a   b", "svc a b"},
+		{"tabs and newlines", "\tSvc\t\tA\nB\r\n", "svc a b"},
+		// 全角空格, 不换行空格, em空格
+		{"unicode spaces", "　Svc  A　", "svc a"},
+		{"unicode letters", " ÉCOLE  Straße ", "école straße"},
+		{"already normalized", "svc a", "svc a"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, Normalize(test.s))
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package stringx
+
+import (
+	crand "crypto/rand"
+	"errors"
+	"sync"
+	"time"
+)
+
+const (
+	ulidLen     = 26
+	ulidTimeLen = 10
+	// Crockford base32 字符集, 去掉了容易混淆的 I L O U
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// 48位的毫秒时间戳, 第一个字符最大只能是7
+	maxULIDTimeFirstChar = '7'
+)
+
+var (
+	ErrInvalidULID = errors.New("invalid ulid")
+
+	crockfordIndex = buildCrockfordIndex()
+
+	// 同一毫秒内在上一次的随机数基础上加1, 保证生成的ULID严格递增
+	ulidLock     sync.Mutex
+	lastULIDTime uint64
+	lastULIDRand [10]byte
+)
+
+// ULID 生成26个字符的ULID, 前10个字符为毫秒时间戳, 后16个字符为80位的随机数
+// 同一毫秒内生成的ULID也是按字典序递增的
+func ULID() string {
+	ulidLock.Lock()
+	defer ulidLock.Unlock()
+
+	ms := uint64(time.Now().UnixMilli())
+	if ms > lastULIDTime {
+		if _, err := crand.Read(lastULIDRand[:]); err != nil {
+			panic(err)
+		}
+		lastULIDTime = ms
+	} else if !incrementBytes(lastULIDRand[:]) {
+		// 同一毫秒内随机数溢出(或者时钟回拨), 时间戳往前推一毫秒继续保证递增
+		lastULIDTime++
+		if _, err := crand.Read(lastULIDRand[:]); err != nil {
+			panic(err)
+		}
+	}
+
+	var id [16]byte
+	for i := 0; i < 6; i++ {
+		id[i] = byte(lastULIDTime >> (40 - 8*i))
+	}
+	copy(id[6:], lastULIDRand[:])
+	return encodeULID(id)
+}
+
+// ParseULIDTime 解析ULID中的时间戳
+func ParseULIDTime(s string) (time.Time, error) {
+	if len(s) != ulidLen || s[0] > maxULIDTimeFirstChar {
+		return time.Time{}, ErrInvalidULID
+	}
+
+	var ms int64
+	for i := 0; i < ulidLen; i++ {
+		v := crockfordIndex[s[i]]
+		if v < 0 {
+			return time.Time{}, ErrInvalidULID
+		}
+		if i < ulidTimeLen {
+			ms = ms<<5 | int64(v)
+		}
+	}
+
+	return time.UnixMilli(ms), nil
+}
+
+// 128位数据编码为26个字符, 高位补2个0凑够130位
+func encodeULID(id [16]byte) string {
+	var buf [ulidLen]byte
+	for i := 0; i < ulidLen; i++ {
+		var v byte
+		for j := 0; j < 5; j++ {
+			v <<= 1
+			if pos := i*5 + j - 2; pos >= 0 && id[pos/8]>>(7-pos%8)&1 == 1 {
+				v |= 1
+			}
+		}
+		buf[i] = crockfordAlphabet[v]
+	}
+	return string(buf[:])
+}
+
+// 大端字节序加1, 溢出时返回false
+func incrementBytes(b []byte) bool {
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i]++
+		if b[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func buildCrockfordIndex() [256]int8 {
+	var index [256]int8
+	for i := range index {
+		index[i] = -1
+	}
+	for i := 0; i < len(crockfordAlphabet); i++ {
+		c := crockfordAlphabet[i]
+		index[c] = int8(i)
+		// 解析时兼容小写
+		if 'A' <= c && c <= 'Z' {
+			index[c+'a'-'A'] = int8(i)
+		}
+	}
+	return index
+}
@@ -0,0 +1,50 @@
+package stringx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestULIDSortable(t *testing.T) {
+	const total = 1000
+	ids := make([]string, 0, total)
+	for i := 0; i < total; i++ {
+		id := ULID()
+		assert.Len(t, id, ulidLen)
+		ids = append(ids, id)
+	}
+
+	assert.True(t, sort.StringsAreSorted(ids))
+	for i := 1; i < total; i++ {
+		assert.NotEqual(t, ids[i-1], ids[i])
+	}
+}
+
+func TestParseULIDTime(t *testing.T) {
+	before := time.Now().UnixMilli()
+	id := ULID()
+	after := time.Now().UnixMilli()
+
+	ts, err := ParseULIDTime(id)
+	assert.Nil(t, err)
+	assert.GreaterOrEqual(t, ts.UnixMilli(), before)
+	assert.LessOrEqual(t, ts.UnixMilli(), after)
+
+	ts, err = ParseULIDTime("01ARZ3NDEKTSV4RRFFQ69G5FAV")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1469922850259), ts.UnixMilli())
+}
+
+func TestParseULIDTimeInvalid(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"01ARZ3NDEKTSV4RRFFQ69G5FA",
+		"81ARZ3NDEKTSV4RRFFQ69G5FAV",
+		"01ARZ3NDEKTSV4RRFFQ69G5FAU",
+	} {
+		_, err := ParseULIDTime(s)
+		assert.Equal(t, ErrInvalidULID, err, s)
+	}
+}
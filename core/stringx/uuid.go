@@ -0,0 +1,66 @@
+package stringx
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+)
+
+const uuidLen = 36
+
+// UUIDv4 生成符合 RFC 4122 的随机UUID, 格式为 xxxxxxxx-xxxx-4xxx-yxxx-xxxxxxxxxxxx
+// 只使用 crypto/rand, 读取失败时直接panic, 不会退化为 math/rand
+func UUIDv4() string {
+	var u [16]byte
+	if _, err := crand.Read(u[:]); err != nil {
+		panic(err)
+	}
+
+	u[6] = (u[6] & 0x0f) | 0x40 // 版本号 4
+	u[8] = (u[8] & 0x3f) | 0x80 // 变体 10xx
+
+	var buf [uuidLen]byte
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf[:])
+}
+
+// IsValidUUIDv4 校验格式以及版本号、变体位
+func IsValidUUIDv4(s string) bool {
+	if len(s) != uuidLen {
+		return false
+	}
+
+	for i := 0; i < uuidLen; i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHex(s[i]) {
+				return false
+			}
+		}
+	}
+
+	if s[14] != '4' {
+		return false
+	}
+	switch s[19] {
+	case '8', '9', 'a', 'b', 'A', 'B':
+		return true
+	default:
+		return false
+	}
+}
+
+func isHex(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
+}
@@ -0,0 +1,36 @@
+package stringx
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestUUIDv4(t *testing.T) {
+	const total = 10000
+	seen := make(map[string]struct{}, total)
+	for i := 0; i < total; i++ {
+		id := UUIDv4()
+		assert.True(t, IsValidUUIDv4(id), id)
+		seen[id] = struct{}{}
+	}
+	assert.Equal(t, total, len(seen))
+}
+
+func TestIsValidUUIDv4(t *testing.T) {
+	tests := []struct {
+		in   string
+		want bool
+	}{
+		{"f47ac10b-58cc-4372-a567-0e02b2c3d479", true},
+		{"F47AC10B-58CC-4372-A567-0E02B2C3D479", true},
+		{"", false},
+		{"f47ac10b58cc4372a5670e02b2c3d479", false},
+		{"f47ac10b-58cc-1372-a567-0e02b2c3d479", false},
+		{"f47ac10b-58cc-4372-c567-0e02b2c3d479", false},
+		{"f47ac10b-58cc-4372-a567-0e02b2c3d47g", false},
+		{"f47ac10b-58cc-4372-a567_0e02b2c3d479", false},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, IsValidUUIDv4(test.in), test.in)
+	}
+}
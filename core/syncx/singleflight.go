@@ -0,0 +1,69 @@
+package syncx
+
+import "sync"
+
+type (
+	// SingleFlight 合并并发的相同调用, 同一个key同时只有一个fn在执行, 其它调用等待并共享其结果
+	// 常用于熔断器恢复期间, 避免大量请求同时探测下游
+	SingleFlight interface {
+		Do(key string, fn func() (any, error)) (any, error)
+	}
+
+	call struct {
+		wg  sync.WaitGroup
+		val any
+		err error
+	}
+
+	flightGroup struct {
+		calls map[string]*call
+		lock  sync.Mutex
+	}
+)
+
+func NewSingleFlight() SingleFlight {
+	return &flightGroup{
+		calls: make(map[string]*call),
+	}
+}
+
+// Do 执行fn并返回结果, key相同的调用正在执行时, 等待该调用结束并返回相同的结果
+// fn结束之后的调用会重新执行fn, 结果不会被缓存
+func (g *flightGroup) Do(key string, fn func() (any, error)) (any, error) {
+	c, done := g.createCall(key)
+	if done {
+		return c.val, c.err
+	}
+
+	g.makeCall(c, key, fn)
+	return c.val, c.err
+}
+
+// 已经有相同key的调用时等待其结束, done为true
+func (g *flightGroup) createCall(key string) (c *call, done bool) {
+	g.lock.Lock()
+	if c, ok := g.calls[key]; ok {
+		g.lock.Unlock()
+		c.wg.Wait()
+		return c, true
+	}
+
+	c = new(call)
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.lock.Unlock()
+
+	return c, false
+}
+
+func (g *flightGroup) makeCall(c *call, key string, fn func() (any, error)) {
+	// fn panic时也要唤醒等待者并移除调用, 否则相同key的调用会永远阻塞
+	defer func() {
+		g.lock.Lock()
+		delete(g.calls, key)
+		g.lock.Unlock()
+		c.wg.Done()
+	}()
+
+	c.val, c.err = fn()
+}
@@ -0,0 +1,114 @@
+package syncx
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleFlightDo(t *testing.T) {
+	g := NewSingleFlight()
+	v, err := g.Do("key", func() (any, error) {
+		return "bar", nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "bar", v)
+}
+
+func TestSingleFlightDoErr(t *testing.T) {
+	g := NewSingleFlight()
+	errDown := errors.New("down")
+	v, err := g.Do("key", func() (any, error) {
+		return nil, errDown
+	})
+	assert.Equal(t, errDown, err)
+	assert.Nil(t, v)
+}
+
+func TestSingleFlightDoDupSuppress(t *testing.T) {
+	g := NewSingleFlight()
+	release := make(chan struct{})
+	var calls int32
+	fn := func() (any, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "bar", nil
+	}
+
+	const n = 10
+	var started, wg sync.WaitGroup
+	results := make([]any, n)
+	for i := 0; i < n; i++ {
+		started.Add(1)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			started.Done()
+			v, err := g.Do("key", fn)
+			assert.Nil(t, err)
+			results[i] = v
+		}(i)
+	}
+	started.Wait()
+	// 等待所有的调用都进入Do
+	time.Sleep(time.Millisecond * 50)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	for _, v := range results {
+		assert.Equal(t, "bar", v)
+	}
+}
+
+func TestSingleFlightDifferentKeys(t *testing.T) {
+	g := NewSingleFlight()
+	var calls int32
+	var wg sync.WaitGroup
+	for _, key := range []string{"a", "b", "c"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			v, err := g.Do(key, func() (any, error) {
+				atomic.AddInt32(&calls, 1)
+				return key, nil
+			})
+			assert.Nil(t, err)
+			assert.Equal(t, key, v)
+		}(key)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestSingleFlightNotCached(t *testing.T) {
+	g := NewSingleFlight()
+	var calls int
+	for i := 0; i < 3; i++ {
+		v, err := g.Do("key", func() (any, error) {
+			calls++
+			return calls, nil
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, i+1, v)
+	}
+}
+
+func TestSingleFlightPanic(t *testing.T) {
+	g := NewSingleFlight()
+	assert.Panics(t, func() {
+		_, _ = g.Do("key", func() (any, error) {
+			panic("boom")
+		})
+	})
+
+	// panic之后相同的key可以继续使用
+	v, err := g.Do("key", func() (any, error) {
+		return "bar", nil
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "bar", v)
+}
@@ -0,0 +1,55 @@
+package threading
+
+import (
+	"fmt"
+	"go-zero-/core/stat"
+	"runtime/debug"
+	"sync"
+)
+
+// TaskRunner 限制同时运行的任务数量, 常用于并发调用多个下游
+type TaskRunner struct {
+	limitChan chan struct{}
+	wg        sync.WaitGroup
+}
+
+// NewTaskRunner 创建最多同时运行concurrency个任务的TaskRunner, concurrency必须大于0
+func NewTaskRunner(concurrency int) *TaskRunner {
+	if concurrency < 1 {
+		panic("concurrency must be greater than 0")
+	}
+	return &TaskRunner{
+		limitChan: make(chan struct{}, concurrency),
+	}
+}
+
+// Schedule 在新的goroutine中运行task, 正在运行的任务数达到上限时阻塞, 直到有任务结束
+// task panic时recover并通过stat上报, 不会导致进程退出, 也不会占用并发名额
+func (rp *TaskRunner) Schedule(task func()) {
+	rp.limitChan <- struct{}{}
+	rp.wg.Add(1)
+
+	go func() {
+		defer func() {
+			rp.wg.Done()
+			<-rp.limitChan
+		}()
+		runSafe(task)
+	}()
+}
+
+// Wait 等待所有已经调度的任务结束
+func (rp *TaskRunner) Wait() {
+	rp.wg.Wait()
+}
+
+// 运行fn, panic时上报panic的内容和堆栈而不是让进程崩溃
+func runSafe(fn func()) {
+	defer func() {
+		if p := recover(); p != nil {
+			stat.Report(fmt.Sprintf("%v\n%s", p, debug.Stack()))
+		}
+	}()
+
+	fn()
+}
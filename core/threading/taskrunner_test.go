@@ -0,0 +1,112 @@
+package threading
+
+import (
+	"github.com/stretchr/testify/assert"
+	"go-zero-/core/stat"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTaskRunnerConcurrency(t *testing.T) {
+	const concurrency = 3
+	runner := NewTaskRunner(concurrency)
+	var running, maxRunning, done int32
+	for i := 0; i < 30; i++ {
+		runner.Schedule(func() {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				m := atomic.LoadInt32(&maxRunning)
+				if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&done, 1)
+		})
+	}
+	runner.Wait()
+
+	assert.Equal(t, int32(30), atomic.LoadInt32(&done))
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(concurrency))
+	assert.Greater(t, atomic.LoadInt32(&maxRunning), int32(0))
+}
+
+func TestTaskRunnerScheduleBlocks(t *testing.T) {
+	runner := NewTaskRunner(1)
+	release := make(chan struct{})
+	runner.Schedule(func() {
+		<-release
+	})
+
+	scheduled := make(chan struct{})
+	go func() {
+		runner.Schedule(func() {})
+		close(scheduled)
+	}()
+
+	select {
+	case <-scheduled:
+		t.Fatal("Schedule should block when concurrency is reached")
+	case <-time.After(time.Millisecond * 50):
+	}
+
+	close(release)
+	<-scheduled
+	runner.Wait()
+}
+
+func TestTaskRunnerWait(t *testing.T) {
+	runner := NewTaskRunner(5)
+	var done int32
+	for i := 0; i < 5; i++ {
+		runner.Schedule(func() {
+			time.Sleep(time.Millisecond * 20)
+			atomic.AddInt32(&done, 1)
+		})
+	}
+
+	runner.Wait()
+	assert.Equal(t, int32(5), atomic.LoadInt32(&done))
+}
+
+func TestNewTaskRunnerInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		NewTaskRunner(0)
+	})
+}
+
+func TestTaskRunnerRecoversPanic(t *testing.T) {
+	var lock sync.Mutex
+	var reports []string
+	stat.SetReporter(func(level stat.Level, msg string) {
+		lock.Lock()
+		reports = append(reports, msg)
+		lock.Unlock()
+	})
+	defer stat.SetReporter(nil)
+
+	// 并发为1, panic的任务如果没有归还名额, 之后的 Schedule 会一直阻塞
+	runner := NewTaskRunner(1)
+	var done int32
+	for i := 0; i < 3; i++ {
+		runner.Schedule(func() {
+			panic("boom")
+		})
+		runner.Schedule(func() {
+			atomic.AddInt32(&done, 1)
+		})
+	}
+	runner.Wait()
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&done))
+	lock.Lock()
+	defer lock.Unlock()
+	assert.Len(t, reports, 3)
+	for _, report := range reports {
+		assert.True(t, strings.HasPrefix(report, "boom\n"), report)
+	}
+}
@@ -0,0 +1,34 @@
+package timex
+
+import "time"
+
+// TruncateTo 向下对齐到unit的整数倍, 即 d - d%unit, unit为0时直接返回d
+func TruncateTo(d, unit time.Duration) time.Duration {
+	if unit == 0 {
+		return d
+	}
+	return d - d%unit
+}
+
+// RoundTo 对齐到最近的unit的整数倍, 恰好在中间时远离0取整, unit为0时直接返回d
+func RoundTo(d, unit time.Duration) time.Duration {
+	if unit == 0 {
+		return d
+	}
+	if unit < 0 {
+		unit = -unit
+	}
+
+	r := d % unit
+	if d < 0 {
+		r = -r
+		if r+r < unit {
+			return d + r
+		}
+		return d + r - unit
+	}
+	if r+r < unit {
+		return d - r
+	}
+	return d - r + unit
+}
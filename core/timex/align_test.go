@@ -0,0 +1,49 @@
+package timex
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTruncateTo(t *testing.T) {
+	tests := []struct {
+		d, unit, want time.Duration
+	}{
+		{time.Second * 30, time.Second * 10, time.Second * 30},
+		{time.Second * 35, time.Second * 10, time.Second * 30},
+		{time.Second * 39, time.Second * 10, time.Second * 30},
+		{time.Second * 5, time.Second * 10, 0},
+		{time.Second * 15, time.Second * 10, time.Second * 10},
+		{-time.Second * 15, time.Second * 10, -time.Second * 10},
+		{time.Second * 15, 0, time.Second * 15},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, TruncateTo(test.d, test.unit), "TruncateTo(%s, %s)", test.d, test.unit)
+	}
+}
+
+func TestRoundTo(t *testing.T) {
+	tests := []struct {
+		d, unit, want time.Duration
+	}{
+		{time.Second * 30, time.Second * 10, time.Second * 30},
+		{time.Second * 34, time.Second * 10, time.Second * 30},
+		{time.Second * 36, time.Second * 10, time.Second * 40},
+		{time.Second * 35, time.Second * 10, time.Second * 40},
+		{time.Second * 5, time.Second * 10, time.Second * 10},
+		{time.Second * 4, time.Second * 10, 0},
+		{-time.Second * 34, time.Second * 10, -time.Second * 30},
+		{-time.Second * 35, time.Second * 10, -time.Second * 40},
+		{time.Second * 35, -time.Second * 10, time.Second * 40},
+		{time.Second * 35, 0, time.Second * 35},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.want, RoundTo(test.d, test.unit), "RoundTo(%s, %s)", test.d, test.unit)
+		if test.unit > 0 {
+			assert.Equal(t, test.d.Round(test.unit), RoundTo(test.d, test.unit))
+		}
+	}
+}
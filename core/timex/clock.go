@@ -0,0 +1,62 @@
+package timex
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// Clock 时钟, 与 Now/Since 一样使用相对时间, 便于在测试中替换为 MockClock
+	Clock interface {
+		Now() time.Duration
+		Since(d time.Duration) time.Duration
+	}
+
+	// RealClock 真实时钟, 即 Now/Since
+	RealClock struct{}
+
+	// MockClock 手动控制的时钟, 用于编写确定性的测试, 并发安全
+	MockClock struct {
+		lock sync.RWMutex
+		now  time.Duration
+	}
+)
+
+func (RealClock) Now() time.Duration {
+	return Now()
+}
+
+func (RealClock) Since(d time.Duration) time.Duration {
+	return Since(d)
+}
+
+// NewMockClock 创建从now开始的时钟
+func NewMockClock(now time.Duration) *MockClock {
+	return &MockClock{
+		now: now,
+	}
+}
+
+func (c *MockClock) Now() time.Duration {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.now
+}
+
+func (c *MockClock) Since(d time.Duration) time.Duration {
+	return c.Now() - d
+}
+
+// Advance 时钟往前拨d
+func (c *MockClock) Advance(d time.Duration) {
+	c.lock.Lock()
+	c.now += d
+	c.lock.Unlock()
+}
+
+// Set 将时钟设置为t
+func (c *MockClock) Set(t time.Duration) {
+	c.lock.Lock()
+	c.now = t
+	c.lock.Unlock()
+}
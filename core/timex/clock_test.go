@@ -0,0 +1,44 @@
+package timex
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRealClock(t *testing.T) {
+	var clock Clock = RealClock{}
+	start := clock.Now()
+	assert.True(t, start > 0)
+	time.Sleep(time.Millisecond)
+	assert.True(t, clock.Since(start) >= time.Millisecond)
+}
+
+func TestMockClock(t *testing.T) {
+	var clock Clock = NewMockClock(time.Second)
+	assert.Equal(t, time.Second, clock.Now())
+
+	mock := clock.(*MockClock)
+	mock.Advance(time.Minute)
+	assert.Equal(t, time.Minute+time.Second, clock.Now())
+	assert.Equal(t, time.Minute, clock.Since(time.Second))
+
+	mock.Set(time.Hour)
+	assert.Equal(t, time.Hour, clock.Now())
+}
+
+func TestMockClockConcurrentAdvance(t *testing.T) {
+	clock := NewMockClock(0)
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			clock.Advance(time.Millisecond)
+			_ = clock.Now()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, time.Millisecond*100, clock.Now())
+}
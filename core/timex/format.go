@@ -0,0 +1,59 @@
+package timex
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 从大到小排列
+var durationUnits = []struct {
+	unit time.Duration
+	name string
+}{
+	{time.Hour, "hour"},
+	{time.Minute, "minute"},
+	{time.Second, "second"},
+	{time.Millisecond, "millisecond"},
+	{time.Microsecond, "microsecond"},
+	{time.Nanosecond, "nanosecond"},
+}
+
+// FormatDuration 返回便于阅读的时长, 只保留最高的两个非零单位, 例如 1 hour 23 minutes, 45 seconds
+// 零值返回 0 seconds, 负数带上 - 前缀
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0 seconds"
+	}
+
+	var sign string
+	// 转为uint64, 避免math.MinInt64取反溢出
+	abs := uint64(d)
+	if d < 0 {
+		sign = "-"
+		abs = uint64(-d)
+	}
+
+	parts := make([]string, 0, 2)
+	for _, u := range durationUnits {
+		n := abs / uint64(u.unit)
+		if n == 0 {
+			continue
+		}
+
+		abs -= n * uint64(u.unit)
+		parts = append(parts, formatUnit(n, u.name))
+		if len(parts) == 2 {
+			break
+		}
+	}
+
+	return sign + strings.Join(parts, " ")
+}
+
+func formatUnit(n uint64, name string) string {
+	if n == 1 {
+		return "1 " + name
+	}
+	return fmt.Sprintf("%d %ss", n, name)
+}
@@ -0,0 +1,38 @@
+package timex
+
+import (
+	"github.com/stretchr/testify/assert"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{0, "0 seconds"},
+		{time.Hour, "1 hour"},
+		{time.Minute, "1 minute"},
+		{time.Second, "1 second"},
+		{time.Hour*2 + time.Minute, "2 hours 1 minute"},
+		{time.Hour + time.Minute*23 + time.Second*45 + time.Millisecond*678, "1 hour 23 minutes"},
+		{time.Hour + time.Second*5, "1 hour 5 seconds"},
+		{time.Second * 45, "45 seconds"},
+		{time.Millisecond * 300, "300 milliseconds"},
+		{time.Minute - time.Nanosecond, "59 seconds 999 milliseconds"},
+		{time.Microsecond * 500, "500 microseconds"},
+		{time.Microsecond + time.Nanosecond, "1 microsecond 1 nanosecond"},
+		{time.Nanosecond * 2, "2 nanoseconds"},
+		{-time.Minute * 3, "-3 minutes"},
+		{-time.Millisecond * 1500, "-1 second 500 milliseconds"},
+		{time.Duration(math.MinInt64), "-2562047 hours 47 minutes"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			assert.Equal(t, test.want, FormatDuration(test.d))
+		})
+	}
+}
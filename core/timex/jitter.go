@@ -0,0 +1,23 @@
+package timex
+
+import (
+	"go-zero-/core/mathx"
+	"time"
+)
+
+// 抖动比例为1, 每次调用按ratio缩放, 不需要为不同的ratio分别创建
+var jitterSource = mathx.NewUnstable(1)
+
+// Jitter 返回在 [d*(1-ratio), d*(1+ratio)] 范围内随机抖动之后的时长, 用于错开同一间隔触发的后台任务
+// ratio 的取值范围为 [0, 1), 否则panic
+func Jitter(d time.Duration, ratio float64) time.Duration {
+	if ratio < 0 || ratio >= 1 {
+		panic("ratio must be in [0, 1)")
+	}
+	if ratio == 0 || d == 0 {
+		return d
+	}
+
+	offset := jitterSource.AroundDuration(d) - d
+	return d + time.Duration(float64(offset)*ratio)
+}
@@ -0,0 +1,46 @@
+package timex
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestJitter(t *testing.T) {
+	for _, d := range []time.Duration{time.Millisecond, time.Second, time.Minute, -time.Second} {
+		for _, ratio := range []float64{0.1, 0.5, 0.99} {
+			lower := time.Duration(float64(d) * (1 - ratio))
+			upper := time.Duration(float64(d) * (1 + ratio))
+			if d < 0 {
+				lower, upper = upper, lower
+			}
+
+			var below, above bool
+			for i := 0; i < 2000; i++ {
+				v := Jitter(d, ratio)
+				assert.GreaterOrEqual(t, v, lower, "Jitter(%s, %v)", d, ratio)
+				assert.LessOrEqual(t, v, upper, "Jitter(%s, %v)", d, ratio)
+				if v < d {
+					below = true
+				} else if v > d {
+					above = true
+				}
+			}
+			// 两个方向都有抖动
+			assert.True(t, below && above, "Jitter(%s, %v)", d, ratio)
+		}
+	}
+}
+
+func TestJitterNoop(t *testing.T) {
+	assert.Equal(t, time.Second, Jitter(time.Second, 0))
+	assert.Equal(t, time.Duration(0), Jitter(0, 0.5))
+}
+
+func TestJitterInvalidRatio(t *testing.T) {
+	for _, ratio := range []float64{-0.1, 1, 1.5} {
+		assert.Panics(t, func() {
+			Jitter(time.Second, ratio)
+		}, ratio)
+	}
+}
@@ -0,0 +1,122 @@
+package timex
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ParseHumanDuration 支持的大于小时的单位
+const (
+	Day  = time.Hour * 24
+	Week = Day * 7
+	Year = Day * 365
+)
+
+var (
+	ErrInvalidDuration = errors.New("timex: invalid duration")
+
+	humanUnits = map[string]time.Duration{
+		"ns": time.Nanosecond,
+		"us": time.Microsecond,
+		"µs": time.Microsecond,
+		"ms": time.Millisecond,
+		"s":  time.Second,
+		"m":  time.Minute,
+		"h":  time.Hour,
+		"d":  Day,
+		"w":  Week,
+		"y":  Year,
+	}
+)
+
+// ParseHumanDuration 与 time.ParseDuration 类似, 额外支持 d(24h), w(7d), y(365d)
+// 例如 1d12h30m, 2w3d, -1.5d
+func ParseHumanDuration(s string) (time.Duration, error) {
+	orig := s
+	var neg bool
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	if len(s) == 0 {
+		return 0, invalidDuration(orig, "empty")
+	}
+	if s == "0" {
+		return 0, nil
+	}
+
+	var total uint64
+	for len(s) > 0 {
+		// 整数部分
+		var n, frac, scale uint64
+		var i int
+		for ; i < len(s) && '0' <= s[i] && s[i] <= '9'; i++ {
+			if n > (math.MaxInt64-9)/10 {
+				return 0, invalidDuration(orig, "overflow")
+			}
+			n = n*10 + uint64(s[i]-'0')
+		}
+		digits := i
+		// 小数部分, 超出精度的位直接丢弃
+		scale = 1
+		if i < len(s) && s[i] == '.' {
+			i++
+			for ; i < len(s) && '0' <= s[i] && s[i] <= '9'; i++ {
+				if scale < 1e18 {
+					frac = frac*10 + uint64(s[i]-'0')
+					scale *= 10
+				}
+				digits++
+			}
+		}
+		if digits == 0 {
+			return 0, invalidDuration(orig, "missing number")
+		}
+		s = s[i:]
+
+		// 单位
+		i = 0
+		for ; i < len(s) && s[i] != '.' && (s[i] < '0' || s[i] > '9'); i++ {
+		}
+		if i == 0 {
+			return 0, invalidDuration(orig, "missing unit")
+		}
+		unit, ok := humanUnits[s[:i]]
+		if !ok {
+			return 0, invalidDuration(orig, fmt.Sprintf("unknown unit %q", s[:i]))
+		}
+		s = s[i:]
+
+		if n > math.MaxInt64/uint64(unit) {
+			return 0, invalidDuration(orig, "overflow")
+		}
+		v := n * uint64(unit)
+		if frac > 0 {
+			v += uint64(float64(frac) * (float64(unit) / float64(scale)))
+		}
+		total += v
+		if total > math.MaxInt64 {
+			return 0, invalidDuration(orig, "overflow")
+		}
+	}
+
+	if neg {
+		return -time.Duration(total), nil
+	}
+	return time.Duration(total), nil
+}
+
+// MustParseHumanDuration 与 ParseHumanDuration 相同, 解析失败时panic, 用于初始化全局变量
+func MustParseHumanDuration(s string) time.Duration {
+	d, err := ParseHumanDuration(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func invalidDuration(s, reason string) error {
+	return fmt.Errorf("%w %q: %s", ErrInvalidDuration, s, reason)
+}
@@ -0,0 +1,74 @@
+package timex
+
+import (
+	"errors"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestParseHumanDuration(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Duration
+	}{
+		{"0", 0},
+		{"5ns", time.Nanosecond * 5},
+		{"5us", time.Microsecond * 5},
+		{"5µs", time.Microsecond * 5},
+		{"5ms", time.Millisecond * 5},
+		{"5s", time.Second * 5},
+		{"5m", time.Minute * 5},
+		{"5h", time.Hour * 5},
+		{"1d", time.Hour * 24},
+		{"1w", time.Hour * 24 * 7},
+		{"1y", time.Hour * 24 * 365},
+		{"1d12h30m", time.Hour*36 + time.Minute*30},
+		{"2w3d", time.Hour * 24 * 17},
+		{"1y2w", Year + Week*2},
+		{"1.5d", time.Hour * 36},
+		{".5h", time.Minute * 30},
+		{"1h30m15s500ms", time.Hour + time.Minute*30 + time.Second*15 + time.Millisecond*500},
+		{"+3d", Day * 3},
+		{"-3d", -Day * 3},
+		{"-1d12h", -time.Hour * 36},
+		{"0d", 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.s, func(t *testing.T) {
+			d, err := ParseHumanDuration(test.s)
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, d)
+		})
+	}
+}
+
+func TestParseHumanDurationMatchesStd(t *testing.T) {
+	for _, s := range []string{"1h2m3s", "1.5h", "300ms", "-2m", "1h0.5m"} {
+		want, err := time.ParseDuration(s)
+		assert.Nil(t, err)
+		got, err := ParseHumanDuration(s)
+		assert.Nil(t, err)
+		assert.Equal(t, want, got, s)
+	}
+}
+
+func TestParseHumanDurationInvalid(t *testing.T) {
+	for _, s := range []string{"", "-", "d", "1", "12", "1x", "1dd", "1.d.5", "1d-2h", "abc", "1 d", "300000y"} {
+		t.Run(s, func(t *testing.T) {
+			_, err := ParseHumanDuration(s)
+			assert.True(t, errors.Is(err, ErrInvalidDuration), "%q: %v", s, err)
+		})
+	}
+
+	_, err := ParseHumanDuration("3q")
+	assert.Contains(t, err.Error(), `unknown unit "q"`)
+}
+
+func TestMustParseHumanDuration(t *testing.T) {
+	assert.Equal(t, Week, MustParseHumanDuration("1w"))
+	assert.Panics(t, func() {
+		MustParseHumanDuration("1x")
+	})
+}
@@ -11,3 +11,13 @@ func Now() time.Duration {
 func Since(d time.Duration) time.Duration {
 	return time.Since(initTime) - d
 }
+
+// Until 返回距离t还有多久, t已经过去时返回负数
+func Until(t time.Duration) time.Duration {
+	return t - Now()
+}
+
+// HasPassed 返回t是否已经过去
+func HasPassed(t time.Duration) bool {
+	return Now() >= t
+}
@@ -19,3 +19,24 @@ func TestRelativeTime(t *testing.T) {
 		time.Sleep(time.Millisecond)
 	}
 }
+
+func TestUntil(t *testing.T) {
+	now := Now()
+	for _, d := range []time.Duration{-time.Hour, -time.Second, 0, time.Second, time.Hour} {
+		target := now + d
+		// 两次调用之间时间会流逝, 所以 Until(t) <= -Since(t) 且相差很小
+		since := Since(target)
+		until := Until(target)
+		assert.True(t, until <= -since)
+		assert.True(t, -since-until < time.Second)
+	}
+
+	assert.True(t, Until(Now()+time.Hour) > time.Minute*59)
+	assert.True(t, Until(Now()-time.Hour) < -time.Minute*59)
+}
+
+func TestHasPassed(t *testing.T) {
+	assert.True(t, HasPassed(Now()))
+	assert.True(t, HasPassed(Now()-time.Second))
+	assert.False(t, HasPassed(Now()+time.Hour))
+}
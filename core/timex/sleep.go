@@ -0,0 +1,22 @@
+package timex
+
+import (
+	"context"
+	"time"
+)
+
+// 测试时用于拿到创建的timer
+var newTimer = time.NewTimer
+
+// SleepWithContext 与 time.Sleep 相同, 但ctx结束时立即返回 ctx.Err(), 正常睡眠结束时返回nil
+func SleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := newTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
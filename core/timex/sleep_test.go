@@ -0,0 +1,50 @@
+package timex
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func captureTimer(t *testing.T) **time.Timer {
+	var timer *time.Timer
+	newTimer = func(d time.Duration) *time.Timer {
+		timer = time.NewTimer(d)
+		return timer
+	}
+	t.Cleanup(func() {
+		newTimer = time.NewTimer
+	})
+	return &timer
+}
+
+func TestSleepWithContext(t *testing.T) {
+	timer := captureTimer(t)
+	start := time.Now()
+	assert.Nil(t, SleepWithContext(context.Background(), time.Millisecond*10))
+	assert.True(t, time.Since(start) >= time.Millisecond*10)
+	// timer已经触发或者已经停止
+	assert.False(t, (*timer).Stop())
+}
+
+func TestSleepWithContextCancel(t *testing.T) {
+	timer := captureTimer(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(time.Millisecond * 10)
+		cancel()
+	}()
+
+	start := time.Now()
+	assert.Equal(t, context.Canceled, SleepWithContext(ctx, time.Hour))
+	assert.True(t, time.Since(start) < time.Second)
+	// 取消时timer已经被停止, 不会泄漏
+	assert.False(t, (*timer).Stop())
+}
+
+func TestSleepWithContextDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, SleepWithContext(ctx, time.Hour))
+}
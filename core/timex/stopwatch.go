@@ -0,0 +1,59 @@
+package timex
+
+import (
+	"sync"
+	"time"
+)
+
+// Stopwatch 计时器, 代替 start := Now(); ...; Since(start) 的写法, 并发安全
+type Stopwatch struct {
+	lock    sync.Mutex
+	clock   Clock
+	start   time.Duration
+	lastLap time.Duration
+}
+
+// NewStopwatch 创建并立即开始计时
+func NewStopwatch() *Stopwatch {
+	return newStopwatch(RealClock{})
+}
+
+func newStopwatch(clock Clock) *Stopwatch {
+	now := clock.Now()
+	return &Stopwatch{
+		clock:   clock,
+		start:   now,
+		lastLap: now,
+	}
+}
+
+// Elapsed 返回开始计时到现在的时长
+func (sw *Stopwatch) Elapsed() time.Duration {
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+	return sw.clock.Since(sw.start)
+}
+
+// Lap 返回上一次调用 Lap 到现在的时长, 第一次调用时返回开始计时到现在的时长
+func (sw *Stopwatch) Lap() time.Duration {
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+
+	now := sw.clock.Now()
+	lap := now - sw.lastLap
+	sw.lastLap = now
+	return lap
+}
+
+// Reset 重新开始计时
+func (sw *Stopwatch) Reset() {
+	sw.lock.Lock()
+	now := sw.clock.Now()
+	sw.start = now
+	sw.lastLap = now
+	sw.lock.Unlock()
+}
+
+func (sw *Stopwatch) String() string {
+	return FormatDuration(sw.Elapsed())
+}
@@ -0,0 +1,40 @@
+package timex
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestStopwatchElapsed(t *testing.T) {
+	sw := NewStopwatch()
+	first := sw.Elapsed()
+	time.Sleep(time.Millisecond)
+	second := sw.Elapsed()
+	assert.True(t, second > first)
+	assert.True(t, second >= time.Millisecond)
+}
+
+func TestStopwatchLap(t *testing.T) {
+	clock := NewMockClock(time.Hour)
+	sw := newStopwatch(clock)
+
+	clock.Advance(time.Second)
+	assert.Equal(t, time.Second, sw.Lap())
+	clock.Advance(time.Second * 3)
+	assert.Equal(t, time.Second*3, sw.Lap())
+	assert.Equal(t, time.Duration(0), sw.Lap())
+	assert.Equal(t, time.Second*4, sw.Elapsed())
+}
+
+func TestStopwatchReset(t *testing.T) {
+	clock := NewMockClock(0)
+	sw := newStopwatch(clock)
+	clock.Advance(time.Minute)
+	sw.Reset()
+	assert.Equal(t, time.Duration(0), sw.Elapsed())
+
+	clock.Advance(time.Second)
+	assert.Equal(t, time.Second, sw.Lap())
+	assert.Equal(t, "1 second", sw.String())
+}
@@ -0,0 +1,39 @@
+package timex
+
+import (
+	"context"
+	"time"
+)
+
+// TickWithContext 与 time.Tick 相同, 但ctx结束时停止ticker并关闭返回的channel, 不会泄漏协程
+// 与 time.Ticker 一样, 接收方处理不过来时会丢弃多余的tick
+func TickWithContext(ctx context.Context, d time.Duration) <-chan time.Time {
+	ticker := time.NewTicker(d)
+	ch := make(chan time.Time)
+
+	go func() {
+		defer close(ch)
+		defer func() {
+			ticker.Stop()
+			select {
+			case <-ticker.C:
+			default:
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case t := <-ticker.C:
+				select {
+				case ch <- t:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return ch
+}
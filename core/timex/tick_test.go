@@ -0,0 +1,58 @@
+package timex
+
+import (
+	"context"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/goleak"
+	"testing"
+	"time"
+)
+
+func TestTickWithContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch := TickWithContext(ctx, time.Millisecond)
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ch:
+		case <-time.After(time.Second):
+			t.Fatal("tick timeout")
+		}
+	}
+}
+
+func TestTickWithContextCancel(t *testing.T) {
+	// 只检查本用例创建的协程, 忽略其它用例遗留的协程
+	ignore := goleak.IgnoreCurrent()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := TickWithContext(ctx, time.Millisecond)
+	<-ch
+	// 不再接收的情况下取消, 协程也要退出
+	time.Sleep(time.Millisecond * 5)
+	cancel()
+
+	closed := make(chan struct{})
+	go func() {
+		for range ch {
+		}
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("channel not closed after cancel")
+	}
+
+	// 没有泄漏协程
+	goleak.VerifyNone(t, ignore)
+}
+
+func TestTickWithContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, ok := <-TickWithContext(ctx, time.Hour)
+	assert.False(t, ok)
+}
@@ -0,0 +1,97 @@
+package timex
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// Ticker 定时器, 便于在测试中替换为 FakeTicker 手动触发
+	Ticker interface {
+		Chan() <-chan time.Time
+		Stop()
+		// Reset 停止定时器, 并改为每隔d触发一次
+		Reset(d time.Duration)
+	}
+
+	realTicker struct {
+		*time.Ticker
+	}
+
+	// FakeTicker 手动触发的定时器, 用于编写确定性的测试, 并发安全
+	FakeTicker struct {
+		c    chan time.Time
+		done chan struct{}
+		once sync.Once
+		// 最近一次 Reset 设置的间隔
+		interval int64
+	}
+)
+
+var _ Ticker = (*FakeTicker)(nil)
+
+// NewTicker 创建每隔d触发一次的定时器, d必须大于0
+// 与 time.Ticker 一样由运行时的单调时钟驱动, 修改系统时间不会影响触发间隔, 与 Now/Since 的相对时间一致
+// 接收方处理不过来时会丢弃多余的tick
+func NewTicker(d time.Duration) Ticker {
+	return realTicker{
+		Ticker: time.NewTicker(d),
+	}
+}
+
+func (t realTicker) Chan() <-chan time.Time {
+	return t.C
+}
+
+// NewFakeTicker 创建手动触发的定时器
+func NewFakeTicker() *FakeTicker {
+	return &FakeTicker{
+		c:    make(chan time.Time),
+		done: make(chan struct{}),
+	}
+}
+
+func (t *FakeTicker) Chan() <-chan time.Time {
+	return t.c
+}
+
+// Stop 停止定时器, 可以重复调用
+func (t *FakeTicker) Stop() {
+	t.once.Do(func() {
+		close(t.done)
+	})
+}
+
+// Reset 只记录间隔, FakeTicker 总是通过 Tick 手动触发
+func (t *FakeTicker) Reset(d time.Duration) {
+	if d <= 0 {
+		panic("non-positive interval for FakeTicker.Reset")
+	}
+	atomic.StoreInt64(&t.interval, int64(d))
+}
+
+// Interval 返回最近一次 Reset 设置的间隔, 没有调用过时返回0
+func (t *FakeTicker) Interval() time.Duration {
+	return time.Duration(atomic.LoadInt64(&t.interval))
+}
+
+// Tick 触发一次, 阻塞到接收方收到为止, 已经停止时返回false
+func (t *FakeTicker) Tick() bool {
+	select {
+	case t.c <- time.Now():
+		return true
+	case <-t.done:
+		return false
+	}
+}
+
+// Stopped 是否已经停止
+func (t *FakeTicker) Stopped() bool {
+	select {
+	case <-t.done:
+		return true
+	default:
+		return false
+	}
+}
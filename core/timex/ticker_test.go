@@ -0,0 +1,88 @@
+package timex
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestNewTicker(t *testing.T) {
+	ticker := NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case <-ticker.Chan():
+		case <-time.After(time.Second):
+			t.Fatal("tick timeout")
+		}
+	}
+}
+
+func TestFakeTicker(t *testing.T) {
+	ticker := NewFakeTicker()
+	received := make(chan struct{})
+	go func() {
+		for i := 0; i < 2; i++ {
+			<-ticker.Chan()
+		}
+		close(received)
+	}()
+
+	assert.True(t, ticker.Tick())
+	assert.True(t, ticker.Tick())
+	<-received
+	assert.False(t, ticker.Stopped())
+
+	// 停止之后不再阻塞
+	ticker.Stop()
+	ticker.Stop()
+	assert.True(t, ticker.Stopped())
+	assert.False(t, ticker.Tick())
+}
+
+// 连续接收n个tick, 返回相邻两个tick的平均间隔
+func averageTickInterval(t *testing.T, ticker Ticker, n int) time.Duration {
+	start := Now()
+	for i := 0; i < n; i++ {
+		select {
+		case <-ticker.Chan():
+		case <-time.After(time.Second):
+			t.Fatal("tick timeout")
+		}
+	}
+	return Since(start) / time.Duration(n)
+}
+
+func TestTickerCadence(t *testing.T) {
+	ticker := NewTicker(time.Millisecond * 10)
+	defer ticker.Stop()
+
+	interval := averageTickInterval(t, ticker, 10)
+	assert.InDelta(t, float64(time.Millisecond*10), float64(interval), float64(time.Millisecond*5))
+
+	ticker.Reset(time.Millisecond * 30)
+	interval = averageTickInterval(t, ticker, 5)
+	assert.InDelta(t, float64(time.Millisecond*30), float64(interval), float64(time.Millisecond*10))
+}
+
+func TestTickerInvalid(t *testing.T) {
+	assert.Panics(t, func() {
+		NewTicker(0)
+	})
+	ticker := NewTicker(time.Second)
+	defer ticker.Stop()
+	assert.Panics(t, func() {
+		ticker.Reset(-time.Second)
+	})
+}
+
+func TestFakeTickerReset(t *testing.T) {
+	ticker := NewFakeTicker()
+	assert.Equal(t, time.Duration(0), ticker.Interval())
+	ticker.Reset(time.Second)
+	assert.Equal(t, time.Second, ticker.Interval())
+	assert.Panics(t, func() {
+		ticker.Reset(0)
+	})
+}